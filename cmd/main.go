@@ -1,41 +1,66 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
 	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
-	"time"
+	"syscall"
 
 	casbin "github.com/casbin/casbin/v2"
 	"github.com/segmentio/kafka-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
 
-	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/audit"
+	configpkg "gateway-service/internal/items/config"
 	"gateway-service/internal/items/http/app"
 	"gateway-service/internal/items/http/handler"
+	"gateway-service/internal/items/lifecycle"
 	"gateway-service/internal/items/msgbroker"
+	"gateway-service/internal/items/rbac"
 	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/pkg/certs"
+	loggerpkg "gateway-service/internal/pkg/logger"
 	redisCl "gateway-service/internal/pkg/redis"
+	"gateway-service/internal/pkg/tracing"
 )
 
 func main() {
-	config, err := config.New()
+	config, err := configpkg.New()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	logFile, err := os.OpenFile("application.log", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	logger, logLevels, logCloser, err := loggerpkg.New(config.Logging)
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer logFile.Close()
+	defer logCloser.Close()
 
-	logger := slog.New(slog.NewJSONHandler(logFile, nil))
+	logger.Info("Effective configuration", "config", config.Effective())
+
+	store := configpkg.NewStore(config, os.Args[1:], logger.With(loggerpkg.ComponentKey, "config"))
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	lc := lifecycle.New(logger.With(loggerpkg.ComponentKey, "lifecycle"), config.Lifecycle.DrainDelay)
+
+	shutdownTracing, err := tracing.Setup(ctx, config)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	modelPath := filepath.Join("internal", "items", "casbin", "model.conf")
 	policyPath := filepath.Join("internal", "items", "casbin", "policy.csv")
 
-	enforcer, err := casbin.NewEnforcer(modelPath, policyPath)
+	enforcer, err := casbin.NewSyncedEnforcer(modelPath, policyPath)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -45,20 +70,144 @@ func main() {
 		logger.Error("Error connecting to Redis", slog.String("err", err.Error()))
 	}
 
-	time.Sleep(10 * time.Second)
+	startupCtx, cancelStartup := context.WithTimeout(ctx, config.Lifecycle.StartupTimeout)
+	defer cancelStartup()
+
+	err = lifecycle.WaitFor(startupCtx, logger, "redis", func(ctx context.Context) error {
+		return redis.Ping(ctx).Err()
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = lifecycle.WaitFor(startupCtx, logger, "kafka", func(ctx context.Context) error {
+		return msgbroker.Ping(ctx, config)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
 
+	kafkaLogger := logger.With(loggerpkg.ComponentKey, "kafka")
 	writer := kafka.NewWriter(kafka.WriterConfig{
-		Brokers: []string{config.Kafka.Brokers},
-		Logger:  log.New(os.Stdout, "kafka writer: ", 0),
+		Brokers: config.Kafka.BrokerList(),
+		Dialer:  msgbroker.Dialer(config),
+		Logger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			kafkaLogger.Debug(fmt.Sprintf(msg, args...))
+		}),
+		ErrorLogger: kafka.LoggerFunc(func(msg string, args ...interface{}) {
+			kafkaLogger.Error(fmt.Sprintf(msg, args...))
+		}),
 	})
-	defer writer.Close()
 
 	err = msgbroker.CreateTopics(config, logger)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	handler := handler.New(redisservice.New(redis, logger), logger, config, writer)
+	broker := msgbroker.NewMsgBroker(writer, logger.With(loggerpkg.ComponentKey, "msgbroker"))
+
+	auditor, err := audit.New(config, broker, logger.With(loggerpkg.ComponentKey, "audit"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	redisService := redisservice.New(redis, logger.With(loggerpkg.ComponentKey, "redisservice"), config.Cache.AccountTTL)
+
+	roles := rbac.New(enforcer, redisService)
+	if err := roles.Load(startupCtx); err != nil {
+		log.Fatal(err)
+	}
+	go roles.Watch(ctx, config.Roles.SyncInterval, logger.With(loggerpkg.ComponentKey, "rbac"))
+
+	handler := handler.New(redisService, logger.With(loggerpkg.ComponentKey, "handler"), store, broker, lc, auditor, roles)
+
+	// Exports that a stopped gateway was building will not finish.
+	if err := handler.BudgetingRepo.ExportHandler.FailStaleJobs(startupCtx); err != nil {
+		logger.Error("Failed to mark abandoned export jobs failed", "error", err.Error())
+	}
+
+	err = lifecycle.WaitFor(startupCtx, logger, "auth", grpcReady(handler.AuthRepo.Conn()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = lifecycle.WaitFor(startupCtx, logger, "budgeting", grpcReady(handler.BudgetingRepo.Conn()))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	server, err := app.New(handler, logger.With(loggerpkg.ComponentKey, "http"), store, enforcer)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server.TLSConfig, err = certs.ServerTLS(config.Server.TLS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store.Subscribe(func(cfg *configpkg.Config) {
+		logLevels.Set(cfg.Logging.Level, cfg.Logging.Levels)
+		redisService.SetAccountTTL(cfg.Cache.AccountTTL)
+	})
+	go store.Watch(ctx)
+	go config.SecretManager().Watch(ctx, config.Secrets.RefreshInterval, logger.With(loggerpkg.ComponentKey, "secrets"))
+
+	lc.OnShutdown("http server", server.Shutdown)
+	lc.OnShutdown("background jobs", handler.Jobs.Shutdown)
+	lc.OnShutdown("audit", auditor.Close)
+	lc.OnShutdown("kafka", broker.Close)
+	lc.OnShutdown("redis", func(context.Context) error { return redis.Close() })
+	lc.OnShutdown("grpc", func(context.Context) error { return handler.Close() })
+	lc.OnShutdown("tracing", shutdownTracing)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("HTTP server listening", "addr", server.Addr, "tls", server.TLSConfig != nil)
+		var err error
+		if server.TLSConfig != nil {
+			// The certificate comes from TLSConfig.GetCertificate.
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
+
+	lc.SetState(lifecycle.Ready)
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutdown signal received")
+	case err := <-serverErr:
+		if err != nil {
+			logger.Error("HTTP server failed", "error", err.Error())
+		}
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Lifecycle.ShutdownTimeout)
+	defer cancel()
 
-	log.Fatal(app.Run(handler, logger, config, enforcer))
+	if err := lc.Shutdown(shutdownCtx); err != nil {
+		log.Fatal(err)
+	}
+	logger.Info("Gateway stopped")
+}
+
+// grpcReady returns a probe that succeeds once conn reaches the Ready state.
+func grpcReady(conn *grpc.ClientConn) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		conn.Connect()
+		for {
+			state := conn.GetState()
+			if state == connectivity.Ready {
+				return nil
+			}
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("connection state %s", state)
+			}
+		}
+	}
 }
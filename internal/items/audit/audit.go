@@ -0,0 +1,397 @@
+// Package audit records privileged and financial actions. Every record is
+// appended to a hash-chained local file, so edits or deletions break the
+// chain, and published to Kafka for downstream retention.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/msgbroker"
+	"gateway-service/internal/pkg/logger"
+	"gateway-service/internal/pkg/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Outcome string
+
+// SystemActor is the actor and role of records written by LogSystem.
+const SystemActor = "system"
+
+const (
+	OutcomeSuccess Outcome = "success"
+	OutcomeFailure Outcome = "failure"
+)
+
+// Record is one audit entry as stored and published. Hash covers every
+// other field, including PrevHash, which is the Hash of the record before.
+// ActedBy is the admin impersonating ActorID, if any.
+type Record struct {
+	ID         string          `json:"id"`
+	Time       time.Time       `json:"time"`
+	ActorID    string          `json:"actor_id"`
+	Role       string          `json:"role"`
+	ActedBy    string          `json:"acted_by,omitempty"`
+	Action     string          `json:"action"`
+	Resource   string          `json:"resource"`
+	ResourceID string          `json:"resource_id,omitempty"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	Outcome    Outcome         `json:"outcome"`
+	Error      string          `json:"error,omitempty"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// Entry describes an action from the handler's point of view. Before and
+// After are summarised as redacted JSON.
+type Entry struct {
+	Action     string
+	Resource   string
+	ResourceID string
+	Before     any
+	After      any
+}
+
+const (
+	// publishQueueSize is how many records may wait to be published
+	// before Append publishes them itself.
+	publishQueueSize = 1000
+	// maxRecordSize is the longest line read back from the file.
+	maxRecordSize = 4 * 1024 * 1024
+	// reverseChunkSize is how much of the file eachReverse reads at once.
+	reverseChunkSize = 64 * 1024
+)
+
+type Auditor struct {
+	config *config.Config
+	broker *msgbroker.MsgBroker
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	lastHash string
+	closed   bool
+
+	publish chan publication
+	done    chan struct{}
+}
+
+// publication is a record waiting to be published, with the context of
+// the request that wrote it.
+type publication struct {
+	ctx  context.Context
+	line []byte
+}
+
+// New opens the audit file for appending, resumes its hash chain and
+// starts publishing records in the background until Close.
+func New(config *config.Config, broker *msgbroker.MsgBroker, logger *slog.Logger) (*Auditor, error) {
+	a := &Auditor{
+		config:  config,
+		broker:  broker,
+		logger:  logger,
+		path:    config.Audit.File,
+		publish: make(chan publication, publishQueueSize),
+		done:    make(chan struct{}),
+	}
+
+	var err error
+	a.file, err = os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	info, err := a.file.Stat()
+	if err != nil {
+		a.file.Close()
+		return nil, fmt.Errorf("open audit file: %w", err)
+	}
+	a.size = info.Size()
+
+	err = a.eachReverse(a.size, func(last Record) bool {
+		a.lastHash = last.Hash
+		return false
+	})
+	if err != nil {
+		a.file.Close()
+		return nil, err
+	}
+
+	go a.publishLoop()
+	return a, nil
+}
+
+// Log records the outcome of an action performed in request c. err is the
+// error the action failed with, or nil.
+func (a *Auditor) Log(c *gin.Context, e Entry, err error) {
+	ctx := c.Request.Context()
+
+	record := Record{
+		ID:         reqctx.NewRequestID(),
+		Time:       time.Now().UTC(),
+		ActorID:    middleware.GetUser_id(c, a.config),
+		Role:       middleware.GetRole(c, a.config),
+		ActedBy:    middleware.GetImpersonator(c, a.config),
+		Action:     e.Action,
+		Resource:   e.Resource,
+		ResourceID: e.ResourceID,
+		Before:     summary(e.Before),
+		After:      summary(e.After),
+		IP:         c.ClientIP(),
+		UserAgent:  c.Request.UserAgent(),
+		RequestID:  reqctx.RequestID(ctx),
+	}
+	a.write(ctx, record, err)
+}
+
+// LogSystem records the outcome of an action the gateway performed on its
+// own, such as a scheduled job, with SystemActor as the actor.
+func (a *Auditor) LogSystem(ctx context.Context, e Entry, err error) {
+	a.write(ctx, Record{
+		ID:         reqctx.NewRequestID(),
+		Time:       time.Now().UTC(),
+		ActorID:    SystemActor,
+		Role:       SystemActor,
+		Action:     e.Action,
+		Resource:   e.Resource,
+		ResourceID: e.ResourceID,
+		Before:     summary(e.Before),
+		After:      summary(e.After),
+		RequestID:  reqctx.RequestID(ctx),
+	}, err)
+}
+
+func (a *Auditor) write(ctx context.Context, record Record, err error) {
+	record.Outcome = OutcomeSuccess
+	if err != nil {
+		record.Outcome = OutcomeFailure
+		record.Error = err.Error()
+	}
+
+	if err := a.Append(ctx, &record); err != nil {
+		a.logger.ErrorContext(ctx, "Failed to write audit record", "action", record.Action, "error", err.Error())
+	}
+}
+
+// Append chains record onto the file and queues it to be published. If
+// the queue is full it publishes the record itself rather than drop it.
+func (a *Auditor) Append(ctx context.Context, record *Record) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return errors.New("audit: auditor is closed")
+	}
+	record.PrevHash = a.lastHash
+	record.Hash = ""
+	hash, err := hashRecord(record)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	record.Hash = hash
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	n, err := a.file.Write(append(line, '\n'))
+	a.size += int64(n)
+	if err != nil {
+		a.mu.Unlock()
+		return err
+	}
+	a.lastHash = hash
+
+	// The request may end before the record is published, so keep its
+	// values, such as the trace, but not its cancellation.
+	queued := false
+	select {
+	case a.publish <- publication{ctx: context.WithoutCancel(ctx), line: line}:
+		queued = true
+	default:
+	}
+	a.mu.Unlock()
+
+	if !queued {
+		return a.broker.AuditRecorded(ctx, line)
+	}
+	return nil
+}
+
+func (a *Auditor) publishLoop() {
+	defer close(a.done)
+	for p := range a.publish {
+		if err := a.broker.AuditRecorded(p.ctx, p.line); err != nil {
+			a.logger.ErrorContext(p.ctx, "Failed to publish audit record", "error", err.Error())
+		}
+	}
+}
+
+func hashRecord(record *Record) (string, error) {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func summary(v any) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(logger.RedactValue(v))
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+// written returns how much of the file holds records. Records are
+// written whole under mu, so the file can be read up to there without it.
+func (a *Auditor) written() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.size
+}
+
+// each calls fn with the records in the first size bytes of the file,
+// oldest first, without holding more than one in memory.
+func (a *Auditor) each(size int64, fn func(line int, record Record) error) error {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(io.LimitReader(f, size))
+	scanner.Buffer(make([]byte, 64*1024), maxRecordSize)
+	for line := 1; scanner.Scan(); line++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return fmt.Errorf("audit file line %d: %w", line, err)
+		}
+		if err := fn(line, record); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// eachReverse calls fn with the records in the first size bytes of the
+// file, newest first, until fn returns false. The file is read backwards
+// in chunks, so only the records fn is given are read.
+func (a *Auditor) eachReverse(size int64, fn func(record Record) bool) error {
+	f, err := os.Open(a.path)
+	if err != nil {
+		return fmt.Errorf("open audit file: %w", err)
+	}
+	defer f.Close()
+
+	// pending holds the bytes from offset up to the last line not yet
+	// given to fn.
+	var pending []byte
+	emit := func(line []byte, at int64) (bool, error) {
+		if len(line) == 0 {
+			return true, nil
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return false, fmt.Errorf("audit file offset %d: %w", at, err)
+		}
+		return fn(record), nil
+	}
+
+	offset := size
+	for offset > 0 {
+		n := min(reverseChunkSize, offset)
+		offset -= n
+		chunk := make([]byte, n, n+int64(len(pending)))
+		if _, err := f.ReadAt(chunk, offset); err != nil {
+			return fmt.Errorf("read audit file: %w", err)
+		}
+		pending = append(chunk, pending...)
+		if len(pending) > maxRecordSize+1 && bytes.IndexByte(pending, '\n') < 0 {
+			return fmt.Errorf("audit file offset %d: record too long", offset)
+		}
+
+		for {
+			i := bytes.LastIndexByte(pending, '\n')
+			if i < 0 {
+				break
+			}
+			more, err := emit(pending[i+1:], offset+int64(i)+1)
+			if err != nil || !more {
+				return err
+			}
+			pending = pending[:i]
+		}
+	}
+	_, err = emit(pending, 0)
+	return err
+}
+
+// Verify walks the whole file and reports the first record whose hash or
+// link to the previous record does not match.
+func (a *Auditor) Verify() error {
+	prev := ""
+	return a.each(a.written(), func(line int, record Record) error {
+		if record.PrevHash != prev {
+			return fmt.Errorf("record %d (%s): chain broken", line, record.ID)
+		}
+		want := record.Hash
+		record.Hash = ""
+		got, err := hashRecord(&record)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("record %d (%s): hash mismatch", line, record.ID)
+		}
+		prev = want
+		return nil
+	})
+}
+
+// Close stops accepting records, waits until the queued ones have been
+// published or ctx is done, and closes the file.
+func (a *Auditor) Close(ctx context.Context) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	close(a.publish)
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+	case <-ctx.Done():
+		a.logger.Error("Audit records left unpublished", "records", len(a.publish))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
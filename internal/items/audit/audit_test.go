@@ -0,0 +1,261 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/msgbroker"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/segmentio/kafka-go"
+)
+
+// newTestAuditor returns an auditor writing to a file in a temporary
+// directory and publishing to a broker nobody listens on.
+func newTestAuditor(t *testing.T, path string) *Auditor {
+	t.Helper()
+	cfg := config.Default()
+	cfg.Audit.File = path
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	broker := msgbroker.NewMsgBroker(&kafka.Writer{Addr: kafka.TCP("127.0.0.1:1"), MaxAttempts: 1}, logger)
+
+	a, err := New(cfg, broker, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		a.Close(ctx)
+		broker.Close(ctx)
+	})
+	return a
+}
+
+// logRecords writes n records for resources "r0" to "r<n-1>", padded so
+// that they span several chunks of eachReverse.
+func logRecords(a *Auditor, n int) {
+	padding := strings.Repeat("x", 500)
+	for i := range n {
+		a.LogSystem(context.Background(), Entry{
+			Action:     "test.action",
+			Resource:   fmt.Sprintf("r%d", i),
+			ResourceID: fmt.Sprintf("id-%d", i%3),
+			After:      map[string]string{"padding": padding},
+		}, nil)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	a := newTestAuditor(t, filepath.Join(t.TempDir(), "audit.log"))
+	logRecords(a, 400)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+		count  int
+	}{
+		{"newest first", Filter{Limit: 3}, []string{"r399", "r398", "r397"}, 3},
+		{"filtered", Filter{ResourceID: "id-0", Limit: 3}, []string{"r399", "r396", "r393"}, 3},
+		{"oldest record", Filter{Resource: "r0"}, []string{"r0"}, 1},
+		{"no match", Filter{Action: "other.action"}, nil, 0},
+		{"default limit", Filter{}, nil, DefaultLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := a.Query(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(records) != tt.count {
+				t.Fatalf("got %d records, want %d", len(records), tt.count)
+			}
+			if tt.want == nil {
+				return
+			}
+			var got []string
+			for _, r := range records {
+				got = append(got, r.Resource)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("resources = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines [][]byte) [][]byte
+		want   string
+	}{
+		{"intact", func(lines [][]byte) [][]byte { return lines }, ""},
+		{"edited record", func(lines [][]byte) [][]byte {
+			lines[1] = bytes.Replace(lines[1], []byte(`"resource":"r1"`), []byte(`"resource":"r9"`), 1)
+			return lines
+		}, "record 2"},
+		{"deleted record", func(lines [][]byte) [][]byte {
+			return append(lines[:1], lines[2:]...)
+		}, "record 2"},
+		{"reordered records", func(lines [][]byte) [][]byte {
+			lines[1], lines[2] = lines[2], lines[1]
+			return lines
+		}, "record 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "audit.log")
+			a := newTestAuditor(t, path)
+			logRecords(a, 4)
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n"))
+			tampered := append(bytes.Join(tt.tamper(lines), []byte("\n")), '\n')
+			if err := os.WriteFile(path, tampered, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			a.mu.Lock()
+			a.size = int64(len(tampered))
+			a.mu.Unlock()
+			err = a.Verify()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("Verify = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("Verify = %v, want an error about %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResumesChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	first := newTestAuditor(t, path)
+	logRecords(first, 200)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	first.Close(ctx)
+
+	second := newTestAuditor(t, path)
+	logRecords(second, 1)
+	if err := second.Verify(); err != nil {
+		t.Fatalf("Verify after reopening = %v", err)
+	}
+}
+
+func TestLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestAuditor(t, filepath.Join(t.TempDir(), "audit.log"))
+	a.config.JWT.SecretKey = "test-secret"
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name    string
+		claims  jwt.MapClaims
+		err     error
+		after   any
+		actor   string
+		actedBy string
+		outcome Outcome
+	}{
+		{"success", jwt.MapClaims{"user_id": "user-1", "role": "user", "iat": now}, nil, nil, "user-1", "", OutcomeSuccess},
+		{"failure", jwt.MapClaims{"user_id": "admin-1", "role": "admin", "iat": now}, errors.New("boom"), nil, "admin-1", "", OutcomeFailure},
+		{"impersonation", jwt.MapClaims{"user_id": "user-1", "role": "user", "act": map[string]any{"sub": "admin-1"}, "iat": now}, nil, nil, "user-1", "admin-1", OutcomeSuccess},
+		{"secrets are redacted", jwt.MapClaims{"user_id": "user-1", "role": "user", "iat": now}, nil, gin.H{"password": "hunter2", "email": "a@b.c"}, "user-1", "", OutcomeSuccess},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := middleware.SignToken(tt.claims, a.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/user/profile", nil)
+			c.Request.Header.Set("Authorization", token)
+
+			resourceId := fmt.Sprintf("log-%d", i)
+			a.Log(c, Entry{Action: "test.log", Resource: "test", ResourceID: resourceId, After: tt.after}, tt.err)
+
+			records, err := a.Query(Filter{ResourceID: resourceId})
+			if err != nil || len(records) != 1 {
+				t.Fatalf("Query = %v, %v, want one record", records, err)
+			}
+			r := records[0]
+			if r.ActorID != tt.actor || r.ActedBy != tt.actedBy || r.Outcome != tt.outcome {
+				t.Fatalf("record actor %q, acted by %q, outcome %s; want %q, %q, %s", r.ActorID, r.ActedBy, r.Outcome, tt.actor, tt.actedBy, tt.outcome)
+			}
+			if tt.err != nil && r.Error != tt.err.Error() {
+				t.Fatalf("error = %q, want %q", r.Error, tt.err)
+			}
+			if bytes.Contains(r.After, []byte("hunter2")) {
+				t.Fatalf("after = %s, want the password redacted", r.After)
+			}
+		})
+	}
+	if err := a.Verify(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecordImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	a := newTestAuditor(t, filepath.Join(t.TempDir(), "audit.log"))
+	a.config.JWT.SecretKey = "test-secret"
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		status   int
+		recorded bool
+		outcome  Outcome
+	}{
+		{"user's own request", jwt.MapClaims{"user_id": "user-1", "role": "user", "iat": now}, http.StatusOK, false, ""},
+		{"impersonated request", jwt.MapClaims{"user_id": "user-2", "role": "user", "act": map[string]any{"sub": "admin-1"}, "iat": now}, http.StatusOK, true, OutcomeSuccess},
+		{"impersonated request refused", jwt.MapClaims{"user_id": "user-3", "role": "user", "act": map[string]any{"sub": "admin-1"}, "iat": now}, http.StatusForbidden, true, OutcomeFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := middleware.SignToken(tt.claims, a.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router := gin.New()
+			router.GET("/user/account/list", a.RecordImpersonation(), func(c *gin.Context) { c.Status(tt.status) })
+			req := httptest.NewRequest(http.MethodGet, "/user/account/list", nil)
+			req.Header.Set("Authorization", token)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			records, err := a.Query(Filter{ActorID: tt.claims["user_id"].(string), Action: "impersonation.request"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if recorded := len(records) == 1; recorded != tt.recorded {
+				t.Fatalf("records = %v, want recorded %v", records, tt.recorded)
+			}
+			if tt.recorded && (records[0].ActedBy != "admin-1" || records[0].Outcome != tt.outcome || records[0].ResourceID != "GET /user/account/list") {
+				t.Fatalf("record = %+v", records[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package audit
+
+import (
+	"fmt"
+
+	"gateway-service/internal/items/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordImpersonation audits every request made with an impersonation
+// token, whether or not it succeeds. Records name both the user and the
+// admin acting as them.
+func (a *Auditor) RecordImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if middleware.GetImpersonator(c, a.config) == "" {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		status := c.Writer.Status()
+		var err error
+		if status >= 400 {
+			err = fmt.Errorf("status %d", status)
+		}
+		a.Log(c, Entry{
+			Action:     "impersonation.request",
+			Resource:   "route",
+			ResourceID: c.Request.Method + " " + c.Request.URL.Path,
+			After:      gin.H{"status": status},
+		}, err)
+	}
+}
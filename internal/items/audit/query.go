@@ -0,0 +1,57 @@
+package audit
+
+import "time"
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// Filter selects audit records. Zero fields match everything.
+type Filter struct {
+	ActorID    string
+	ActedBy    string
+	Action     string
+	Resource   string
+	ResourceID string
+	Outcome    Outcome
+	From       time.Time
+	To         time.Time
+	Limit      int
+}
+
+func (f Filter) matches(r Record) bool {
+	switch {
+	case f.ActorID != "" && r.ActorID != f.ActorID,
+		f.ActedBy != "" && r.ActedBy != f.ActedBy,
+		f.Action != "" && r.Action != f.Action,
+		f.Resource != "" && r.Resource != f.Resource,
+		f.ResourceID != "" && r.ResourceID != f.ResourceID,
+		f.Outcome != "" && r.Outcome != f.Outcome,
+		!f.From.IsZero() && r.Time.Before(f.From),
+		!f.To.IsZero() && !r.Time.Before(f.To):
+		return false
+	}
+	return true
+}
+
+// Query returns the records matching f, newest first. The file is read
+// from the end and no further than the oldest record returned.
+func (a *Auditor) Query(f Filter) ([]Record, error) {
+	if f.Limit <= 0 {
+		f.Limit = DefaultLimit
+	}
+	f.Limit = min(f.Limit, MaxLimit)
+
+	result := []Record{}
+	err := a.eachReverse(a.written(), func(record Record) bool {
+		if f.matches(record) {
+			result = append(result, record)
+		}
+		return len(result) < f.Limit
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -1,55 +1,605 @@
 package config
 
 import (
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
-	"github.com/joho/godotenv"
+	"gateway-service/internal/pkg/profile"
+	"gateway-service/internal/pkg/secrets"
 )
 
+// Every setting is declared once here. The yaml tags give the dotted key
+// used in config files and as the CLI flag name (e.g. -server.port), env
+// names the environment variable, secret hides the value when the
+// effective config is printed and reload marks settings that a running
+// gateway picks up without a restart.
 type (
 	Config struct {
-		Server ServerConfig
-		Redis  RedisConfig
-		JWT    JWTConfig
-		Kafka  KafkaConfig
+		Server         ServerConfig         `yaml:"server"`
+		Upstream       UpstreamConfig       `yaml:"upstream"`
+		Redis          RedisConfig          `yaml:"redis"`
+		JWT            JWTConfig            `yaml:"jwt"`
+		Kafka          KafkaConfig          `yaml:"kafka"`
+		Lifecycle      LifecycleConfig      `yaml:"lifecycle"`
+		Tracing        TracingConfig        `yaml:"tracing"`
+		Logging        LoggingConfig        `yaml:"logging"`
+		Cache          CacheConfig          `yaml:"cache"`
+		RateLimit      RateLimitConfig      `yaml:"rate_limit"`
+		CORS           CORSConfig           `yaml:"cors"`
+		Reload         ReloadConfig         `yaml:"reload"`
+		Secrets        SecretsConfig        `yaml:"secrets"`
+		Security       SecurityConfig       `yaml:"security"`
+		Audit          AuditConfig          `yaml:"audit"`
+		Verification   VerificationConfig   `yaml:"verification"`
+		Password       PasswordConfig       `yaml:"password"`
+		PasswordReset  PasswordResetConfig  `yaml:"password_reset"`
+		MFA            MFAConfig            `yaml:"mfa"`
+		StepUp         StepUpConfig         `yaml:"step_up"`
+		OIDC           OIDCConfig           `yaml:"oidc"`
+		PersonalTokens PersonalTokensConfig `yaml:"personal_tokens"`
+		Impersonation  ImpersonationConfig  `yaml:"impersonation"`
+		Profile        ProfileConfig        `yaml:"profile"`
+		Export         ExportConfig         `yaml:"export"`
+		Deletion       DeletionConfig       `yaml:"deletion"`
+		Roles          RolesConfig          `yaml:"roles"`
+
+		file    string
+		secrets *secrets.Manager
 	}
 	JWTConfig struct {
-		SecretKey string
+		SecretKey string `yaml:"secret_key" env:"JWT_SECRET_KEY" secret:"true"`
+		// PreviousSecretKey is still accepted when verifying tokens so the
+		// signing key can be rotated without logging everyone out.
+		PreviousSecretKey string `yaml:"previous_secret_key" env:"JWT_PREVIOUS_SECRET_KEY" secret:"true"`
+		// AccessTokenTTL is the lifetime of tokens issued by the auth
+		// service; session revocations are remembered this long.
+		AccessTokenTTL time.Duration `yaml:"access_token_ttl" env:"JWT_ACCESS_TOKEN_TTL"`
 	}
 	ServerConfig struct {
-		ServerPort    string
-		AuthPort      string
-		BudgetingPort string
+		Host              string        `yaml:"host" env:"SERVER_HOST"`
+		Port              int           `yaml:"port" env:"SERVER_PORT"`
+		ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT"`
+		ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT"`
+		WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT"`
+		IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT"`
+		MaxHeaderBytes    int           `yaml:"max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+		TLS               ServerTLS     `yaml:"tls" env:"SERVER_TLS"`
+		// TrustedProxies lists the IPs and CIDRs whose X-Forwarded-For and
+		// X-Real-IP headers are believed when finding the client IP used
+		// for rate limits, audit records and sessions. None by default.
+		TrustedProxies []string `yaml:"trusted_proxies" env:"SERVER_TRUSTED_PROXIES"`
+	}
+	// ServerTLS enables HTTPS on the public listener. The certificate and
+	// key are re-read when the files change, e.g. SERVER_TLS_CERT_FILE.
+	ServerTLS struct {
+		Enabled  bool   `yaml:"enabled" env:"ENABLED"`
+		CertFile string `yaml:"cert_file" env:"CERT_FILE"`
+		KeyFile  string `yaml:"key_file" env:"KEY_FILE"`
+		// ReloadInterval is how often the files are checked for changes.
+		ReloadInterval time.Duration `yaml:"reload_interval" env:"RELOAD_INTERVAL"`
+	}
+	UpstreamConfig struct {
+		Auth      Endpoint `yaml:"auth" env:"AUTH"`
+		Budgeting Endpoint `yaml:"budgeting" env:"BUDGETING"`
+	}
+	// Endpoint is a host and port. Its env names are prefixed by the env
+	// tag of the field holding it, e.g. AUTH_HOST and AUTH_PORT.
+	Endpoint struct {
+		Host string    `yaml:"host" env:"HOST"`
+		Port int       `yaml:"port" env:"PORT"`
+		TLS  ClientTLS `yaml:"tls" env:"TLS"`
+	}
+	// ClientTLS secures a gRPC upstream connection, e.g. AUTH_TLS_CA_FILE.
+	// CAFile verifies the server; CertFile and KeyFile, when both set, are
+	// presented as the client certificate for mutual TLS.
+	ClientTLS struct {
+		Enabled    bool   `yaml:"enabled" env:"ENABLED"`
+		CAFile     string `yaml:"ca_file" env:"CA_FILE"`
+		CertFile   string `yaml:"cert_file" env:"CERT_FILE"`
+		KeyFile    string `yaml:"key_file" env:"KEY_FILE"`
+		ServerName string `yaml:"server_name" env:"SERVER_NAME"`
 	}
 	RedisConfig struct {
-		Host string
-		Port string
+		Host     string `yaml:"host" env:"REDIS_HOST"`
+		Port     int    `yaml:"port" env:"REDIS_PORT"`
+		Password string `yaml:"password" env:"REDIS_PASSWORD" secret:"true"`
+		DB       int    `yaml:"db" env:"REDIS_DB"`
 	}
 	KafkaConfig struct {
-		Brokers string
+		Brokers string `yaml:"brokers" env:"KAFKA_BROKER_URI"`
+		// SASLMechanism is one of "none", "plain", "scram-sha-256" or "scram-sha-512".
+		SASLMechanism string `yaml:"sasl_mechanism" env:"KAFKA_SASL_MECHANISM"`
+		SASLUsername  string `yaml:"sasl_username" env:"KAFKA_SASL_USERNAME" secret:"true"`
+		SASLPassword  string `yaml:"sasl_password" env:"KAFKA_SASL_PASSWORD" secret:"true"`
+	}
+	LifecycleConfig struct {
+		StartupTimeout  time.Duration `yaml:"startup_timeout" env:"STARTUP_TIMEOUT"`
+		ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+		// DrainDelay is how long /readyz fails before the listener closes
+		// on shutdown. It is part of ShutdownTimeout.
+		DrainDelay time.Duration `yaml:"drain_delay" env:"SHUTDOWN_DRAIN_DELAY"`
+	}
+	TracingConfig struct {
+		// Exporter is one of "otlp", "file" or "none".
+		Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER"`
+		OTLPEndpoint string  `yaml:"otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+		FilePath     string  `yaml:"file" env:"TRACING_FILE"`
+		ServiceName  string  `yaml:"service_name" env:"OTEL_SERVICE_NAME"`
+		SampleRatio  float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+	}
+	LoggingConfig struct {
+		// Output is one of "stdout", "file" or "both".
+		Output string `yaml:"output" env:"LOG_OUTPUT"`
+		File   string `yaml:"file" env:"LOG_FILE"`
+		Level  string `yaml:"level" env:"LOG_LEVEL" reload:"true"`
+		// Levels overrides Level per component, e.g. "msgbroker=debug,http=warn".
+		Levels         map[string]string `yaml:"levels" env:"LOG_LEVELS" reload:"true"`
+		MaxSizeMB      int               `yaml:"max_size_mb" env:"LOG_MAX_SIZE_MB"`
+		RotateInterval time.Duration     `yaml:"rotate_interval" env:"LOG_ROTATE_INTERVAL"`
+		MaxBackups     int               `yaml:"max_backups" env:"LOG_MAX_BACKUPS"`
+		MaxAge         time.Duration     `yaml:"max_age" env:"LOG_MAX_AGE"`
+	}
+	CacheConfig struct {
+		AccountTTL time.Duration `yaml:"account_ttl" env:"CACHE_ACCOUNT_TTL" reload:"true"`
+	}
+	RateLimitConfig struct {
+		Enabled           bool `yaml:"enabled" env:"RATE_LIMIT_ENABLED" reload:"true"`
+		RequestsPerMinute int  `yaml:"requests_per_minute" env:"RATE_LIMIT_RPM" reload:"true"`
+		Burst             int  `yaml:"burst" env:"RATE_LIMIT_BURST" reload:"true"`
+	}
+	CORSConfig struct {
+		// AllowOrigins lists the allowed origins. An entry may use a
+		// wildcard subdomain, e.g. "https://*.example.com", and "*" allows
+		// any origin but only without credentials.
+		AllowOrigins     []string `yaml:"allow_origins" env:"CORS_ALLOW_ORIGINS" reload:"true"`
+		AllowMethods     []string `yaml:"allow_methods" env:"CORS_ALLOW_METHODS" reload:"true"`
+		AllowHeaders     []string `yaml:"allow_headers" env:"CORS_ALLOW_HEADERS" reload:"true"`
+		ExposeHeaders    []string `yaml:"expose_headers" env:"CORS_EXPOSE_HEADERS" reload:"true"`
+		AllowCredentials bool     `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" reload:"true"`
+		// MaxAge is how long browsers may cache a preflight response.
+		MaxAge time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" reload:"true"`
+		Groups CORSGroups    `yaml:"groups" env:"CORS"`
+	}
+	// CORSGroups overrides the CORS policy for the top-level route groups.
+	CORSGroups struct {
+		Auth       CORSOverride `yaml:"auth" env:"AUTH"`
+		Admin      CORSOverride `yaml:"admin" env:"ADMIN"`
+		Superadmin CORSOverride `yaml:"superadmin" env:"SUPERADMIN"`
+		User       CORSOverride `yaml:"user" env:"USER"`
+	}
+	// CORSOverride replaces the matching CORSConfig list when non-empty,
+	// e.g. CORS_ADMIN_ALLOW_ORIGINS.
+	CORSOverride struct {
+		AllowOrigins []string `yaml:"allow_origins" env:"ALLOW_ORIGINS" reload:"true"`
+		AllowMethods []string `yaml:"allow_methods" env:"ALLOW_METHODS" reload:"true"`
+		AllowHeaders []string `yaml:"allow_headers" env:"ALLOW_HEADERS" reload:"true"`
+	}
+	ReloadConfig struct {
+		// WatchInterval is how often the config file is checked for changes.
+		WatchInterval time.Duration `yaml:"watch_interval" env:"CONFIG_WATCH_INTERVAL"`
+	}
+	// SecurityConfig controls response security headers and request limits.
+	// An empty header value leaves that header unset.
+	SecurityConfig struct {
+		// HSTSMaxAge of zero disables Strict-Transport-Security.
+		HSTSMaxAge            time.Duration `yaml:"hsts_max_age" env:"SECURITY_HSTS_MAX_AGE"`
+		HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains" env:"SECURITY_HSTS_INCLUDE_SUBDOMAINS"`
+		ContentTypeOptions    string        `yaml:"content_type_options" env:"SECURITY_CONTENT_TYPE_OPTIONS"`
+		FrameOptions          string        `yaml:"frame_options" env:"SECURITY_FRAME_OPTIONS"`
+		ReferrerPolicy        string        `yaml:"referrer_policy" env:"SECURITY_REFERRER_POLICY"`
+		ContentSecurityPolicy string        `yaml:"content_security_policy" env:"SECURITY_CSP"`
+		// SwaggerContentSecurityPolicy replaces ContentSecurityPolicy under
+		// /swagger/, whose UI needs inline scripts and styles.
+		SwaggerContentSecurityPolicy string `yaml:"swagger_content_security_policy" env:"SECURITY_SWAGGER_CSP"`
+		MaxBodyBytes                 int    `yaml:"max_body_bytes" env:"SECURITY_MAX_BODY_BYTES"`
+		MaxJSONDepth                 int    `yaml:"max_json_depth" env:"SECURITY_MAX_JSON_DEPTH"`
+		// AllowedContentTypes lists the media types accepted for request
+		// bodies; requests with any other Content-Type get 415.
+		AllowedContentTypes []string `yaml:"allowed_content_types" env:"SECURITY_ALLOWED_CONTENT_TYPES"`
+	}
+	AuditConfig struct {
+		// File is the append-only, hash-chained audit log.
+		File string `yaml:"file" env:"AUDIT_FILE"`
+	}
+	VerificationConfig struct {
+		// URL is the page verification emails link to; the token is
+		// appended as the token query parameter.
+		URL      string        `yaml:"url" env:"VERIFICATION_URL"`
+		TokenTTL time.Duration `yaml:"token_ttl" env:"VERIFICATION_TOKEN_TTL"`
+		// ResendCooldown is the minimum time between verification emails
+		// to the same user.
+		ResendCooldown time.Duration `yaml:"resend_cooldown" env:"VERIFICATION_RESEND_COOLDOWN"`
+	}
+	// PasswordConfig is the strength policy for new passwords. Character
+	// classes are lower case, upper case, digits and symbols.
+	PasswordConfig struct {
+		MinLength      int `yaml:"min_length" env:"PASSWORD_MIN_LENGTH"`
+		MaxLength      int `yaml:"max_length" env:"PASSWORD_MAX_LENGTH"`
+		MinCharClasses int `yaml:"min_char_classes" env:"PASSWORD_MIN_CHAR_CLASSES"`
+	}
+	PasswordResetConfig struct {
+		// URL is the page reset emails link to; the token is appended as
+		// the token query parameter.
+		URL      string        `yaml:"url" env:"PASSWORD_RESET_URL"`
+		TokenTTL time.Duration `yaml:"token_ttl" env:"PASSWORD_RESET_TOKEN_TTL"`
+		// Cooldown is the minimum time between reset emails to the same
+		// user.
+		Cooldown time.Duration `yaml:"cooldown" env:"PASSWORD_RESET_COOLDOWN"`
+	}
+	// MFAConfig controls TOTP two-factor authentication.
+	MFAConfig struct {
+		// Issuer is the account name shown in authenticator apps.
+		Issuer string `yaml:"issuer" env:"MFA_ISSUER"`
+		// EncryptionKey encrypts TOTP secrets and pending logins in Redis.
+		EncryptionKey string `yaml:"encryption_key" env:"MFA_ENCRYPTION_KEY" secret:"true"`
+		// EnrollmentTTL is how long a new secret waits to be activated.
+		EnrollmentTTL time.Duration `yaml:"enrollment_ttl" env:"MFA_ENROLLMENT_TTL"`
+		// ChallengeTTL is how long the second login step may take.
+		ChallengeTTL time.Duration `yaml:"challenge_ttl" env:"MFA_CHALLENGE_TTL"`
+		// MaxAttempts is how many wrong codes end a login challenge.
+		MaxAttempts   int `yaml:"max_attempts" env:"MFA_MAX_ATTEMPTS"`
+		RecoveryCodes int `yaml:"recovery_codes" env:"MFA_RECOVERY_CODES"`
+		// LockoutAttempts is how many failed second factors, across login
+		// challenges and re-authentication, lock a user out for Lockout.
+		LockoutAttempts int           `yaml:"lockout_attempts" env:"MFA_LOCKOUT_ATTEMPTS"`
+		Lockout         time.Duration `yaml:"lockout" env:"MFA_LOCKOUT"`
+	}
+	// StepUpConfig controls re-authentication for sensitive routes.
+	StepUpConfig struct {
+		// MaxAuthAge is how recent the login behind a token must be for it
+		// to reach a sensitive route without an elevation token.
+		MaxAuthAge time.Duration `yaml:"max_auth_age" env:"STEP_UP_MAX_AUTH_AGE"`
+		// ElevationTTL is how long an elevation token stays valid.
+		ElevationTTL time.Duration `yaml:"elevation_ttl" env:"STEP_UP_ELEVATION_TTL"`
+	}
+	// OIDCConfig controls sign-in through external identity providers.
+	OIDCConfig struct {
+		// CallbackBaseURL is where providers send the browser back to; the
+		// redirect URI of a provider is <base>/<provider>/callback and must
+		// be registered with it.
+		CallbackBaseURL string `yaml:"callback_base_url" env:"OIDC_CALLBACK_BASE_URL"`
+		// StateTTL is how long a login may take at the provider.
+		StateTTL time.Duration `yaml:"state_ttl" env:"OIDC_STATE_TTL"`
+		Google   OIDCProvider  `yaml:"google" env:"OIDC_GOOGLE"`
+		// GitHub speaks plain OAuth2, so it needs no issuer.
+		GitHub OIDCProvider `yaml:"github" env:"OIDC_GITHUB"`
+		// Generic is any other OpenID provider, e.g. Keycloak or
+		// cmd/mock-oidc in development.
+		Generic OIDCProvider `yaml:"generic" env:"OIDC_GENERIC"`
+	}
+	// OIDCProvider is enabled when ClientID is set, e.g.
+	// OIDC_GOOGLE_CLIENT_ID.
+	OIDCProvider struct {
+		ClientID     string `yaml:"client_id" env:"CLIENT_ID"`
+		ClientSecret string `yaml:"client_secret" env:"CLIENT_SECRET" secret:"true"`
+		// Issuer is where the provider's discovery document is found.
+		Issuer string   `yaml:"issuer" env:"ISSUER"`
+		Scopes []string `yaml:"scopes" env:"SCOPES"`
+	}
+	// PersonalTokensConfig limits personal access tokens.
+	PersonalTokensConfig struct {
+		// DefaultTTL applies when a token is created without an expiry.
+		DefaultTTL time.Duration `yaml:"default_ttl" env:"PERSONAL_TOKENS_DEFAULT_TTL"`
+		MaxTTL     time.Duration `yaml:"max_ttl" env:"PERSONAL_TOKENS_MAX_TTL"`
+		MaxPerUser int           `yaml:"max_per_user" env:"PERSONAL_TOKENS_MAX_PER_USER"`
+	}
+	// ImpersonationConfig limits how long admins may act as a user.
+	ImpersonationConfig struct {
+		DefaultTTL time.Duration `yaml:"default_ttl" env:"IMPERSONATION_DEFAULT_TTL"`
+		MaxTTL     time.Duration `yaml:"max_ttl" env:"IMPERSONATION_MAX_TTL"`
+	}
+	// ProfileConfig holds the preferences of users who have not set their
+	// own.
+	ProfileConfig struct {
+		Currency       string `yaml:"currency" env:"PROFILE_CURRENCY"`
+		Locale         string `yaml:"locale" env:"PROFILE_LOCALE"`
+		Timezone       string `yaml:"timezone" env:"PROFILE_TIMEZONE"`
+		FirstDayOfWeek string `yaml:"first_day_of_week" env:"PROFILE_FIRST_DAY_OF_WEEK"`
+	}
+	// ExportConfig controls personal data exports.
+	ExportConfig struct {
+		// DownloadURL is where archives are downloaded; the signed token
+		// is appended as the token query parameter.
+		DownloadURL string `yaml:"download_url" env:"EXPORT_DOWNLOAD_URL"`
+		// LinkTTL is how long a download link works after the job status
+		// was fetched, Retention how long the archive is kept.
+		LinkTTL   time.Duration `yaml:"link_ttl" env:"EXPORT_LINK_TTL"`
+		Retention time.Duration `yaml:"retention" env:"EXPORT_RETENTION"`
+		// Timeout limits building an archive. Jobs still pending after it,
+		// left by a gateway that stopped, are marked failed.
+		Timeout time.Duration `yaml:"timeout" env:"EXPORT_TIMEOUT"`
+		// MaxSizeMB caps an archive, which is kept in Redis.
+		MaxSizeMB int `yaml:"max_size_mb" env:"EXPORT_MAX_SIZE_MB"`
+		// MaxPerWindow exports may be started by a user per Window.
+		MaxPerWindow int           `yaml:"max_per_window" env:"EXPORT_MAX_PER_WINDOW"`
+		Window       time.Duration `yaml:"window" env:"EXPORT_WINDOW"`
+	}
+	// DeletionConfig controls self-service account deletion.
+	DeletionConfig struct {
+		// GracePeriod is how long a user can cancel by logging in.
+		GracePeriod time.Duration `yaml:"grace_period" env:"DELETION_GRACE_PERIOD"`
+		// Deletions that are due are looked for every SweepInterval and
+		// each sweep may take up to Timeout.
+		SweepInterval time.Duration `yaml:"sweep_interval" env:"DELETION_SWEEP_INTERVAL"`
+		Timeout       time.Duration `yaml:"timeout" env:"DELETION_TIMEOUT"`
+		// RetryAfter is how long a failed deletion waits to be retried.
+		RetryAfter time.Duration `yaml:"retry_after" env:"DELETION_RETRY_AFTER"`
+	}
+	RolesConfig struct {
+		// SyncInterval is how often role assignments made through another
+		// gateway are picked up.
+		SyncInterval time.Duration `yaml:"sync_interval" env:"ROLES_SYNC_INTERVAL"`
+	}
+	SecretsConfig struct {
+		// Dir holds one file per secret named after its lower-cased env
+		// name, as mounted by Docker and Kubernetes.
+		Dir string `yaml:"dir" env:"SECRETS_DIR"`
+		// ProviderFile is a JSON file of secrets used by the local provider.
+		ProviderFile    string        `yaml:"provider_file" env:"SECRETS_PROVIDER_FILE"`
+		RefreshInterval time.Duration `yaml:"refresh_interval" env:"SECRETS_REFRESH_INTERVAL"`
 	}
 )
 
-func (c *Config) Load() error {
-	if err := godotenv.Load(); err != nil {
-		return err
+// Default returns the configuration every other source is layered on.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:              8080,
+			ReadHeaderTimeout: 5 * time.Second,
+			ReadTimeout:       15 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+			MaxHeaderBytes:    1 << 20,
+			TLS: ServerTLS{
+				ReloadInterval: 30 * time.Second,
+			},
+		},
+		Upstream: UpstreamConfig{
+			Auth:      Endpoint{Host: "auth", Port: 8081},
+			Budgeting: Endpoint{Host: "budgeting", Port: 8082},
+		},
+		Redis: RedisConfig{
+			Host: "redis",
+			Port: 6379,
+		},
+		JWT: JWTConfig{
+			AccessTokenTTL: 24 * time.Hour,
+		},
+		Kafka: KafkaConfig{
+			Brokers:       "kafka:9092",
+			SASLMechanism: "none",
+		},
+		Lifecycle: LifecycleConfig{
+			StartupTimeout:  60 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			DrainDelay:      5 * time.Second,
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "otel-collector:4317",
+			FilePath:     "traces.json",
+			ServiceName:  "gateway",
+			SampleRatio:  1,
+		},
+		Logging: LoggingConfig{
+			Output:         "file",
+			File:           "application.log",
+			Level:          "info",
+			MaxSizeMB:      100,
+			RotateInterval: 24 * time.Hour,
+			MaxBackups:     7,
+			MaxAge:         7 * 24 * time.Hour,
+		},
+		Cache: CacheConfig{
+			AccountTTL: 10 * time.Minute,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           true,
+			RequestsPerMinute: 600,
+			Burst:             100,
+		},
+		CORS: CORSConfig{
+			AllowMethods:  []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+			AllowHeaders:  []string{"Authorization", "Content-Type", "X-Request-ID", "X-Elevation-Token"},
+			ExposeHeaders: []string{"X-Request-ID", "Retry-After", "X-Impersonated-User", "X-Impersonator", "X-Impersonation-Expires"},
+			MaxAge:        10 * time.Minute,
+		},
+		Reload: ReloadConfig{
+			WatchInterval: 5 * time.Second,
+		},
+		Secrets: SecretsConfig{
+			Dir:             "/run/secrets",
+			RefreshInterval: 30 * time.Second,
+		},
+		Security: SecurityConfig{
+			HSTSMaxAge:                   180 * 24 * time.Hour,
+			HSTSIncludeSubdomains:        true,
+			ContentTypeOptions:           "nosniff",
+			FrameOptions:                 "DENY",
+			ReferrerPolicy:               "no-referrer",
+			ContentSecurityPolicy:        "default-src 'none'; frame-ancestors 'none'",
+			SwaggerContentSecurityPolicy: "default-src 'self'; script-src 'self' 'unsafe-inline'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; frame-ancestors 'none'",
+			MaxBodyBytes:                 1 << 20,
+			MaxJSONDepth:                 32,
+			AllowedContentTypes:          []string{"application/json"},
+		},
+		Audit: AuditConfig{
+			File: "audit.log",
+		},
+		Verification: VerificationConfig{
+			URL:            "http://localhost:3000/verify",
+			TokenTTL:       24 * time.Hour,
+			ResendCooldown: time.Minute,
+		},
+		Password: PasswordConfig{
+			MinLength:      10,
+			MaxLength:      128,
+			MinCharClasses: 3,
+		},
+		PasswordReset: PasswordResetConfig{
+			URL:      "http://localhost:3000/reset-password",
+			TokenTTL: time.Hour,
+			Cooldown: time.Minute,
+		},
+		MFA: MFAConfig{
+			Issuer:          "Personal Finance Tracker",
+			EnrollmentTTL:   10 * time.Minute,
+			ChallengeTTL:    5 * time.Minute,
+			MaxAttempts:     5,
+			RecoveryCodes:   10,
+			LockoutAttempts: 10,
+			Lockout:         15 * time.Minute,
+		},
+		StepUp: StepUpConfig{
+			MaxAuthAge:   5 * time.Minute,
+			ElevationTTL: 5 * time.Minute,
+		},
+		OIDC: OIDCConfig{
+			CallbackBaseURL: "http://localhost:8080/auth/oidc",
+			StateTTL:        10 * time.Minute,
+			Google: OIDCProvider{
+				Issuer: "https://accounts.google.com",
+				Scopes: []string{"openid", "email", "profile"},
+			},
+			GitHub: OIDCProvider{
+				Scopes: []string{"read:user", "user:email"},
+			},
+			Generic: OIDCProvider{
+				Scopes: []string{"openid", "email", "profile"},
+			},
+		},
+		PersonalTokens: PersonalTokensConfig{
+			DefaultTTL: 30 * 24 * time.Hour,
+			MaxTTL:     365 * 24 * time.Hour,
+			MaxPerUser: 20,
+		},
+		Impersonation: ImpersonationConfig{
+			DefaultTTL: 15 * time.Minute,
+			MaxTTL:     time.Hour,
+		},
+		Profile: ProfileConfig{
+			Currency:       "USD",
+			Locale:         "en-US",
+			Timezone:       "UTC",
+			FirstDayOfWeek: "monday",
+		},
+		Export: ExportConfig{
+			DownloadURL:  "http://localhost:8080/export/download",
+			LinkTTL:      15 * time.Minute,
+			Retention:    24 * time.Hour,
+			Timeout:      5 * time.Minute,
+			MaxSizeMB:    50,
+			MaxPerWindow: 3,
+			Window:       24 * time.Hour,
+		},
+		Deletion: DeletionConfig{
+			GracePeriod:   30 * 24 * time.Hour,
+			SweepInterval: time.Minute,
+			Timeout:       5 * time.Minute,
+			RetryAfter:    time.Hour,
+		},
+		Roles: RolesConfig{
+			SyncInterval: 10 * time.Second,
+		},
 	}
+}
 
-	c.Server.ServerPort = ":" + os.Getenv("SERVER_PORT")
-	c.Server.AuthPort = ":" + os.Getenv("AUTH_PORT")
-	c.Server.BudgetingPort = ":" + os.Getenv("BUDGETING_PORT")
-	c.Redis.Host = os.Getenv("REDIS_HOST")
-	c.Redis.Port = os.Getenv("REDIS_PORT")
-	c.JWT.SecretKey = os.Getenv("JWT_SECRET_KEY")
-	c.Kafka.Brokers = os.Getenv("KAFKA_BROKER_URI")
+// New loads the configuration from the process arguments and environment.
+func New() (*Config, error) {
+	return Load(os.Args[1:])
+}
 
-	return nil
+// SecretManager returns the manager that resolves and refreshes secret settings.
+func (c *Config) SecretManager() *secrets.Manager {
+	return c.secrets
 }
 
-func New() (*Config, error) {
-	var config Config
-	if err := config.Load(); err != nil {
-		return nil, err
+// JWTSecrets returns the keys accepted when verifying tokens, current key
+// first. Values come from the secrets manager so rotations apply at once.
+func (c *Config) JWTSecrets() []string {
+	keys := []string{c.secretValue("JWT_SECRET_KEY", c.JWT.SecretKey)}
+	if previous := c.secretValue("JWT_PREVIOUS_SECRET_KEY", c.JWT.PreviousSecretKey); previous != "" {
+		keys = append(keys, previous)
+	}
+	return keys
+}
+
+// RedisPassword returns the current Redis password.
+func (c *Config) RedisPassword() string {
+	return c.secretValue("REDIS_PASSWORD", c.Redis.Password)
+}
+
+// MFAKey returns the current key for encrypting TOTP secrets.
+func (c *Config) MFAKey() string {
+	return c.secretValue("MFA_ENCRYPTION_KEY", c.MFA.EncryptionKey)
+}
+
+// OIDCProviders returns the enabled identity providers by name, with
+// their current client secrets.
+func (c *Config) OIDCProviders() map[string]OIDCProvider {
+	providers := make(map[string]OIDCProvider)
+	for name, p := range map[string]OIDCProvider{"google": c.OIDC.Google, "github": c.OIDC.GitHub, "generic": c.OIDC.Generic} {
+		if p.ClientID == "" {
+			continue
+		}
+		p.ClientSecret = c.secretValue("OIDC_"+strings.ToUpper(name)+"_CLIENT_SECRET", p.ClientSecret)
+		providers[name] = p
+	}
+	return providers
+}
+
+// KafkaCredentials returns the current SASL username and password.
+func (c *Config) KafkaCredentials() (string, string) {
+	return c.secretValue("KAFKA_SASL_USERNAME", c.Kafka.SASLUsername),
+		c.secretValue("KAFKA_SASL_PASSWORD", c.Kafka.SASLPassword)
+}
+
+func (c *Config) secretValue(key, fallback string) string {
+	if c.secrets != nil {
+		if value, ok := c.secrets.Get(key); ok {
+			return value
+		}
+	}
+	return fallback
+}
+
+// File returns the config file the configuration was loaded from, if any.
+func (c *Config) File() string {
+	return c.file
+}
+
+func (s ServerConfig) Address() string {
+	return net.JoinHostPort(s.Host, strconv.Itoa(s.Port))
+}
+
+func (e Endpoint) Address() string {
+	return net.JoinHostPort(e.Host, strconv.Itoa(e.Port))
+}
+
+func (r RedisConfig) Address() string {
+	return net.JoinHostPort(r.Host, strconv.Itoa(r.Port))
+}
+
+// BrokerList splits the comma-separated broker addresses.
+func (k KafkaConfig) BrokerList() []string {
+	var brokers []string
+	for _, b := range strings.Split(k.Brokers, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			brokers = append(brokers, b)
+		}
+	}
+	return brokers
+}
+
+// Preferences returns the default user preferences.
+func (c ProfileConfig) Preferences() profile.Preferences {
+	return profile.Preferences{
+		Currency:       c.Currency,
+		Locale:         c.Locale,
+		Timezone:       c.Timezone,
+		FirstDayOfWeek: c.FirstDayOfWeek,
 	}
-	return &config, nil
 }
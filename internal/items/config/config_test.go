@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// setRequired sets the settings Default leaves empty through the
+// environment and points the secret providers at an empty directory.
+func setRequired(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", "test-secret")
+	t.Setenv("MFA_ENCRYPTION_KEY", "test-mfa-key")
+	t.Setenv("SECRETS_DIR", t.TempDir())
+	t.Setenv("CONFIG_FILE", "")
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayering(t *testing.T) {
+	yamlFile := "server:\n  port: 9000\n  trusted_proxies: [10.0.0.0/8, 192.168.1.1]\nupstream:\n  auth:\n    host: auth.internal\nlogging:\n  levels:\n    msgbroker: debug\n"
+	tomlFile := "[server]\nport = 9100\n\n[upstream.auth]\nhost = \"auth.toml\"\n"
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		env      map[string]string
+		args     []string
+		port     int
+		authHost string
+	}{
+		{"defaults", "", "", nil, nil, 8080, "auth"},
+		{"yaml file over defaults", "gateway.yaml", yamlFile, nil, nil, 9000, "auth.internal"},
+		{"toml file over defaults", "gateway.toml", tomlFile, nil, nil, 9100, "auth.toml"},
+		{"env over file", "gateway.yaml", yamlFile, map[string]string{"SERVER_PORT": "9001", "AUTH_HOST": "auth.env"}, nil, 9001, "auth.env"},
+		{"flag over env", "gateway.yaml", yamlFile, map[string]string{"SERVER_PORT": "9001"}, []string{"-server.port", "9002"}, 9002, "auth.internal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			args := tt.args
+			if tt.file != "" {
+				args = append([]string{"-config", writeFile(t, tt.file, tt.content)}, args...)
+			}
+
+			c, err := Load(args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.Server.Port != tt.port {
+				t.Errorf("server.port = %d, want %d", c.Server.Port, tt.port)
+			}
+			if c.Upstream.Auth.Host != tt.authHost {
+				t.Errorf("upstream.auth.host = %q, want %q", c.Upstream.Auth.Host, tt.authHost)
+			}
+			if tt.file == "gateway.yaml" {
+				if got := strings.Join(c.Server.TrustedProxies, ","); got != "10.0.0.0/8,192.168.1.1" {
+					t.Errorf("server.trusted_proxies = %q", got)
+				}
+				if c.Logging.Levels["msgbroker"] != "debug" {
+					t.Errorf("logging.levels = %v", c.Logging.Levels)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		want    string
+	}{
+		{"unknown file key", "gateway.yaml", "server:\n  prot: 9000\n", nil, "unknown key server.prot"},
+		{"unsupported file format", "gateway.json", "{}", nil, "unsupported format"},
+		{"malformed env value", "", "", map[string]string{"SERVER_PORT": "eighty"}, "env SERVER_PORT"},
+		{"missing secret", "", "", map[string]string{"JWT_SECRET_KEY": ""}, "jwt.secret_key is required"},
+		{"invalid value", "", "", map[string]string{"SERVER_TRUSTED_PROXIES": "not-an-ip"}, "server.trusted_proxies"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRequired(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			var args []string
+			if tt.file != "" {
+				args = []string{"-config", writeFile(t, tt.file, tt.content)}
+			}
+
+			_, err := Load(args)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   string
+	}{
+		{"valid", func(c *Config) {}, ""},
+		{"empty upstream host", func(c *Config) { c.Upstream.Budgeting.Host = "" }, "upstream.budgeting.host is required"},
+		{"port out of range", func(c *Config) { c.Redis.Port = 70000 }, "redis.port: 70000 is not a valid port"},
+		{"broker without port", func(c *Config) { c.Kafka.Brokers = "kafka:9092,kafka2" }, `kafka.brokers: "kafka2" is not host:port`},
+		{"sasl without credentials", func(c *Config) { c.Kafka.SASLMechanism = "plain" }, "kafka.sasl_username is required"},
+		{"trusted proxy CIDR", func(c *Config) { c.Server.TrustedProxies = []string{"10.0.0.0/8", "::1"} }, ""},
+		{"trusted proxy hostname", func(c *Config) { c.Server.TrustedProxies = []string{"proxy.internal"} }, `server.trusted_proxies: "proxy.internal" is not an IP or CIDR`},
+		{"wildcard origin with credentials", func(c *Config) {
+			c.CORS.AllowOrigins = []string{"*"}
+			c.CORS.AllowCredentials = true
+		}, "* cannot be combined with cors.allow_credentials"},
+		{"origin with path", func(c *Config) { c.CORS.AllowOrigins = []string{"https://app.example.com/"} }, "cors.allow_origins"},
+		{"wildcard subdomain origin", func(c *Config) { c.CORS.Groups.Admin.AllowOrigins = []string{"https://*.example.com"} }, ""},
+		{"drain longer than shutdown", func(c *Config) { c.Lifecycle.DrainDelay = time.Minute }, "lifecycle.drain_delay must be shorter"},
+		{"unknown frame option", func(c *Config) { c.Security.FrameOptions = "ALLOW" }, "security.frame_options"},
+		{"malformed content type", func(c *Config) { c.Security.AllowedContentTypes = []string{"/json"} }, "security.allowed_content_types"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			c.JWT.SecretKey = "test-secret"
+			c.MFA.EncryptionKey = "test-mfa-key"
+			tt.modify(c)
+
+			err := c.Validate()
+			switch {
+			case tt.want == "" && err != nil:
+				t.Fatalf("err = %v, want nil", err)
+			case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+				t.Fatalf("err = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEffectiveMasksSecrets(t *testing.T) {
+	c := Default()
+	c.JWT.SecretKey = "test-secret"
+	c.Redis.Password = ""
+
+	lines := strings.Join(c.Effective(), "\n")
+	for _, want := range []string{"jwt.secret_key = " + maskedValue, "redis.password = \n", "server.port = 8080"} {
+		if !strings.Contains(lines, want) {
+			t.Errorf("effective config does not contain %q", want)
+		}
+	}
+	if strings.Contains(lines, "test-secret") {
+		t.Error("effective config contains the secret value")
+	}
+}
@@ -0,0 +1,319 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gateway-service/internal/pkg/secrets"
+
+	"github.com/joho/godotenv"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const maskedValue = "******"
+
+// field is one leaf setting found by walking Config.
+type field struct {
+	key    string // dotted path, e.g. "upstream.auth.port"
+	env    string
+	secret bool
+	reload bool
+	value  reflect.Value
+}
+
+// Load layers, from lowest to highest precedence: defaults, the config file
+// (-config flag or CONFIG_FILE), a .env file if present, environment
+// variables and command-line flags. Secret settings are then resolved
+// through the secrets providers (environment, KEY_FILE or the secrets
+// directory, then the local provider file), which take precedence over
+// the layers above. The result is validated.
+func Load(args []string) (*Config, error) {
+	c := Default()
+	fields := c.fields()
+
+	fset := flag.NewFlagSet("gateway", flag.ContinueOnError)
+	configFile := fset.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file")
+	flagValues := make(map[string]*string, len(fields))
+	for _, f := range fields {
+		flagValues[f.key] = fset.String(f.key, "", fmt.Sprintf("overrides %s (env %s)", f.key, f.env))
+	}
+	if err := fset.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := c.loadFile(*configFile, fields); err != nil {
+			return nil, err
+		}
+		c.file = *configFile
+	}
+
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	for _, f := range fields {
+		if value, ok := os.LookupEnv(f.env); ok {
+			if err := setValue(f.value, value); err != nil {
+				return nil, fmt.Errorf("env %s: %w", f.env, err)
+			}
+		}
+	}
+
+	var flagErr error
+	fset.Visit(func(fl *flag.Flag) {
+		for _, f := range fields {
+			if f.key == fl.Name && flagErr == nil {
+				if err := setValue(f.value, *flagValues[f.key]); err != nil {
+					flagErr = fmt.Errorf("flag -%s: %w", f.key, err)
+				}
+			}
+		}
+	})
+	if flagErr != nil {
+		return nil, flagErr
+	}
+
+	if err := c.resolveSecrets(fields); err != nil {
+		return nil, err
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Config) resolveSecrets(fields []field) error {
+	c.secrets = secrets.NewManager(
+		secrets.Env{},
+		secrets.Files{Dir: c.Secrets.Dir},
+		secrets.LocalStore{Path: c.Secrets.ProviderFile},
+	)
+
+	for _, f := range fields {
+		if f.secret {
+			c.secrets.Register(f.env)
+		}
+	}
+	if _, err := c.secrets.Refresh(context.Background()); err != nil {
+		return fmt.Errorf("secrets: %w", err)
+	}
+
+	for _, f := range fields {
+		if value, ok := c.secrets.Get(f.env); ok && f.secret {
+			f.value.SetString(value)
+		}
+	}
+	return nil
+}
+
+func (c *Config) loadFile(path string, fields []field) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return fmt.Errorf("config file %s: unsupported format", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	flat := make(map[string]any)
+	flatten("", raw, flat)
+
+	known := make(map[string]field, len(fields))
+	for _, f := range fields {
+		known[f.key] = f
+	}
+
+	for key, value := range flat {
+		f, ok := known[key]
+		if !ok {
+			// Maps such as logging.levels are flattened one level too deep.
+			parent := key[:max(strings.LastIndex(key, "."), 0)]
+			if pf, ok := known[parent]; ok && pf.value.Kind() == reflect.Map {
+				if pf.value.IsNil() {
+					pf.value.Set(reflect.MakeMap(pf.value.Type()))
+				}
+				pf.value.SetMapIndex(reflect.ValueOf(key[len(parent)+1:]), reflect.ValueOf(fmt.Sprint(value)))
+				continue
+			}
+			return fmt.Errorf("config file %s: unknown key %s", path, key)
+		}
+		if list, ok := value.([]any); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		}
+		if err := setValue(f.value, fmt.Sprint(value)); err != nil {
+			return fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+func flatten(prefix string, in map[string]any, out map[string]any) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if nested, ok := v.(map[string]any); ok {
+			flatten(key, nested, out)
+			continue
+		}
+		out[key] = v
+	}
+}
+
+// fields walks c and returns every leaf setting.
+func (c *Config) fields() []field {
+	var fields []field
+	walk(reflect.ValueOf(c).Elem(), "", "", &fields)
+	return fields
+}
+
+func walk(v reflect.Value, keyPrefix, envPrefix string, out *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := sf.Tag.Get("yaml")
+		if keyPrefix != "" {
+			key = keyPrefix + "." + key
+		}
+		env := sf.Tag.Get("env")
+		if envPrefix != "" {
+			env = envPrefix + "_" + env
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Duration(0)) {
+			walk(fv, key, env, out)
+			continue
+		}
+		*out = append(*out, field{
+			key:    key,
+			env:    env,
+			secret: sf.Tag.Get("secret") == "true",
+			reload: sf.Tag.Get("reload") == "true",
+			value:  fv,
+		})
+	}
+}
+
+func setValue(v reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Int:
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(i))
+	case v.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	case v.Kind() == reflect.Map && v.Type().Elem().Kind() == reflect.String:
+		// "k1=v1,k2=v2"
+		m := make(map[string]string)
+		for _, pair := range strings.Split(s, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			k, val, ok := strings.Cut(pair, "=")
+			if !ok || k == "" {
+				return fmt.Errorf("invalid map entry %q", pair)
+			}
+			m[strings.TrimSpace(k)] = strings.TrimSpace(val)
+		}
+		v.Set(reflect.ValueOf(m))
+	default:
+		return fmt.Errorf("unsupported setting type %s", v.Type())
+	}
+	return nil
+}
+
+// Effective lists every setting as "key = value", sorted by key, with
+// secrets masked. It is meant for startup logs and admin endpoints.
+func (c *Config) Effective() []string {
+	fields := c.fields()
+	lines := make([]string, 0, len(fields))
+	for _, f := range fields {
+		value := formatValue(f.value)
+		if f.secret && value != "" {
+			value = maskedValue
+		}
+		lines = append(lines, f.key+" = "+value)
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func formatValue(v reflect.Value) string {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	case v.Kind() == reflect.Map:
+		m := v.Interface().(map[string]string)
+		pairs := make([]string, 0, len(m))
+		for k, val := range m {
+			pairs = append(pairs, k+"="+val)
+		}
+		sort.Strings(pairs)
+		return strings.Join(pairs, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
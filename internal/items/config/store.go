@@ -0,0 +1,186 @@
+package config
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Snapshot is one immutable generation of the running configuration.
+type Snapshot struct {
+	Config   *Config
+	Version  int
+	Checksum string
+	LoadedAt time.Time
+}
+
+// Store holds the active configuration and swaps it atomically on reload.
+// Only settings tagged reload:"true" change at runtime; everything else
+// keeps its startup value until the process restarts.
+type Store struct {
+	args   []string
+	logger *slog.Logger
+
+	current atomic.Pointer[Snapshot]
+
+	mu          sync.Mutex
+	subscribers []func(*Config)
+	lastError   string
+}
+
+func NewStore(cfg *Config, args []string, logger *slog.Logger) *Store {
+	s := &Store{args: args, logger: logger}
+	s.current.Store(&Snapshot{
+		Config:   cfg,
+		Version:  1,
+		Checksum: checksum(cfg),
+		LoadedAt: time.Now(),
+	})
+	return s
+}
+
+// Config returns the active configuration. Callers must not modify it.
+func (s *Store) Config() *Config {
+	return s.current.Load().Config
+}
+
+func (s *Store) Snapshot() *Snapshot {
+	return s.current.Load()
+}
+
+// LastError returns why the most recent reload was rejected, or "" if it
+// succeeded.
+func (s *Store) LastError() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastError
+}
+
+// Subscribe registers fn to be called with the new configuration after
+// every successful reload.
+func (s *Store) Subscribe(fn func(*Config)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Reload loads and validates the configuration from every source again.
+// If anything is invalid the active configuration is kept.
+func (s *Store) Reload() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded, err := Load(s.args)
+	if err != nil {
+		s.lastError = err.Error()
+		s.logger.Error("Config reload rejected", "error", err.Error())
+		return err
+	}
+
+	old := s.current.Load()
+	next, ignored := mergeReloadable(old.Config, loaded)
+	if len(ignored) > 0 {
+		s.logger.Warn("Config changes require a restart", "keys", ignored)
+	}
+
+	sum := checksum(next)
+	s.lastError = ""
+	if sum == old.Checksum {
+		s.logger.Info("Config unchanged", "version", old.Version)
+		return nil
+	}
+
+	s.current.Store(&Snapshot{
+		Config:   next,
+		Version:  old.Version + 1,
+		Checksum: sum,
+		LoadedAt: time.Now(),
+	})
+	for _, fn := range s.subscribers {
+		fn(next)
+	}
+
+	s.logger.Info("Config reloaded", "version", old.Version+1)
+	return nil
+}
+
+// Watch reloads on SIGHUP and whenever the config file content changes,
+// until ctx is done.
+func (s *Store) Watch(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	file := s.Config().File()
+	lastHash := fileHash(file)
+
+	ticker := time.NewTicker(s.Config().Reload.WatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			s.logger.Info("SIGHUP received, reloading config")
+			s.Reload()
+		case <-ticker.C:
+			if file == "" {
+				continue
+			}
+			if hash := fileHash(file); hash != lastHash {
+				lastHash = hash
+				s.logger.Info("Config file changed, reloading", "file", file)
+				s.Reload()
+			}
+		}
+	}
+}
+
+// mergeReloadable copies the reloadable settings of loaded onto a copy of
+// current and returns the keys of non-reloadable settings that differ.
+func mergeReloadable(current, loaded *Config) (*Config, []string) {
+	next := *current
+	nextFields := next.fields()
+	loadedFields := loaded.fields()
+
+	var ignored []string
+	for i, f := range nextFields {
+		lf := loadedFields[i]
+		if f.reload {
+			f.value.Set(lf.value)
+			continue
+		}
+		if !reflect.DeepEqual(f.value.Interface(), lf.value.Interface()) {
+			ignored = append(ignored, f.key)
+		}
+	}
+	return &next, ignored
+}
+
+func checksum(c *Config) string {
+	sum := sha256.Sum256([]byte(strings.Join(c.Effective(), "\n")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func fileHash(path string) string {
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Sprintf("error: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
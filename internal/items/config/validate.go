@@ -0,0 +1,224 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Validate reports every invalid or missing setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.Server.Port), "server.port: %d is not a valid port", c.Server.Port)
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.Server.MaxHeaderBytes > 0, "server.max_header_bytes must be positive")
+	for _, proxy := range c.Server.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		check(err == nil || net.ParseIP(proxy) != nil, "server.trusted_proxies: %q is not an IP or CIDR", proxy)
+	}
+	if c.Server.TLS.Enabled {
+		check(c.Server.TLS.CertFile != "", "server.tls.cert_file is required when TLS is enabled")
+		check(c.Server.TLS.KeyFile != "", "server.tls.key_file is required when TLS is enabled")
+		check(c.Server.TLS.ReloadInterval > 0, "server.tls.reload_interval must be positive")
+	}
+	for name, e := range map[string]Endpoint{"upstream.auth": c.Upstream.Auth, "upstream.budgeting": c.Upstream.Budgeting} {
+		check(e.Host != "", "%s.host is required", name)
+		check(validPort(e.Port), "%s.port: %d is not a valid port", name, e.Port)
+		check((e.TLS.CertFile == "") == (e.TLS.KeyFile == ""), "%s.tls: cert_file and key_file must be set together", name)
+	}
+
+	check(c.Redis.Host != "", "redis.host is required")
+	check(validPort(c.Redis.Port), "redis.port: %d is not a valid port", c.Redis.Port)
+	check(c.Redis.DB >= 0, "redis.db must not be negative")
+
+	check(c.JWT.SecretKey != "", "jwt.secret_key is required")
+	check(c.JWT.AccessTokenTTL > 0, "jwt.access_token_ttl must be positive")
+
+	check(c.Kafka.Brokers != "", "kafka.brokers is required")
+	for _, broker := range strings.Split(c.Kafka.Brokers, ",") {
+		check(validHostPort(broker), "kafka.brokers: %q is not host:port", broker)
+	}
+	check(oneOf(c.Kafka.SASLMechanism, "none", "plain", "scram-sha-256", "scram-sha-512"),
+		"kafka.sasl_mechanism: %q must be none, plain, scram-sha-256 or scram-sha-512", c.Kafka.SASLMechanism)
+	if c.Kafka.SASLMechanism != "none" {
+		check(c.Kafka.SASLUsername != "", "kafka.sasl_username is required when SASL is enabled")
+		check(c.Kafka.SASLPassword != "", "kafka.sasl_password is required when SASL is enabled")
+	}
+
+	check(c.Lifecycle.StartupTimeout > 0, "lifecycle.startup_timeout must be positive")
+	check(c.Lifecycle.ShutdownTimeout > 0, "lifecycle.shutdown_timeout must be positive")
+	check(c.Lifecycle.DrainDelay >= 0, "lifecycle.drain_delay must not be negative")
+	check(c.Lifecycle.DrainDelay < c.Lifecycle.ShutdownTimeout, "lifecycle.drain_delay must be shorter than lifecycle.shutdown_timeout")
+
+	check(oneOf(c.Tracing.Exporter, "none", "otlp", "file"), "tracing.exporter: %q must be none, otlp or file", c.Tracing.Exporter)
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	if c.Tracing.Exporter == "otlp" {
+		check(validHostPort(c.Tracing.OTLPEndpoint), "tracing.otlp_endpoint: %q is not host:port", c.Tracing.OTLPEndpoint)
+	}
+
+	check(oneOf(c.Logging.Output, "stdout", "file", "both"), "logging.output: %q must be stdout, file or both", c.Logging.Output)
+	if c.Logging.Output != "stdout" {
+		check(c.Logging.File != "", "logging.file is required when logging.output is %s", c.Logging.Output)
+	}
+	check(validLevel(c.Logging.Level), "logging.level: %q is not a log level", c.Logging.Level)
+	for component, level := range c.Logging.Levels {
+		check(validLevel(level), "logging.levels.%s: %q is not a log level", component, level)
+	}
+	check(c.Logging.MaxSizeMB >= 0, "logging.max_size_mb must not be negative")
+	check(c.Logging.MaxBackups >= 0, "logging.max_backups must not be negative")
+
+	check(c.Cache.AccountTTL > 0, "cache.account_ttl must be positive")
+	if c.RateLimit.Enabled {
+		check(c.RateLimit.RequestsPerMinute > 0, "rate_limit.requests_per_minute must be positive")
+		check(c.RateLimit.Burst > 0, "rate_limit.burst must be positive")
+	}
+	corsPolicies := map[string]CORSOverride{
+		"cors":                   {AllowOrigins: c.CORS.AllowOrigins, AllowMethods: c.CORS.AllowMethods, AllowHeaders: c.CORS.AllowHeaders},
+		"cors.groups.auth":       c.CORS.Groups.Auth,
+		"cors.groups.admin":      c.CORS.Groups.Admin,
+		"cors.groups.superadmin": c.CORS.Groups.Superadmin,
+		"cors.groups.user":       c.CORS.Groups.User,
+	}
+	for name, p := range corsPolicies {
+		for _, origin := range p.AllowOrigins {
+			check(validOriginPattern(origin), "%s.allow_origins: %q must be *, scheme://host[:port] or scheme://*.domain", name, origin)
+			check(!(origin == "*" && c.CORS.AllowCredentials), "%s.allow_origins: * cannot be combined with cors.allow_credentials", name)
+		}
+		for _, method := range p.AllowMethods {
+			check(method != "*", "%s.allow_methods: list methods explicitly instead of *", name)
+		}
+		for _, header := range p.AllowHeaders {
+			check(header != "*", "%s.allow_headers: list headers explicitly instead of *", name)
+		}
+	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age must not be negative")
+	check(c.Reload.WatchInterval > 0, "reload.watch_interval must be positive")
+	check(c.Secrets.RefreshInterval > 0, "secrets.refresh_interval must be positive")
+
+	check(c.Audit.File != "", "audit.file is required")
+
+	verificationURL, err := url.Parse(c.Verification.URL)
+	check(err == nil && verificationURL.IsAbs(), "verification.url: %q must be an absolute URL", c.Verification.URL)
+	check(c.Verification.TokenTTL > 0, "verification.token_ttl must be positive")
+	check(c.Verification.ResendCooldown > 0, "verification.resend_cooldown must be positive")
+
+	check(c.Password.MinLength > 0, "password.min_length must be positive")
+	check(c.Password.MaxLength >= c.Password.MinLength, "password.max_length must not be less than password.min_length")
+	check(c.Password.MinCharClasses >= 0 && c.Password.MinCharClasses <= 4, "password.min_char_classes must be between 0 and 4")
+	resetURL, err := url.Parse(c.PasswordReset.URL)
+	check(err == nil && resetURL.IsAbs(), "password_reset.url: %q must be an absolute URL", c.PasswordReset.URL)
+	check(c.PasswordReset.TokenTTL > 0, "password_reset.token_ttl must be positive")
+	check(c.PasswordReset.Cooldown > 0, "password_reset.cooldown must be positive")
+
+	check(c.MFA.Issuer != "", "mfa.issuer is required")
+	check(!strings.Contains(c.MFA.Issuer, ":"), "mfa.issuer must not contain a colon")
+	check(c.MFA.EncryptionKey != "", "mfa.encryption_key is required")
+	check(c.MFA.EnrollmentTTL > 0, "mfa.enrollment_ttl must be positive")
+	check(c.MFA.ChallengeTTL > 0, "mfa.challenge_ttl must be positive")
+	check(c.MFA.MaxAttempts > 0, "mfa.max_attempts must be positive")
+	check(c.MFA.RecoveryCodes > 0, "mfa.recovery_codes must be positive")
+	check(c.MFA.LockoutAttempts > 0, "mfa.lockout_attempts must be positive")
+	check(c.MFA.Lockout > 0, "mfa.lockout must be positive")
+	check(c.StepUp.MaxAuthAge > 0, "step_up.max_auth_age must be positive")
+	check(c.StepUp.ElevationTTL > 0, "step_up.elevation_ttl must be positive")
+	check(c.OIDC.StateTTL > 0, "oidc.state_ttl must be positive")
+	check(c.PersonalTokens.DefaultTTL > 0, "personal_tokens.default_ttl must be positive")
+	check(c.PersonalTokens.MaxTTL >= c.PersonalTokens.DefaultTTL, "personal_tokens.max_ttl must not be less than default_ttl")
+	check(c.PersonalTokens.MaxPerUser > 0, "personal_tokens.max_per_user must be positive")
+	check(c.Impersonation.DefaultTTL > 0, "impersonation.default_ttl must be positive")
+	check(c.Impersonation.MaxTTL >= c.Impersonation.DefaultTTL, "impersonation.max_ttl must not be less than default_ttl")
+	check(c.Profile.Currency != "" && c.Profile.Locale != "" && c.Profile.Timezone != "" && c.Profile.FirstDayOfWeek != "", "profile: currency, locale, timezone and first_day_of_week are required")
+	if err := c.Profile.Preferences().Validate(); err != nil {
+		check(false, "profile: %v", err)
+	}
+	downloadURL, err := url.Parse(c.Export.DownloadURL)
+	check(err == nil && downloadURL.IsAbs(), "export.download_url: %q must be an absolute URL", c.Export.DownloadURL)
+	check(c.Export.LinkTTL > 0, "export.link_ttl must be positive")
+	check(c.Export.Retention >= c.Export.LinkTTL, "export.retention must not be less than link_ttl")
+	check(c.Export.Timeout > 0, "export.timeout must be positive")
+	check(c.Export.MaxSizeMB > 0, "export.max_size_mb must be positive")
+	check(c.Export.MaxPerWindow > 0, "export.max_per_window must be positive")
+	check(c.Export.Window > 0, "export.window must be positive")
+	check(c.Deletion.GracePeriod > 0, "deletion.grace_period must be positive")
+	check(c.Deletion.SweepInterval > 0, "deletion.sweep_interval must be positive")
+	check(c.Deletion.Timeout > 0, "deletion.timeout must be positive")
+	check(c.Deletion.RetryAfter >= c.Deletion.Timeout, "deletion.retry_after must not be less than timeout")
+	check(c.Roles.SyncInterval > 0, "roles.sync_interval must be positive")
+	if providers := c.OIDCProviders(); len(providers) > 0 {
+		u, err := url.Parse(c.OIDC.CallbackBaseURL)
+		check(err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "", "oidc.callback_base_url: %q is not an http(s) URL", c.OIDC.CallbackBaseURL)
+		for name, p := range providers {
+			check(p.ClientSecret != "", "oidc.%s.client_secret is required when the provider is enabled", name)
+			check(name == "github" || p.Issuer != "", "oidc.%s.issuer is required when the provider is enabled", name)
+		}
+	}
+
+	check(c.Security.HSTSMaxAge >= 0, "security.hsts_max_age must not be negative")
+	check(oneOf(c.Security.FrameOptions, "", "DENY", "SAMEORIGIN"), "security.frame_options: %q must be DENY, SAMEORIGIN or empty", c.Security.FrameOptions)
+	check(c.Security.MaxBodyBytes > 0, "security.max_body_bytes must be positive")
+	check(c.Security.MaxJSONDepth > 0, "security.max_json_depth must be positive")
+	check(len(c.Security.AllowedContentTypes) > 0, "security.allowed_content_types is required")
+	for _, contentType := range c.Security.AllowedContentTypes {
+		_, _, err := mime.ParseMediaType(contentType)
+		check(err == nil, "security.allowed_content_types: %q is not a media type", contentType)
+	}
+
+	return errors.Join(errs...)
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+func validHostPort(s string) bool {
+	host, port, err := net.SplitHostPort(strings.TrimSpace(s))
+	if err != nil || host == "" {
+		return false
+	}
+	p, err := strconv.Atoi(port)
+	return err == nil && validPort(p)
+}
+
+// validOriginPattern accepts "*", an origin such as https://app.example.com
+// or https://localhost:3000, and a wildcard subdomain such as
+// https://*.example.com.
+func validOriginPattern(s string) bool {
+	if s == "*" {
+		return true
+	}
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return false
+	}
+	if u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return false
+	}
+	host := strings.TrimPrefix(u.Hostname(), "*.")
+	return host != "" && !strings.Contains(host, "*")
+}
+
+func validLevel(s string) bool {
+	return oneOf(strings.ToLower(s), "debug", "info", "warn", "error")
+}
+
+func oneOf(s string, options ...string) bool {
+	for _, o := range options {
+		if s == o {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+// Package export writes the archive users download from /user/export: a
+// ZIP with every kind of record as JSON, as returned by the services, and
+// as CSV formatted for the user's locale, plus a manifest.json listing
+// the files with their record counts and SHA-256 checksums.
+package export
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"gateway-service/internal/pkg/profile"
+)
+
+const timeLayout = "15:04"
+
+// formulaPrefixes are the first characters that make spreadsheets read a
+// CSV cell as a formula.
+const formulaPrefixes = "=+-@\t\r"
+
+// Section is one kind of record, written as <Name>.json and <Name>.csv.
+type Section struct {
+	Name    string
+	Records any
+	Columns []string
+	Rows    [][]string
+}
+
+type Manifest struct {
+	UserID    string              `json:"user_id"`
+	CreatedAt time.Time           `json:"created_at"`
+	Profile   profile.Preferences `json:"profile"`
+	Files     []File              `json:"files"`
+}
+
+type File struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+	SHA256  string `json:"sha256"`
+}
+
+// Write writes the archive of sections to w.
+func Write(w io.Writer, manifest Manifest, sections []Section) error {
+	z := zip.NewWriter(w)
+	for _, s := range sections {
+		records := len(s.Rows)
+
+		data := []byte("[]")
+		if records > 0 {
+			var err error
+			if data, err = json.MarshalIndent(s.Records, "", "  "); err != nil {
+				return err
+			}
+		}
+		file, err := add(z, s.Name+".json", manifest.CreatedAt, func(w io.Writer) error {
+			_, err := w.Write(data)
+			return err
+		})
+		if err != nil {
+			return err
+		}
+		file.Records = records
+		manifest.Files = append(manifest.Files, file)
+
+		file, err = add(z, s.Name+".csv", manifest.CreatedAt, func(w io.Writer) error {
+			c := csv.NewWriter(w)
+			c.Write(s.Columns)
+			for _, row := range s.Rows {
+				cells := make([]string, len(row))
+				for i, value := range row {
+					cells[i] = cell(value)
+				}
+				c.Write(cells)
+			}
+			c.Flush()
+			return c.Error()
+		})
+		if err != nil {
+			return err
+		}
+		file.Records = records
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = add(z, "manifest.json", manifest.CreatedAt, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// add writes one file to z and returns its manifest entry.
+func add(z *zip.Writer, name string, modified time.Time, write func(io.Writer) error) (File, error) {
+	w, err := z.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return File{}, err
+	}
+	sum := sha256.New()
+	if err := write(io.MultiWriter(w, sum)); err != nil {
+		return File{}, err
+	}
+	return File{Name: name, SHA256: hex.EncodeToString(sum.Sum(nil))}, nil
+}
+
+// cell returns value for a CSV file, with an apostrophe in front if a
+// spreadsheet would otherwise run it as a formula. Signed numbers, such as
+// negative amounts, are kept as they are.
+func cell(value string) string {
+	if value == "" || !strings.ContainsRune(formulaPrefixes, rune(value[0])) {
+		return value
+	}
+	if (value[0] == '-' || value[0] == '+') && isNumber(value[1:]) {
+		return value
+	}
+	return "'" + value
+}
+
+// isNumber reports whether s is digits with the decimal and group
+// separators of any locale.
+func isNumber(s string) bool {
+	digits := false
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits = true
+		case strings.ContainsRune(".,' \u00a0\u202f", r):
+		default:
+			return false
+		}
+	}
+	return digits
+}
+
+// Date formats a date or timestamp from the services for prefs. Values it
+// does not recognise are kept as they are.
+func Date(prefs profile.Preferences, value string) string {
+	if t, err := time.ParseInLocation("2006-01-02", value, prefs.Location()); err == nil {
+		return prefs.FormatDate(t)
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return prefs.FormatDate(t) + " " + t.In(prefs.Location()).Format(timeLayout)
+		}
+	}
+	return value
+}
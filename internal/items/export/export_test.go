@@ -0,0 +1,29 @@
+package export
+
+import "testing"
+
+func TestCell(t *testing.T) {
+	tests := []struct {
+		value, want string
+	}{
+		{"", ""},
+		{"Groceries", "Groceries"},
+		{"=HYPERLINK(\"https://evil.example.com\")", "'=HYPERLINK(\"https://evil.example.com\")"},
+		{"+cmd|' /C calc'!A0", "'+cmd|' /C calc'!A0"},
+		{"-2+3+cmd|' /C calc'!A0", "'-2+3+cmd|' /C calc'!A0"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"\t=1+1", "'\t=1+1"},
+		{"\r=1+1", "'\r=1+1"},
+		{"-", "'-"},
+		{"-1,234.56", "-1,234.56"},
+		{"-1.234,56", "-1.234,56"},
+		{"-1 234,56", "-1 234,56"},
+		{"+12", "+12"},
+		{"12-31", "12-31"},
+	}
+	for _, tt := range tests {
+		if got := cell(tt.value); got != tt.want {
+			t.Errorf("cell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
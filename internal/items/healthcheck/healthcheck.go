@@ -0,0 +1,100 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+)
+
+const checkTimeout = 3 * time.Second
+
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Check probes a single dependency.
+type Check struct {
+	Name  string
+	Probe func(ctx context.Context) error
+}
+
+type Result struct {
+	Name      string `json:"name"`
+	Status    Status `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status       Status   `json:"status"`
+	Dependencies []Result `json:"dependencies"`
+}
+
+type Checker struct {
+	checks []Check
+}
+
+func New(checks ...Check) *Checker {
+	return &Checker{checks: checks}
+}
+
+// Run probes every dependency concurrently. The report is down if any
+// dependency is down.
+func (c *Checker) Run(ctx context.Context) Report {
+	results := make([]Result, len(c.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range c.checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := check.Probe(ctx)
+			results[i] = Result{
+				Name:      check.Name,
+				Status:    StatusUp,
+				LatencyMs: time.Since(start).Milliseconds(),
+			}
+			if err != nil {
+				results[i].Status = StatusDown
+				results[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, Dependencies: results}
+	for _, r := range results {
+		if r.Status == StatusDown {
+			report.Status = StatusDown
+			break
+		}
+	}
+	return report
+}
+
+// GRPC reports a gRPC connection as down unless it is Ready. An idle
+// connection is asked to connect so the next check can see it come up.
+func GRPC(conn *grpc.ClientConn) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		state := conn.GetState()
+		if state == connectivity.Idle {
+			conn.Connect()
+		}
+		if state != connectivity.Ready {
+			return fmt.Errorf("connection state %s", state)
+		}
+		return nil
+	}
+}
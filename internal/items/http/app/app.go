@@ -22,13 +22,16 @@
 package app
 
 import (
+	"fmt"
 	"log/slog"
+	"net/http"
 
 	_ "gateway-service/internal/items/http/app/docs"
+	"gateway-service/internal/items/metrics"
 	"gateway-service/internal/items/middleware"
 
 	casbin "github.com/casbin/casbin/v2"
-	"github.com/gin-contrib/cors"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	"gateway-service/internal/items/config"
 	"gateway-service/internal/items/http/handler"
@@ -39,30 +42,71 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, enforcer *casbin.Enforcer) error {
-	router := gin.Default()
+// New builds the router and wraps it in an http.Server so the caller can
+// drain it with Shutdown.
+func New(handler *handler.Handler, logger *slog.Logger, store *config.Store, enforcer *casbin.SyncedEnforcer) (*http.Server, error) {
+	corsPolicy := middleware.NewCORS(store.Config().CORS)
+	rateLimiter := middleware.NewRateLimiter(store.Config().RateLimit)
+	store.Subscribe(func(cfg *config.Config) {
+		corsPolicy.Update(cfg.CORS)
+		rateLimiter.Update(cfg.RateLimit)
+	})
 
-	// CORS konfiguratsiyasi
+	config := store.Config()
+
+	router := gin.New()
+	// Handlers that pass *gin.Context as a context.Context still carry the
+	// request's trace span.
+	router.ContextWithFallback = true
+	// Without trusted proxies ClientIP is the peer address, so clients
+	// cannot pick their own IP with X-Forwarded-For. The list is validated
+	// with the rest of the config, but gin trusts every proxy if it cannot
+	// parse it, so that must never be ignored.
+	if err := router.SetTrustedProxies(config.Server.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("server.trusted_proxies: %w", err)
+	}
+
+	router.Use(middleware.RequestID())
+	router.Use(middleware.AccessLog(logger, config))
+	router.Use(gin.Recovery())
+	router.Use(middleware.SecurityHeaders(config.Security))
+	router.Use(middleware.RequestLimits(config.Security))
 
-	corsConfig := cors.DefaultConfig()
-	corsConfig.AllowAllOrigins = true
-	corsConfig.AllowCredentials = true
-	corsConfig.AllowHeaders = []string{"*"}
-	corsConfig.AllowBrowserExtensions = true
-	corsConfig.AllowMethods = []string{"*"}
-	router.Use(cors.New(corsConfig))
+	// CORS konfiguratsiyasi
+	router.Use(corsPolicy.Middleware())
 
 	// Swagger dokumentatsiyasi uchun
 	url := ginSwagger.URL("/swagger/doc.json")
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url, ginSwagger.PersistAuthorization(true)))
 
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
+	router.Use(otelgin.Middleware(config.Tracing.ServiceName))
+	router.Use(metrics.GinMiddleware())
+
+	router.GET("/metrics", metrics.Handler())
+
+	router.GET("/healthz", handler.HealthRepo.LivenessHandler)
+	router.GET("/readyz", handler.HealthRepo.ReadinessHandler)
+
+	router.Use(rateLimiter.Middleware())
+	router.Use(middleware.PersonalAccessToken(handler.Redis))
+	router.Use(handler.Auditor.RecordImpersonation(), middleware.Impersonation(handler.Redis, config))
+
+	// Signed links from /user/export/:id work without a token.
+	router.GET("/export/download", handler.BudgetingRepo.ExportHandler.DownloadExportHandler)
+
+	tokenGuard := middleware.TokenGuard(handler.Redis, config)
+	trackActivity := middleware.TrackActivity(handler.Redis, config)
+	stepUp := middleware.StepUp(handler.Redis, config)
 
 	superadmin := router.Group("superadmin")
-	superadmin.Use(middleware.AuthzMiddleware("/superadmin", enforcer, config))
+	superadmin.Use(middleware.AuthzMiddleware("/superadmin", enforcer, config), tokenGuard, middleware.RequireMFA(handler.Redis, config), trackActivity)
 	{
-		superadmin.POST("/createadmin", handler.AuthRepo.SuperAdminCreateAdminHandler)
+		superadmin.POST("/createadmin", stepUp, handler.AuthRepo.SuperAdminCreateAdminHandler)
+		superadmin.GET("/admins", handler.AuthRepo.ListAdminsHandler)
+		superadmin.POST("/admins/:id/revoke-sessions", handler.AuthRepo.RevokeAdminSessionsHandler)
+		superadmin.PUT("/users/:id/role", handler.AuthRepo.SetRoleHandler)
+		superadmin.GET("/audit", handler.AuditRepo.QueryAuditHandler)
+		superadmin.GET("/audit/verify", handler.AuditRepo.VerifyAuditHandler)
 	}
 
 	auth := router.Group("auth")
@@ -82,30 +126,100 @@ func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, e
 			user.POST("/register", handler.AuthRepo.RegisterHandler)
 			user.POST("/login", handler.AuthRepo.LoginHandler)
 			user.POST("/logout", handler.AuthRepo.LogoutHandler)
+			user.POST("/verify", handler.AuthRepo.VerifyEmailHandler)
+			user.POST("/resend-verification", handler.AuthRepo.ResendVerificationHandler)
+			user.POST("/forgot-password", handler.AuthRepo.ForgotPasswordHandler)
+			user.POST("/reset-password", handler.AuthRepo.ResetPasswordHandler)
+		}
+		oidc := auth.Group("/oidc")
+		{
+			oidc.GET("", handler.AuthRepo.OIDCProvidersHandler)
+			oidc.GET("/:provider/login", handler.AuthRepo.OIDCLoginHandler)
+			oidc.GET("/:provider/callback", handler.AuthRepo.OIDCCallbackHandler)
+		}
+		auth.POST("/elevate", middleware.AuthzMiddleware("/user", enforcer, config), tokenGuard, handler.AuthRepo.ElevateHandler)
+
+		mfa := auth.Group("/mfa")
+		{
+			mfa.POST("/verify", handler.AuthRepo.VerifyMFAHandler)
+
+			authenticated := mfa.Group("")
+			authenticated.Use(middleware.AuthzMiddleware("/user", enforcer, config), tokenGuard)
+			authenticated.GET("", handler.AuthRepo.MFAStatusHandler)
+			authenticated.POST("/enroll", handler.AuthRepo.EnrollMFAHandler)
+			authenticated.POST("/activate", handler.AuthRepo.ActivateMFAHandler)
+			authenticated.POST("/recovery-codes", handler.AuthRepo.RegenerateRecoveryCodesHandler)
+			authenticated.POST("/disable", handler.AuthRepo.DisableMFAHandler)
 		}
 	}
 
 	admin := router.Group("admin")
-	admin.Use(middleware.AuthzMiddleware("/admin", enforcer, config))
+	admin.Use(middleware.AuthzMiddleware("/admin", enforcer, config), tokenGuard, trackActivity)
 	{
 		admin.PUT("/update/:id", handler.AuthRepo.UpdateUserHandler)
-		admin.DELETE("/delete/:id", handler.AuthRepo.DeleteUserHandler)
+		admin.DELETE("/delete/:id", stepUp, handler.AuthRepo.DeleteUserHandler)
+		users := admin.Group("/users")
+		{
+			users.GET("", handler.AuthRepo.ListUsersHandler)
+			users.GET("/lookup", handler.AuthRepo.LookupUserHandler)
+			users.GET("/:id", handler.AuthRepo.GetUserHandler)
+			users.POST("/:id/activate", handler.AuthRepo.ActivateUserHandler)
+			users.POST("/:id/deactivate", handler.AuthRepo.DeactivateUserHandler)
+			users.POST("/:id/logout", handler.AuthRepo.ForceLogoutHandler)
+			users.POST("/:id/reset-role", handler.AuthRepo.ResetRoleHandler)
+			users.GET("/:id/sessions", handler.AuthRepo.AdminListSessionsHandler)
+			users.DELETE("/:id/sessions/:session_id", handler.AuthRepo.AdminRevokeSessionHandler)
+			users.POST("/:id/impersonate", stepUp, handler.AuthRepo.ImpersonateHandler)
+		}
+		impersonations := admin.Group("/impersonations")
+		{
+			impersonations.DELETE("/:id", handler.AuthRepo.EndImpersonationHandler)
+		}
+		admin.GET("/status", handler.HealthRepo.StatusHandler)
+		admin.GET("/config", handler.ConfigRepo.GetConfigHandler)
+		admin.POST("/config/reload", handler.ConfigRepo.ReloadConfigHandler)
 
 	}
 
+	// Only budgeting data needs a verified email; an unverified user can
+	// still manage their password, profile, sessions and tokens.
+	verified := middleware.RequireVerified(handler.Redis, config)
+
 	user := router.Group("user")
-	user.Use(middleware.AuthzMiddleware("/user", enforcer, config))
+	user.Use(middleware.AuthzMiddleware("/user", enforcer, config), tokenGuard)
 	{
-		account := user.Group("account")
+		user.PUT("/password", handler.AuthRepo.ChangePasswordHandler)
+		user.DELETE("/me", stepUp, handler.AuthRepo.DeleteMeHandler)
+		user.GET("/profile", handler.ProfileRepo.GetProfileHandler)
+		user.PUT("/profile", handler.ProfileRepo.UpdateProfileHandler)
+		user.POST("/export", verified, handler.BudgetingRepo.ExportHandler.StartExportHandler)
+		user.GET("/export/:id", verified, handler.BudgetingRepo.ExportHandler.GetExportHandler)
+
+		sessions := user.Group("sessions")
+		{
+			sessions.GET("", handler.AuthRepo.ListSessionsHandler)
+			sessions.DELETE("/:id", handler.AuthRepo.RevokeSessionHandler)
+			sessions.POST("/revoke-others", handler.AuthRepo.RevokeOtherSessionsHandler)
+		}
+
+		tokens := user.Group("tokens")
+		{
+			tokens.GET("", handler.AuthRepo.ListPersonalTokensHandler)
+			tokens.POST("", handler.AuthRepo.CreatePersonalTokenHandler)
+			tokens.GET("/scopes", handler.AuthRepo.PersonalTokenScopesHandler)
+			tokens.DELETE("/:id", handler.AuthRepo.RevokePersonalTokenHandler)
+		}
+
+		account := user.Group("account", verified)
 		{
 			account.POST("/", handler.BudgetingRepo.AccountHandler.CreateAccountHandler)
 			account.GET("/", handler.BudgetingRepo.AccountHandler.GetAccountsHandler)
 			account.GET("/:id", handler.BudgetingRepo.AccountHandler.GetAccountByIdHandler)
 			account.PUT("/", handler.BudgetingRepo.AccountHandler.UpdateAccountHandler)
-			account.DELETE("/:id", handler.BudgetingRepo.AccountHandler.DeleteAccountHandler)
+			account.DELETE("/:id", stepUp, handler.BudgetingRepo.AccountHandler.DeleteAccountHandler)
 		}
 
-		budget := user.Group("budget")
+		budget := user.Group("budget", verified)
 		{
 			budget.POST("/", handler.BudgetingRepo.BudgetHandler.CreateBudgetHandler)
 			budget.GET("/", handler.BudgetingRepo.BudgetHandler.GetBudgetsHandler)
@@ -114,7 +228,7 @@ func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, e
 			budget.DELETE("/:id", handler.BudgetingRepo.BudgetHandler.DeleteBudgetHandler)
 		}
 
-		category := user.Group("category")
+		category := user.Group("category", verified)
 		{
 			category.POST("/", handler.BudgetingRepo.CategoryHandler.CreateCategoryHandler)
 			category.GET("/", handler.BudgetingRepo.CategoryHandler.GetCategoriesHandler)
@@ -123,7 +237,7 @@ func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, e
 			category.DELETE("/:id", handler.BudgetingRepo.CategoryHandler.DeleteCategoryHandler)
 		}
 
-		goal := user.Group("goal")
+		goal := user.Group("goal", verified)
 		{
 			goal.POST("/", handler.BudgetingRepo.GoalHandler.CreateGoalHandler)
 			goal.GET("/", handler.BudgetingRepo.GoalHandler.GetGoalsHandler)
@@ -132,7 +246,7 @@ func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, e
 			goal.DELETE("/:id", handler.BudgetingRepo.GoalHandler.DeleteGoalHandler)
 		}
 
-		transaction := user.Group("transaction")
+		transaction := user.Group("transaction", verified)
 		{
 			transaction.POST("/", handler.BudgetingRepo.TransactionHandler.CreateTransactionHandler)
 			transaction.GET("/", handler.BudgetingRepo.TransactionHandler.GetTransactionsHandler)
@@ -141,7 +255,7 @@ func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, e
 			transaction.DELETE("/:id", handler.BudgetingRepo.TransactionHandler.DeleteTransactionHandler)
 		}
 
-		report := user.Group("report")
+		report := user.Group("report", verified)
 		{
 			report.POST("/spending", handler.BudgetingRepo.ReportHandler.GetSpendingReportHandler)
 			report.POST("/incoming", handler.BudgetingRepo.ReportHandler.GetIncomeReportHandler)
@@ -149,12 +263,20 @@ func Run(handler *handler.Handler, logger *slog.Logger, config *config.Config, e
 			report.POST("/goal", handler.BudgetingRepo.ReportHandler.GetGoalProgressReportHandler)
 		}
 
-		notification := user.Group("notification")
+		notification := user.Group("notification", verified)
 		{
 			notification.GET("/", handler.BudgetingRepo.NotificationHandler.GetNotifications)
 			notification.PUT("/", handler.BudgetingRepo.NotificationHandler.MarkNotificationAsRead)
 		}
 	}
 
-	return router.Run("gateway" + config.Server.ServerPort)
+	return &http.Server{
+		Addr:              config.Server.Address(),
+		Handler:           router,
+		ReadHeaderTimeout: config.Server.ReadHeaderTimeout,
+		ReadTimeout:       config.Server.ReadTimeout,
+		WriteTimeout:      config.Server.WriteTimeout,
+		IdleTimeout:       config.Server.IdleTimeout,
+		MaxHeaderBytes:    config.Server.MaxHeaderBytes,
+	}, nil
 }
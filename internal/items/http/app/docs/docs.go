@@ -24,37 +24,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/admin/delete/{id}": {
-            "delete": {
-                "description": "Soft delete a user by admin",
-                "consumes": [
-                    "application/json"
+        "/admin/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Returns the active config version, when it was loaded and the effective settings with secrets masked",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Admin Auth"
+                    "Admin Config"
                 ],
-                "summary": "Delete user",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                "summary": "Active configuration",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
                     },
-                    {
-                        "description": "Delete User Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.DeleteUserRequest"
+                            "$ref": "#/definitions/gin.H"
                         }
                     }
+                }
+            }
+        },
+        "/admin/config/reload": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Reloads the configuration from every source. An invalid configuration is rejected and the active one is kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin Config"
+                ],
+                "summary": "Reload configuration",
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -73,19 +88,13 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
                     }
                 }
             }
         },
-        "/admin/update/{id}": {
-            "put": {
-                "description": "Update a user's information by admin",
+        "/admin/delete/{id}": {
+            "delete": {
+                "description": "Soft delete a user by admin. Requires a recent login or an X-Elevation-Token. Only superadmins can delete admins and superadmins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -95,7 +104,7 @@ const docTemplate = `{
                 "tags": [
                     "Admin Auth"
                 ],
-                "summary": "Update user",
+                "summary": "Delete user",
                 "parameters": [
                     {
                         "type": "string",
@@ -105,13 +114,18 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Update User Request",
+                        "description": "Optional; user_id must match the path if given",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.UpdateUserRequest"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.DeleteUserRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token from /auth/elevate",
+                        "name": "X-Elevation-Token",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -127,6 +141,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/gin.H"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
@@ -142,39 +162,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/admin/login": {
-            "post": {
-                "description": "Log in an admin user with email and password",
-                "consumes": [
-                    "application/json"
+        "/admin/impersonations/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "End an impersonation before it expires; its token is rejected from then on. Only the admin who started it or a superadmin can end it.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Admin Auth"
+                    "Admin Users"
                 ],
-                "summary": "Admin login",
+                "summary": "End an impersonation",
                 "parameters": [
                     {
-                        "description": "Login Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Impersonation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -188,45 +214,30 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/admin/logout": {
-            "post": {
-                "description": "Log out an admin user by their ID",
-                "consumes": [
-                    "application/json"
+        "/admin/status": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Detailed dependency status with errors, lifecycle state, uptime and Kafka outbox size",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Admin Auth"
-                ],
-                "summary": "Admin logout",
-                "parameters": [
-                    {
-                        "description": "Logout Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutRequest"
-                        }
-                    }
+                    "Admin Status"
                 ],
+                "summary": "Detailed gateway status",
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -234,9 +245,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/superadmin/login": {
-            "post": {
-                "description": "Login as a super admin",
+        "/admin/update/{id}": {
+            "put": {
+                "description": "Change a user's email address. The role, active state and password cannot be set here; use the role, activate/deactivate and password reset endpoints. Only superadmins can update admins and superadmins.",
                 "consumes": [
                     "application/json"
                 ],
@@ -244,17 +255,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Super Admin"
+                    "Admin Auth"
                 ],
-                "summary": "Super Admin Login",
+                "summary": "Update user",
                 "parameters": [
                     {
-                        "description": "Login Request",
-                        "name": "loginRequest",
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New email; user_id may be omitted and must match the path if given",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginRequest"
+                            "$ref": "#/definitions/gateway-service_internal_models.UpdateUserRequest"
                         }
                     }
                 ],
@@ -262,7 +280,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
@@ -271,6 +289,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/gin.H"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -280,28 +310,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/superadmin/logout": {
-            "post": {
-                "description": "Logout from a super admin session",
-                "consumes": [
-                    "application/json"
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Paginated list of users. Returns 501 until the auth service provides a ListUsers RPC.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Super Admin"
+                    "Admin Users"
                 ],
-                "summary": "Super Admin Logout",
+                "summary": "List users",
                 "parameters": [
                     {
-                        "description": "Logout Request",
-                        "name": "logoutRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutRequest"
-                        }
+                        "type": "integer",
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page size, at most 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -317,8 +352,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -326,35 +367,35 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/user/login": {
-            "post": {
-                "description": "Log in a user with email and password",
-                "consumes": [
-                    "application/json"
+        "/admin/users/lookup": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Look up a user's ID by email address",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Auth"
+                    "Admin Users"
                 ],
-                "summary": "User login",
+                "summary": "Find a user by email",
                 "parameters": [
                     {
-                        "description": "Login Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginRequest"
-                        }
+                        "type": "string",
+                        "description": "Email address",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.RegisterResponse"
                         }
                     },
                     "400": {
@@ -363,6 +404,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/gin.H"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -372,45 +425,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/user/logout": {
-            "post": {
-                "description": "Log out a user by their ID",
-                "consumes": [
-                    "application/json"
+        "/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Get a user's details by ID. Returns 501 until the auth service provides a GetUserById RPC.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Auth"
+                    "Admin Users"
                 ],
-                "summary": "User logout",
+                "summary": "Get a user by ID",
                 "parameters": [
                     {
-                        "description": "Logout Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -418,39 +471,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/user/register": {
+        "/admin/users/{id}/activate": {
             "post": {
-                "description": "Register a new user with an email and password",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Allow a deactivated user to use the API again. Only superadmins can act on admins and superadmins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Auth"
+                    "Admin Users"
                 ],
-                "summary": "Register a new user",
+                "summary": "Activate a user",
                 "parameters": [
                     {
-                        "description": "Register Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.RegisterRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.RegisterResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Bad Request",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -464,33 +517,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/superadmin/createadmin": {
+        "/admin/users/{id}/deactivate": {
             "post": {
-                "description": "Create a new admin user by a super admin",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Block a user from the API. Their existing tokens are rejected immediately. Only superadmins can act on admins and superadmins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Super Admin"
+                    "Admin Users"
                 ],
-                "summary": "Create Admin",
+                "summary": "Deactivate a user",
                 "parameters": [
                     {
-                        "description": "Create Admin Request",
-                        "name": "createAdminRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_auth.CreateAdminRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -501,6 +554,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/gin.H"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -510,140 +569,191 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/account": {
-            "get": {
+        "/admin/users/{id}/impersonate": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all accounts for the authenticated user",
+                "description": "Get a short-lived token that acts as the user on the budgeting routes under /user. The token is read-only unless a superadmin sets allow_write, responses carry X-Impersonated-User and X-Impersonator, and every request made with it is audited with both identities. Requires a recent login or an X-Elevation-Token.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Accounts"
+                    "Admin Users"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Reason, duration and write access",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ImpersonateRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token from /auth/elevate",
+                        "name": "X-Elevation-Token",
+                        "in": "header"
+                    }
                 ],
-                "summary": "Get accounts",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_account.AccountsResponse"
+                            "$ref": "#/definitions/gateway-service_internal_models.ImpersonationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "401": {
-                        "description": "User not authenticated",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to get accounts",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/users/{id}/logout": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update account details by account ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "End every session of a user. Tokens issued before now are rejected. Only superadmins can act on admins and superadmins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Accounts"
+                    "Admin Users"
                 ],
-                "summary": "Update account",
+                "summary": "Force logout",
                 "parameters": [
                     {
-                        "description": "Updated account details",
-                        "name": "UpdateAccountRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_account.UpdateAccountRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to update account",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/reset-role": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new account for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Reset a user's role to \"user\" and end their sessions so the new role applies at once",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Accounts"
+                    "Admin Users"
                 ],
-                "summary": "Create an account",
+                "summary": "Reset a user's role",
                 "parameters": [
                     {
-                        "description": "Account details",
-                        "name": "CreateAccountRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.CreateAccountRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to create account",
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "501": {
+                        "description": "Not Implemented",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -651,25 +761,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/account/{id}": {
+        "/admin/users/{id}/sessions": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get account details by account ID",
+                "description": "List the devices a user is logged in on",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Accounts"
+                    "Admin Users"
                 ],
-                "summary": "Get account by ID",
+                "summary": "List a user's sessions",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Account ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -679,61 +789,79 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gateway-service_internal_models.Session"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Account ID is required",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve account",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/sessions/{session_id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete account by account ID",
+                "description": "Log a user out of one session. To end all of them use /admin/users/{id}/logout. Only superadmins can act on admins and superadmins.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Accounts"
+                    "Admin Users"
                 ],
-                "summary": "Delete account",
+                "summary": "Revoke a user's session",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Account ID",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "session_id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Account deleted successfully",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Account ID is required",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete account",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -741,49 +869,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/budget": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/admin/login": {
+            "post": {
+                "description": "Log in an admin user with email and password. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get all budgets for the authenticated user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Budgets"
+                    "Admin Auth"
+                ],
+                "summary": "Admin login",
+                "parameters": [
+                    {
+                        "description": "Login Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginRequest"
+                        }
+                    }
                 ],
-                "summary": "Get budgets",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_budget.BudgetsResponse"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
-                        "schema": {
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to get budgets",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update budget details by budget ID",
+            }
+        },
+        "/auth/admin/logout": {
+            "post": {
+                "description": "Log out an admin user by their ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -791,17 +925,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Budgets"
+                    "Admin Auth"
                 ],
-                "summary": "Update budget",
+                "summary": "Admin logout",
                 "parameters": [
                     {
-                        "description": "Updated budget details",
-                        "name": "UpdateBudgetRequest",
+                        "description": "Logout Request",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_budget.UpdateBudgetRequest"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutRequest"
                         }
                     }
                 ],
@@ -809,30 +943,32 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to update budget",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/elevate": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new budget for the authenticated user",
+                "description": "Confirm the logged in user with a TOTP code, or with email and password, and get a short-lived token to send as X-Elevation-Token to routes that answer 401 with a step_up challenge",
                 "consumes": [
                     "application/json"
                 ],
@@ -840,41 +976,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Budgets"
+                    "User Auth"
                 ],
-                "summary": "Create a budget",
+                "summary": "Re-authenticate for sensitive operations",
                 "parameters": [
                     {
-                        "description": "Budget details",
-                        "name": "CreateBudgetRequest",
+                        "description": "TOTP code, or email and password",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.CreateBudgetRequest"
+                            "$ref": "#/definitions/gateway-service_internal_models.ElevateRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_budget.BudgetResponse"
+                            "$ref": "#/definitions/gateway-service_internal_models.ElevateResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "401": {
-                        "description": "User not authenticated",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to create budget",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -882,89 +1024,99 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/budget/{id}": {
+        "/auth/mfa": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get budget details by budget ID",
+                "description": "Show whether two-factor authentication is enabled or required for the logged in user",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Budgets"
-                ],
-                "summary": "Get budget by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "MFA"
                 ],
+                "summary": "Two-factor status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_budget.BudgetResponse"
+                            "$ref": "#/definitions/gateway-service_internal_models.MFAStatusResponse"
                         }
                     },
-                    "400": {
-                        "description": "Budget ID is required",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve budget",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/auth/mfa/activate": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete budget by budget ID",
+                "description": "Confirm enrollment with a code from the authenticator app. Returns one-time recovery codes, shown only once. Every session is ended so the next login uses two factors.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Budgets"
+                    "MFA"
                 ],
-                "summary": "Delete budget",
+                "summary": "Activate two-factor authentication",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "TOTP code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.MFACodeRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Budget deleted successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_internal_models.MFARecoveryCodesResponse"
                         }
                     },
                     "400": {
-                        "description": "Budget ID is required",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete budget",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -972,98 +1124,126 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/category": {
-            "get": {
+        "/auth/mfa/disable": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all categories for the authenticated user",
+                "description": "Turn off two-factor authentication with a TOTP or recovery code. Superadmins cannot disable it.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Categories"
+                    "MFA"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "TOTP or recovery code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.MFACodeRequest"
+                        }
+                    }
                 ],
-                "summary": "Get categories",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_category.CategoriesResponse"
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "401": {
-                        "description": "User not authenticated",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to get categories",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/auth/mfa/enroll": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update category details by category ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Create a TOTP secret. Add it to an authenticator app, usually by rendering otpauth_uri as a QR code, then confirm it with /auth/mfa/activate.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Categories"
-                ],
-                "summary": "Update category",
-                "parameters": [
-                    {
-                        "description": "Updated category details",
-                        "name": "UpdateCategoryRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_category.UpdateCategoryRequest"
-                        }
-                    }
+                    "MFA"
                 ],
+                "summary": "Start two-factor enrollment",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                            "$ref": "#/definitions/gateway-service_internal_models.MFAEnrollResponse"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to update category",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/mfa/recovery-codes": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new category for the authenticated user",
+                "description": "Invalidate the remaining recovery codes and return new ones",
                 "consumes": [
                     "application/json"
                 ],
@@ -1071,41 +1251,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Categories"
+                    "MFA"
                 ],
-                "summary": "Create a category",
+                "summary": "Replace recovery codes",
                 "parameters": [
                     {
-                        "description": "Category details",
-                        "name": "CreateCategoryRequest",
+                        "description": "TOTP code",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.CreateCategoryRequest"
+                            "$ref": "#/definitions/gateway-service_internal_models.MFACodeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                            "$ref": "#/definitions/gateway-service_internal_models.MFARecoveryCodesResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "401": {
-                        "description": "User not authenticated",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to create category",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1113,89 +1305,144 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/category/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Exchange the challenge token from the login response and a TOTP or recovery code for the access and refresh tokens",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get category details by category ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Categories"
+                    "MFA"
                 ],
-                "summary": "Get category by ID",
+                "summary": "Complete a two-factor login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Category ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Challenge token and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.MFAVerifyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Category ID is required",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "500": {
-                        "description": "Failed to retrieve category",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
-                }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+                }
+            }
+        },
+        "/auth/oidc": {
+            "get": {
+                "description": "List the external identity providers users can sign in with",
+                "produces": [
+                    "application/json"
                 ],
-                "description": "Delete category by category ID",
+                "tags": [
+                    "OIDC"
+                ],
+                "summary": "List identity providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.OIDCProvidersResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/{provider}/callback": {
+            "get": {
+                "description": "Exchange the code the provider redirected back with for gateway tokens. The identity is linked to the user with the same verified email, or a new user is registered. Admins, superadmins and existing users who have never signed in with their password are refused. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Categories"
+                    "OIDC"
                 ],
-                "summary": "Delete category",
+                "summary": "Complete a sign-in with an identity provider",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Category ID",
-                        "name": "id",
+                        "description": "Provider name",
+                        "name": "provider",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "State from the login redirect",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Category deleted successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Category ID is required",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete category",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1203,49 +1450,50 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/goal": {
+        "/auth/oidc/{provider}/login": {
             "get": {
-                "security": [
+                "description": "Redirect the browser to the provider's login page. The provider sends it back to the callback endpoint.",
+                "tags": [
+                    "OIDC"
+                ],
+                "summary": "Sign in with an identity provider",
+                "parameters": [
                     {
-                        "BearerAuth": []
+                        "type": "string",
+                        "description": "Provider name, e.g. google or github",
+                        "name": "provider",
+                        "in": "path",
+                        "required": true
                     }
                 ],
-                "description": "Get all financial goals for the authenticated user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "User Goals"
-                ],
-                "summary": "Get goals",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "302": {
+                        "description": "Found"
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_goal.GoalsResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "500": {
-                        "description": "Failed to get goals",
+                    "502": {
+                        "description": "Bad Gateway",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Update financial goal details by goal ID",
+            }
+        },
+        "/auth/superadmin/login": {
+            "post": {
+                "description": "Login as a super admin. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1253,17 +1501,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Goals"
+                    "Super Admin"
                 ],
-                "summary": "Update goal",
+                "summary": "Super Admin Login",
                 "parameters": [
                     {
-                        "description": "Updated goal details",
-                        "name": "UpdateGoalRequest",
+                        "description": "Login Request",
+                        "name": "loginRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_goal.UpdateGoalRequest"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginRequest"
                         }
                     }
                 ],
@@ -1271,30 +1519,27 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to update goal",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/auth/superadmin/logout": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Create a new financial goal for the authenticated user",
+                "description": "Logout from a super admin session",
                 "consumes": [
                     "application/json"
                 ],
@@ -1302,41 +1547,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Goals"
+                    "Super Admin"
                 ],
-                "summary": "Create a goal",
+                "summary": "Super Admin Logout",
                 "parameters": [
                     {
-                        "description": "Goal details",
-                        "name": "CreateGoalRequest",
+                        "description": "Logout Request",
+                        "name": "logoutRequest",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.CreateGoalRequest"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_goal.GoalResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request body",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to create goal",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1344,89 +1583,91 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/goal/{id}": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+        "/auth/user/forgot-password": {
+            "post": {
+                "description": "Email a single-use password reset link. The response is the same whether or not the account exists.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Get financial goal details by goal ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Goals"
+                    "User Auth"
                 ],
-                "summary": "Get goal by ID",
+                "summary": "Request a password reset",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Goal ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ForgotPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_goal.GoalResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
-                        "description": "Goal ID is required",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to get goal",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "delete": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+            }
+        },
+        "/auth/user/login": {
+            "post": {
+                "description": "Log in a user with email and password. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Delete financial goal by goal ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Goals"
+                    "User Auth"
                 ],
-                "summary": "Delete goal",
+                "summary": "User login",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Goal ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Login Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Goal deleted successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LoginResponse"
                         }
                     },
                     "400": {
-                        "description": "Goal ID is required",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete goal",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1434,14 +1675,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/notification/": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve all notifications for the authenticated user",
+        "/auth/user/logout": {
+            "post": {
+                "description": "Log out a user by their ID",
                 "consumes": [
                     "application/json"
                 ],
@@ -1449,24 +1685,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Notifications"
+                    "User Auth"
+                ],
+                "summary": "User logout",
+                "parameters": [
+                    {
+                        "description": "Logout Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutRequest"
+                        }
+                    }
                 ],
-                "summary": "Get user notifications",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_notification.NotificationsResponse"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.LogoutResponse"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve notifications",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1474,14 +1721,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/notification/{id}": {
-            "put": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Mark the specified notification as read",
+        "/auth/user/register": {
+            "post": {
+                "description": "Register a new user with an email and password. A verification email is sent, and the budgeting API is unavailable until the address is confirmed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1489,33 +1731,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "User Notifications"
+                    "User Auth"
                 ],
-                "summary": "Mark a notification as read",
+                "summary": "Register a new user",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Notification ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Register Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_auth.RegisterRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_genproto_auth.RegisterResponse"
                         }
                     },
                     "400": {
-                        "description": "Notification ID is required",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to mark notification as read",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1523,51 +1767,45 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/report/bugdet": {
+        "/auth/user/resend-verification": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+                "description": "Send a new verification email, invalidating the previous link. The response is the same whether or not the account exists.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieve a budget performance report for a specific budget by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Reports"
+                    "User Auth"
                 ],
-                "summary": "Get budget performance report",
+                "summary": "Resend verification email",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Budget ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Email address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ResendVerificationRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_report.BudgetPerformanceReportResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid request payload",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve budget performance report",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1575,51 +1813,51 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/report/goal": {
+        "/auth/user/reset-password": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+                "description": "Set a new password with the token from the reset email. Every existing session is ended.",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieve a goal progress report for a specific goal by its ID",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Reports"
+                    "User Auth"
                 ],
-                "summary": "Get goal progress report",
+                "summary": "Reset password",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Goal ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Reset token and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ResetPasswordRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_report.GoalProgressReportResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve goal progress report",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1627,29 +1865,27 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/report/incoming": {
+        "/auth/user/verify": {
             "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
+                "description": "Confirm a user's email address with the token from the verification email",
+                "consumes": [
+                    "application/json"
                 ],
-                "description": "Retrieve an income report for a user between the specified start and end dates",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Reports"
+                    "User Auth"
                 ],
-                "summary": "Get income report",
+                "summary": "Verify email address",
                 "parameters": [
                     {
-                        "description": "Get Income Report Request",
+                        "description": "Verification token",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.GetIncomeReportRequest"
+                            "$ref": "#/definitions/gateway-service_internal_models.VerifyEmailRequest"
                         }
                     }
                 ],
@@ -1657,17 +1893,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_report.IncomeReportResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve income report",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1675,47 +1911,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/report/spending": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Retrieve a spending report for a user between the specified start and end dates",
+        "/export/download": {
+            "get": {
+                "description": "Download the archive of an export through the signed link from /user/export/{id}",
                 "produces": [
-                    "application/json"
+                    "application/zip"
                 ],
                 "tags": [
-                    "User Reports"
+                    "User Export"
                 ],
-                "summary": "Get spending report",
+                "summary": "Download a data export",
                 "parameters": [
                     {
-                        "description": "Get Spending Report Request",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.GetSpendingReportRequest"
-                        }
+                        "type": "string",
+                        "description": "Signed download token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_report.SpendingReportResponse"
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to retrieve spending report",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1723,140 +1952,137 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/transaction": {
+        "/healthz": {
             "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "Get all financial transactions for the authenticated user",
+                "description": "Reports that the gateway process is alive",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Transactions"
+                    "Health"
                 ],
-                "summary": "Get transactions",
+                "summary": "Liveness probe",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionsResponse"
+                            "$ref": "#/definitions/gin.H"
                         }
-                    },
-                    "401": {
-                        "description": "User not authenticated",
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Reports per-dependency status and latency. Fails while the gateway is starting up or draining.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_internal_items_healthcheck.Report"
                         }
                     },
-                    "500": {
-                        "description": "Failed to get transactions",
+                    "503": {
+                        "description": "Service Unavailable",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/gateway-service_internal_items_healthcheck.Report"
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/superadmin/admins": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update financial transaction details by transaction ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List admins and superadmins with the time of their last request",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Transactions"
-                ],
-                "summary": "Update transaction",
-                "parameters": [
-                    {
-                        "description": "Updated transaction details",
-                        "name": "UpdateTransactionRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_transaction.UpdateTransactionRequest"
-                        }
-                    }
+                    "Super Admin"
                 ],
+                "summary": "List admins",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gateway-service_internal_models.StaffMember"
+                            }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to update transaction",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/superadmin/admins/{id}/revoke-sessions": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new financial transaction for the authenticated user",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "End every session of an admin or superadmin. Tokens issued before now are rejected.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Transactions"
+                    "Super Admin"
                 ],
-                "summary": "Create a transaction",
+                "summary": "Revoke an admin's sessions",
                 "parameters": [
                     {
-                        "description": "Transaction details",
-                        "name": "CreateTransactionRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_internal_models.CreateTransactionRequest"
-                        }
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "401": {
-                        "description": "User not authenticated",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to create transaction",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -1864,108 +2090,2648 @@ const docTemplate = `{
                 }
             }
         },
-        "/user/transaction/{id}": {
+        "/superadmin/audit": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get financial transaction details by transaction ID",
+                "description": "Returns audit records, newest first, filtered by the given parameters",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Transactions"
+                    "Super Admin Audit"
                 ],
-                "summary": "Get transaction by ID",
+                "summary": "Query the audit log",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Transaction ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionResponse"
-                        }
+                        "description": "Actor user ID",
+                        "name": "actor_id",
+                        "in": "query"
                     },
-                    "400": {
-                        "description": "Invalid transaction ID",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
+                    {
+                        "type": "string",
+                        "description": "ID of the admin impersonating the actor",
+                        "name": "acted_by",
+                        "in": "query"
                     },
-                    "500": {
-                        "description": "Failed to get transaction",
+                    {
+                        "type": "string",
+                        "description": "Action, e.g. account.update",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource type, e.g. account",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resource ID",
+                        "name": "resource_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "success or failure",
+                        "name": "outcome",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 start time (inclusive)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "RFC 3339 end time (exclusive)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Maximum records, default 100, max 1000",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/superadmin/audit/verify": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete financial transaction by transaction ID",
+                "description": "Recomputes the hash chain of the audit file and reports the first tampered record, if any",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "User Transactions"
+                    "Super Admin Audit"
                 ],
-                "summary": "Delete transaction",
+                "summary": "Verify the audit log",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/superadmin/createadmin": {
+            "post": {
+                "description": "Create a new admin user by a super admin. Requires a recent login or an X-Elevation-Token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Super Admin"
+                ],
+                "summary": "Create Admin",
                 "parameters": [
+                    {
+                        "description": "Create Admin Request",
+                        "name": "createAdminRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_auth.CreateAdminRequest"
+                        }
+                    },
                     {
                         "type": "string",
-                        "description": "Transaction ID",
+                        "description": "Token from /auth/elevate",
+                        "name": "X-Elevation-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/superadmin/users/{id}/role": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Promote a user to admin or demote an admin or superadmin to user. The last superadmin cannot be demoted, and a user's role can only be changed once they have logged in. The user's sessions are ended so the new role applies at once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Super Admin"
+                ],
+                "summary": "Change a user's role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "New role: user or admin",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.SetRoleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "message: Transaction deleted successfully",
+                        "description": "OK",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "400": {
-                        "description": "Invalid transaction ID",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to delete transaction",
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/account": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all accounts for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Accounts"
+                ],
+                "summary": "Get accounts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_account.AccountsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get accounts",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update account details by account ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Accounts"
+                ],
+                "summary": "Update account",
+                "parameters": [
+                    {
+                        "description": "Updated account details",
+                        "name": "UpdateAccountRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_account.UpdateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update account",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new account for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Accounts"
+                ],
+                "summary": "Create an account",
+                "parameters": [
+                    {
+                        "description": "Account details",
+                        "name": "CreateAccountRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreateAccountRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create account",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/account/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get account details by account ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Accounts"
+                ],
+                "summary": "Get account by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Account ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve account",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete account by account ID. Requires a recent login or an X-Elevation-Token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Accounts"
+                ],
+                "summary": "Delete account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Account ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token from /auth/elevate",
+                        "name": "X-Elevation-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Account deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Account ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Recent authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete account",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/budget": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all budgets for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Budgets"
+                ],
+                "summary": "Get budgets",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_budget.BudgetsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get budgets",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update budget details by budget ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Budgets"
+                ],
+                "summary": "Update budget",
+                "parameters": [
+                    {
+                        "description": "Updated budget details",
+                        "name": "UpdateBudgetRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_budget.UpdateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update budget",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new budget for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Budgets"
+                ],
+                "summary": "Create a budget",
+                "parameters": [
+                    {
+                        "description": "Budget details",
+                        "name": "CreateBudgetRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreateBudgetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_budget.BudgetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create budget",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/budget/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get budget details by budget ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Budgets"
+                ],
+                "summary": "Get budget by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_budget.BudgetResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Budget ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve budget",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete budget by budget ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Budgets"
+                ],
+                "summary": "Delete budget",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Budget deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Budget ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete budget",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/category": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all categories for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Categories"
+                ],
+                "summary": "Get categories",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_category.CategoriesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get categories",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update category details by category ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Categories"
+                ],
+                "summary": "Update category",
+                "parameters": [
+                    {
+                        "description": "Updated category details",
+                        "name": "UpdateCategoryRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_category.UpdateCategoryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update category",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new category for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Categories"
+                ],
+                "summary": "Create a category",
+                "parameters": [
+                    {
+                        "description": "Category details",
+                        "name": "CreateCategoryRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreateCategoryRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create category",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/category/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get category details by category ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Categories"
+                ],
+                "summary": "Get category by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Category ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve category",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete category by category ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Categories"
+                ],
+                "summary": "Delete category",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Category ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Category deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Category ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete category",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/export": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start collecting all of the caller's accounts, budgets, categories, goals, transactions and notifications into a ZIP archive with JSON and CSV files and a manifest. Poll the job at the Location header until it is done.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Export"
+                ],
+                "summary": "Export personal data",
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ExportJob"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many exports",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/export/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the status of an export. Once it is done the response has a signed download link that expires after a few minutes; fetch the job again for a new one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Export"
+                ],
+                "summary": "Get a data export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ExportJob"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/goal": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all financial goals for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Goals"
+                ],
+                "summary": "Get goals",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_goal.GoalsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get goals",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update financial goal details by goal ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Goals"
+                ],
+                "summary": "Update goal",
+                "parameters": [
+                    {
+                        "description": "Updated goal details",
+                        "name": "UpdateGoalRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_goal.UpdateGoalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update goal",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new financial goal for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Goals"
+                ],
+                "summary": "Create a goal",
+                "parameters": [
+                    {
+                        "description": "Goal details",
+                        "name": "CreateGoalRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreateGoalRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_goal.GoalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create goal",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/goal/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get financial goal details by goal ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Goals"
+                ],
+                "summary": "Get goal by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_goal.GoalResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Goal ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get goal",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete financial goal by goal ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Goals"
+                ],
+                "summary": "Delete goal",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Goal deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Goal ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete goal",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/me": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Schedule the caller's account and budgeting data for deletion after the grace period. All sessions and personal access tokens are revoked at once; logging in again before the deletion is due cancels it. Requires a recent login or an X-Elevation-Token.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Auth"
+                ],
+                "summary": "Delete own account",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from /auth/elevate",
+                        "name": "X-Elevation-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.AccountDeletionResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notification/": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve all notifications for the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Notifications"
+                ],
+                "summary": "Get user notifications",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_notification.NotificationsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve notifications",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/notification/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark the specified notification as read",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Notifications"
+                ],
+                "summary": "Mark a notification as read",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Notification ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Notification ID is required",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to mark notification as read",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the password of the logged in user. Every session, including the current one, is ended.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Email, current password and new password",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/profile": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the caller's currency, locale, timezone, first day of week and default account. Settings the user has not chosen show the gateway's defaults.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Get profile preferences",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.Profile"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the caller's preferences. Empty fields fall back to the gateway's defaults. The timezone decides the date of new transactions and the default report ranges; the locale decides how exports format numbers and dates.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Profile"
+                ],
+                "summary": "Update profile preferences",
+                "parameters": [
+                    {
+                        "description": "Preferences",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.Profile"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.Profile"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/report/bugdet": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a budget performance report for a specific budget by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Reports"
+                ],
+                "summary": "Get budget performance report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Budget ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_report.BudgetPerformanceReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve budget performance report",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/report/goal": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a goal progress report for a specific goal by its ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Reports"
+                ],
+                "summary": "Get goal progress report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Goal ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_report.GoalProgressReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve goal progress report",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/report/incoming": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve an income report for a user between the specified start and end dates. Missing dates default to the current period in the user's timezone, the month unless period is week or year.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Reports"
+                ],
+                "summary": "Get income report",
+                "parameters": [
+                    {
+                        "description": "Get Income Report Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.GetIncomeReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_report.IncomeReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve income report",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/report/spending": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a spending report for a user between the specified start and end dates. Missing dates default to the current period in the user's timezone, the month unless period is week or year.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Reports"
+                ],
+                "summary": "Get spending report",
+                "parameters": [
+                    {
+                        "description": "Get Spending Report Request",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.GetSpendingReportRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_report.SpendingReportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve spending report",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the devices the caller is logged in on, with the session of this request marked current",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gateway-service_internal_models.Session"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions/revoke-others": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Log the caller out everywhere except the session of this request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Revoke all other sessions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.RevokeSessionsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Log the caller out of one session. Its access token is rejected at once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the caller's unexpired personal access tokens with their last use",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personal Access Tokens"
+                ],
+                "summary": "List personal access tokens",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/gateway-service_internal_models.PersonalToken"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a named token for scripts and integrations, sent in the Authorization header like a JWT. It only reaches the routes its scopes allow and is shown only in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personal Access Tokens"
+                ],
+                "summary": "Create a personal access token",
+                "parameters": [
+                    {
+                        "description": "Name, scopes and optional expiry",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreatePersonalTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreatePersonalTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens/scopes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the scopes personal access tokens can be given",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personal Access Tokens"
+                ],
+                "summary": "List personal access token scopes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.PersonalTokenScopesResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the caller's personal access tokens at once",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Personal Access Tokens"
+                ],
+                "summary": "Revoke a personal access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/transaction": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get all financial transactions for the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Transactions"
+                ],
+                "summary": "Get transactions",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get transactions",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Update financial transaction details by transaction ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Transactions"
+                ],
+                "summary": "Update transaction",
+                "parameters": [
+                    {
+                        "description": "Updated transaction details",
+                        "name": "UpdateTransactionRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_transaction.UpdateTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update transaction",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new financial transaction for the authenticated user, dated today in the user's timezone. Without account_id it goes to the user's default account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Transactions"
+                ],
+                "summary": "Create a transaction",
+                "parameters": [
+                    {
+                        "description": "Transaction details",
+                        "name": "CreateTransactionRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_internal_models.CreateTransactionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create transaction",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/transaction/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get financial transaction details by transaction ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Transactions"
+                ],
+                "summary": "Get transaction by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid transaction ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get transaction",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete financial transaction by transaction ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User Transactions"
+                ],
+                "summary": "Delete transaction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Transaction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "message: Transaction deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid transaction ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete transaction",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "gateway-service_genproto_account.AccountResponse": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_account.AccountsResponse": {
+            "type": "object",
+            "properties": {
+                "accounts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                    }
+                }
+            }
+        },
+        "gateway-service_genproto_account.UpdateAccountRequest": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.CreateAdminRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.DeleteUserRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.LogoutRequest": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.LogoutResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.RegisterRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_auth.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_budget.BudgetResponse": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_budget.BudgetsResponse": {
+            "type": "object",
+            "properties": {
+                "budgets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gateway-service_genproto_budget.BudgetResponse"
+                    }
+                }
+            }
+        },
+        "gateway-service_genproto_budget.UpdateBudgetRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "end_date": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "period": {
+                    "type": "string"
+                },
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_category.CategoriesResponse": {
+            "type": "object",
+            "properties": {
+                "categories": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                    }
+                }
+            }
+        },
+        "gateway-service_genproto_category.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_category.UpdateCategoryRequest": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_goal.GoalResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "current_amount": {
+                    "type": "number"
+                },
+                "deadline": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "target_amount": {
+                    "type": "number"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_genproto_goal.GoalsResponse": {
+            "type": "object",
+            "properties": {
+                "goals": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gateway-service_genproto_goal.GoalResponse"
+                    }
+                }
             }
-        }
-    },
-    "definitions": {
-        "gateway-service_genproto_account.AccountResponse": {
+        },
+        "gateway-service_genproto_goal.UpdateGoalRequest": {
             "type": "object",
             "properties": {
-                "balance": {
+                "current_amount": {
                     "type": "number"
                 },
-                "created_at": {
-                    "type": "string"
-                },
-                "currency": {
+                "deadline": {
                     "type": "string"
                 },
                 "id": {
@@ -1974,10 +4740,27 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
-                "type": {
+                "status": {
                     "type": "string"
                 },
-                "updated_at": {
+                "target_amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "gateway-service_genproto_notification.NotificationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_read": {
+                    "type": "boolean"
+                },
+                "message": {
                     "type": "string"
                 },
                 "user_id": {
@@ -1985,131 +4768,228 @@ const docTemplate = `{
                 }
             }
         },
-        "gateway-service_genproto_account.AccountsResponse": {
+        "gateway-service_genproto_notification.NotificationsResponse": {
             "type": "object",
             "properties": {
-                "accounts": {
+                "notifications": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gateway-service_genproto_account.AccountResponse"
+                        "$ref": "#/definitions/gateway-service_genproto_notification.NotificationResponse"
                     }
                 }
             }
         },
-        "gateway-service_genproto_account.UpdateAccountRequest": {
+        "gateway-service_genproto_report.BudgetPerformanceReportResponse": {
             "type": "object",
             "properties": {
-                "balance": {
-                    "type": "number"
+                "category_performance": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
                 },
-                "currency": {
-                    "type": "string"
+                "total_budget": {
+                    "type": "number"
                 },
-                "id": {
-                    "type": "string"
+                "total_spent": {
+                    "type": "number"
+                }
+            }
+        },
+        "gateway-service_genproto_report.GoalProgressReportResponse": {
+            "type": "object",
+            "properties": {
+                "category_performance": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
                 },
-                "name": {
-                    "type": "string"
+                "target_amount": {
+                    "type": "number"
                 },
-                "type": {
-                    "type": "string"
+                "total_progress": {
+                    "type": "number"
                 }
             }
         },
-        "gateway-service_genproto_auth.CreateAdminRequest": {
+        "gateway-service_genproto_report.IncomeReportResponse": {
             "type": "object",
             "properties": {
-                "user_id": {
-                    "type": "string"
+                "category_income": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "total_income": {
+                    "type": "number"
                 }
             }
         },
-        "gateway-service_genproto_auth.DeleteUserRequest": {
+        "gateway-service_genproto_report.SpendingReportResponse": {
             "type": "object",
             "properties": {
-                "user_id": {
-                    "type": "string"
+                "category_spending": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "total_spending": {
+                    "type": "number"
                 }
             }
         },
-        "gateway-service_genproto_auth.LoginRequest": {
+        "gateway-service_genproto_transaction.TransactionResponse": {
             "type": "object",
             "properties": {
-                "email": {
+                "account_id": {
                     "type": "string"
                 },
-                "password": {
+                "amount": {
+                    "type": "number"
+                },
+                "category_id": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_auth.LoginResponse": {
+        "gateway-service_genproto_transaction.TransactionsResponse": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "type": "string"
-                },
-                "refresh_token": {
-                    "type": "string"
+                "transactions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionResponse"
+                    }
                 }
             }
         },
-        "gateway-service_genproto_auth.LogoutRequest": {
+        "gateway-service_genproto_transaction.UpdateTransactionRequest": {
             "type": "object",
             "properties": {
-                "user_id": {
+                "amount": {
+                    "type": "number"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_auth.LogoutResponse": {
+        "gateway-service_internal_items_healthcheck.Report": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "dependencies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/gateway-service_internal_items_healthcheck.Result"
+                    }
+                },
+                "status": {
+                    "$ref": "#/definitions/gateway-service_internal_items_healthcheck.Status"
                 }
             }
         },
-        "gateway-service_genproto_auth.RegisterRequest": {
+        "gateway-service_internal_items_healthcheck.Result": {
             "type": "object",
             "properties": {
-                "email": {
+                "error": {
                     "type": "string"
                 },
-                "password": {
+                "latency_ms": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/gateway-service_internal_items_healthcheck.Status"
                 }
             }
         },
-        "gateway-service_genproto_auth.RegisterResponse": {
+        "gateway-service_internal_items_healthcheck.Status": {
+            "type": "string",
+            "enum": [
+                "up",
+                "down"
+            ],
+            "x-enum-varnames": [
+                "StatusUp",
+                "StatusDown"
+            ]
+        },
+        "gateway-service_internal_models.AccountDeletionResponse": {
             "type": "object",
             "properties": {
-                "user_id": {
+                "scheduled_for": {
+                    "description": "ScheduledFor is when the account is deleted unless the user logs in\nbefore then.",
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_auth.UpdateUserRequest": {
+        "gateway-service_internal_models.ChangePasswordRequest": {
             "type": "object",
             "properties": {
+                "current_password": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
-                "is_active": {
-                    "type": "boolean"
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_internal_models.CreateAccountRequest": {
+            "type": "object",
+            "properties": {
+                "balance": {
+                    "type": "number"
                 },
-                "password": {
+                "currency": {
                     "type": "string"
                 },
-                "role": {
+                "name": {
                     "type": "string"
                 },
-                "user_id": {
+                "type": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_budget.BudgetResponse": {
+        "gateway-service_internal_models.CreateBudgetRequest": {
             "type": "object",
             "properties": {
                 "amount": {
@@ -2118,439 +4998,464 @@ const docTemplate = `{
                 "category_id": {
                     "type": "string"
                 },
-                "created_at": {
+                "end_date": {
                     "type": "string"
                 },
-                "end_date": {
+                "period": {
                     "type": "string"
                 },
-                "id": {
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_internal_models.CreateCategoryRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
                     "type": "string"
                 },
-                "period": {
+                "type": {
                     "type": "string"
+                }
+            }
+        },
+        "gateway-service_internal_models.CreateGoalRequest": {
+            "type": "object",
+            "properties": {
+                "current_amount": {
+                    "type": "number"
                 },
-                "start_date": {
+                "deadline": {
                     "type": "string"
                 },
-                "updated_at": {
+                "name": {
                     "type": "string"
                 },
-                "user_id": {
+                "status": {
                     "type": "string"
+                },
+                "target_amount": {
+                    "type": "number"
                 }
             }
         },
-        "gateway-service_genproto_budget.BudgetsResponse": {
+        "gateway-service_internal_models.CreatePersonalTokenRequest": {
             "type": "object",
             "properties": {
-                "budgets": {
+                "expires_at": {
+                    "description": "ExpiresAt defaults to personal_tokens.default_ttl from now.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gateway-service_genproto_budget.BudgetResponse"
+                        "type": "string"
                     }
                 }
             }
         },
-        "gateway-service_genproto_budget.UpdateBudgetRequest": {
+        "gateway-service_internal_models.CreatePersonalTokenResponse": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
+                "created_at": {
+                    "type": "string"
                 },
-                "end_date": {
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "period": {
+                "last_used_at": {
                     "type": "string"
                 },
-                "start_date": {
+                "name": {
                     "type": "string"
-                }
-            }
-        },
-        "gateway-service_genproto_category.CategoriesResponse": {
-            "type": "object",
-            "properties": {
-                "categories": {
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gateway-service_genproto_category.CategoryResponse"
+                        "type": "string"
                     }
+                },
+                "token": {
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_category.CategoryResponse": {
+        "gateway-service_internal_models.CreateTransactionRequest": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "account_id": {
                     "type": "string"
                 },
-                "id": {
+                "amount": {
+                    "type": "number"
+                },
+                "category_id": {
                     "type": "string"
                 },
-                "name": {
+                "description": {
                     "type": "string"
                 },
                 "type": {
                     "type": "string"
+                }
+            }
+        },
+        "gateway-service_internal_models.ElevateRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
                 },
-                "updated_at": {
+                "email": {
                     "type": "string"
                 },
-                "user_id": {
+                "password": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_category.UpdateCategoryRequest": {
+        "gateway-service_internal_models.ElevateResponse": {
             "type": "object",
             "properties": {
-                "id": {
+                "elevation_token": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string"
-                },
-                "type": {
+                "expires_at": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_goal.GoalResponse": {
+        "gateway-service_internal_models.ExportJob": {
             "type": "object",
             "properties": {
                 "created_at": {
                     "type": "string"
                 },
-                "current_amount": {
-                    "type": "number"
-                },
-                "deadline": {
+                "download_expires_at": {
                     "type": "string"
                 },
-                "id": {
+                "download_url": {
+                    "description": "DownloadURL is a signed link to the archive, set once done. It works\nwithout the Authorization header until DownloadExpiresAt.",
                     "type": "string"
                 },
-                "name": {
+                "error": {
                     "type": "string"
                 },
-                "status": {
+                "finished_at": {
                     "type": "string"
                 },
-                "target_amount": {
-                    "type": "number"
-                },
-                "updated_at": {
+                "id": {
                     "type": "string"
                 },
-                "user_id": {
+                "size": {
+                    "description": "Size of the archive in bytes, once done.",
+                    "type": "integer"
+                },
+                "status": {
+                    "description": "Status is pending, done or failed.",
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_goal.GoalsResponse": {
+        "gateway-service_internal_models.ForgotPasswordRequest": {
             "type": "object",
             "properties": {
-                "goals": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/gateway-service_genproto_goal.GoalResponse"
-                    }
+                "email": {
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_goal.UpdateGoalRequest": {
+        "gateway-service_internal_models.GetIncomeReportRequest": {
             "type": "object",
             "properties": {
-                "current_amount": {
-                    "type": "number"
+                "end_date": {
+                    "type": "string"
                 },
-                "deadline": {
+                "period": {
+                    "description": "Period is week, month or year and fills missing dates with the\ncurrent one. It defaults to month.",
                     "type": "string"
                 },
-                "id": {
+                "start_date": {
+                    "type": "string"
+                }
+            }
+        },
+        "gateway-service_internal_models.GetSpendingReportRequest": {
+            "type": "object",
+            "properties": {
+                "end_date": {
                     "type": "string"
                 },
-                "name": {
+                "period": {
+                    "description": "Period is week, month or year and fills missing dates with the\ncurrent one. It defaults to month.",
                     "type": "string"
                 },
-                "status": {
+                "start_date": {
                     "type": "string"
+                }
+            }
+        },
+        "gateway-service_internal_models.ImpersonateRequest": {
+            "type": "object",
+            "properties": {
+                "allow_write": {
+                    "description": "AllowWrite lets the token change data; only superadmins may set it.",
+                    "type": "boolean"
                 },
-                "target_amount": {
-                    "type": "number"
+                "duration_minutes": {
+                    "description": "DurationMinutes defaults to impersonation.default_ttl.",
+                    "type": "integer"
+                },
+                "reason": {
+                    "description": "Reason is recorded in the audit log, e.g. a support ticket number.",
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_notification.NotificationResponse": {
+        "gateway-service_internal_models.ImpersonationResponse": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "access_token": {
+                    "type": "string"
+                },
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "is_read": {
+                "read_only": {
                     "type": "boolean"
                 },
-                "message": {
-                    "type": "string"
-                },
                 "user_id": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_notification.NotificationsResponse": {
+        "gateway-service_internal_models.MFACodeRequest": {
             "type": "object",
             "properties": {
-                "notifications": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/gateway-service_genproto_notification.NotificationResponse"
-                    }
+                "code": {
+                    "description": "Code is a 6 digit TOTP code or, where accepted, a recovery code.",
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_report.BudgetPerformanceReportResponse": {
+        "gateway-service_internal_models.MFAEnrollResponse": {
             "type": "object",
             "properties": {
-                "category_performance": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "number"
-                    }
-                },
-                "total_budget": {
-                    "type": "number"
+                "otpauth_uri": {
+                    "type": "string"
                 },
-                "total_spent": {
-                    "type": "number"
+                "secret": {
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_report.GoalProgressReportResponse": {
+        "gateway-service_internal_models.MFARecoveryCodesResponse": {
             "type": "object",
             "properties": {
-                "category_performance": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "number"
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
+                }
+            }
+        },
+        "gateway-service_internal_models.MFAStatusResponse": {
+            "type": "object",
+            "properties": {
+                "enabled": {
+                    "type": "boolean"
                 },
-                "target_amount": {
-                    "type": "number"
+                "recovery_codes_left": {
+                    "type": "integer"
                 },
-                "total_progress": {
-                    "type": "number"
+                "required": {
+                    "type": "boolean"
                 }
             }
         },
-        "gateway-service_genproto_report.IncomeReportResponse": {
+        "gateway-service_internal_models.MFAVerifyRequest": {
             "type": "object",
             "properties": {
-                "category_income": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "number"
-                    }
+                "challenge_token": {
+                    "type": "string"
                 },
-                "total_income": {
-                    "type": "number"
+                "code": {
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_genproto_report.SpendingReportResponse": {
+        "gateway-service_internal_models.OIDCProvidersResponse": {
             "type": "object",
             "properties": {
-                "category_spending": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "number"
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
                     }
-                },
-                "total_spending": {
-                    "type": "number"
                 }
             }
         },
-        "gateway-service_genproto_transaction.TransactionResponse": {
+        "gateway-service_internal_models.PersonalToken": {
             "type": "object",
             "properties": {
-                "account_id": {
-                    "type": "string"
-                },
-                "amount": {
-                    "type": "number"
-                },
-                "category_id": {
-                    "type": "string"
-                },
                 "created_at": {
                     "type": "string"
                 },
-                "date": {
-                    "type": "string"
-                },
-                "description": {
+                "expires_at": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "type": {
+                "last_used_at": {
                     "type": "string"
                 },
-                "updated_at": {
+                "name": {
                     "type": "string"
                 },
-                "user_id": {
-                    "type": "string"
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "gateway-service_genproto_transaction.TransactionsResponse": {
+        "gateway-service_internal_models.PersonalTokenScopesResponse": {
             "type": "object",
             "properties": {
-                "transactions": {
+                "scopes": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/gateway-service_genproto_transaction.TransactionResponse"
+                        "type": "string"
                     }
                 }
             }
         },
-        "gateway-service_genproto_transaction.UpdateTransactionRequest": {
+        "gateway-service_internal_models.Profile": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
+                "currency": {
+                    "description": "Currency is an ISO 4217 code, e.g. USD.",
+                    "type": "string"
                 },
-                "date": {
+                "default_account_id": {
+                    "description": "DefaultAccountID is used for transactions created without an account.",
                     "type": "string"
                 },
-                "description": {
+                "first_day_of_week": {
+                    "description": "FirstDayOfWeek is a lower-case weekday name, e.g. monday.",
                     "type": "string"
                 },
-                "id": {
+                "locale": {
+                    "description": "Locale is one of en-US, en-GB, de-DE, fr-FR, ru-RU and uz-UZ.",
                     "type": "string"
                 },
-                "type": {
+                "timezone": {
+                    "description": "Timezone is an IANA name, e.g. Asia/Tashkent.",
                     "type": "string"
                 }
             }
         },
-        "gateway-service_internal_models.CreateAccountRequest": {
+        "gateway-service_internal_models.ResendVerificationRequest": {
             "type": "object",
             "properties": {
-                "balance": {
-                    "type": "number"
-                },
-                "currency": {
-                    "type": "string"
-                },
-                "name": {
-                    "type": "string"
-                },
-                "type": {
+                "email": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_internal_models.CreateBudgetRequest": {
+        "gateway-service_internal_models.ResetPasswordRequest": {
             "type": "object",
             "properties": {
-                "amount": {
-                    "type": "number"
-                },
-                "category_id": {
-                    "type": "string"
-                },
-                "end_date": {
-                    "type": "string"
-                },
-                "period": {
+                "new_password": {
                     "type": "string"
                 },
-                "start_date": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_internal_models.CreateCategoryRequest": {
+        "gateway-service_internal_models.RevokeSessionsResponse": {
             "type": "object",
             "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "type": {
-                    "type": "string"
+                "revoked": {
+                    "type": "integer"
                 }
             }
         },
-        "gateway-service_internal_models.CreateGoalRequest": {
+        "gateway-service_internal_models.Session": {
             "type": "object",
             "properties": {
-                "current_amount": {
-                    "type": "number"
+                "created_at": {
+                    "type": "string"
                 },
-                "deadline": {
+                "current": {
+                    "description": "Current marks the session of the request's own token.",
+                    "type": "boolean"
+                },
+                "id": {
                     "type": "string"
                 },
-                "name": {
+                "ip": {
                     "type": "string"
                 },
-                "status": {
+                "last_seen_at": {
                     "type": "string"
                 },
-                "target_amount": {
-                    "type": "number"
+                "user_agent": {
+                    "type": "string"
                 }
             }
         },
-        "gateway-service_internal_models.CreateTransactionRequest": {
+        "gateway-service_internal_models.SetRoleRequest": {
             "type": "object",
             "properties": {
-                "account_id": {
+                "role": {
                     "type": "string"
-                },
-                "amount": {
-                    "type": "number"
-                },
-                "category_id": {
+                }
+            }
+        },
+        "gateway-service_internal_models.StaffMember": {
+            "type": "object",
+            "properties": {
+                "last_active_at": {
                     "type": "string"
                 },
-                "description": {
+                "role": {
                     "type": "string"
                 },
-                "type": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_internal_models.GetIncomeReportRequest": {
+        "gateway-service_internal_models.UpdateUserRequest": {
             "type": "object",
             "properties": {
-                "end_date": {
+                "email": {
                     "type": "string"
                 },
-                "start_date": {
+                "user_id": {
                     "type": "string"
                 }
             }
         },
-        "gateway-service_internal_models.GetSpendingReportRequest": {
+        "gateway-service_internal_models.VerifyEmailRequest": {
             "type": "object",
             "properties": {
-                "end_date": {
-                    "type": "string"
-                },
-                "start_date": {
+                "token": {
                     "type": "string"
                 }
             }
@@ -0,0 +1,105 @@
+package audit
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"gateway-service/internal/items/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditor *audit.Auditor
+	logger  *slog.Logger
+}
+
+func NewAuditHandler(auditor *audit.Auditor, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		auditor: auditor,
+		logger:  logger,
+	}
+}
+
+// QueryAuditHandler godoc
+// @Summary Query the audit log
+// @Security BearerAuth
+// @Description Returns audit records, newest first, filtered by the given parameters
+// @Tags Super Admin Audit
+// @Produce json
+// @Param actor_id query string false "Actor user ID"
+// @Param acted_by query string false "ID of the admin impersonating the actor"
+// @Param action query string false "Action, e.g. account.update"
+// @Param resource query string false "Resource type, e.g. account"
+// @Param resource_id query string false "Resource ID"
+// @Param outcome query string false "success or failure"
+// @Param from query string false "RFC 3339 start time (inclusive)"
+// @Param to query string false "RFC 3339 end time (exclusive)"
+// @Param limit query int false "Maximum records, default 100, max 1000"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /superadmin/audit [get]
+func (h *AuditHandler) QueryAuditHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "QueryAuditHandler called")
+
+	filter := audit.Filter{
+		ActorID:    c.Query("actor_id"),
+		ActedBy:    c.Query("acted_by"),
+		Action:     c.Query("action"),
+		Resource:   c.Query("resource"),
+		ResourceID: c.Query("resource_id"),
+		Outcome:    audit.Outcome(c.Query("outcome")),
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			c.IndentedJSON(400, gin.H{"error": "from must be an RFC 3339 time"})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			c.IndentedJSON(400, gin.H{"error": "to must be an RFC 3339 time"})
+			return
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 {
+			c.IndentedJSON(400, gin.H{"error": "limit must be a positive integer"})
+			return
+		}
+	}
+
+	records, err := h.auditor.Query(filter)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"records": records, "count": len(records)})
+}
+
+// VerifyAuditHandler godoc
+// @Summary Verify the audit log
+// @Security BearerAuth
+// @Description Recomputes the hash chain of the audit file and reports the first tampered record, if any
+// @Tags Super Admin Audit
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Router /superadmin/audit/verify [get]
+func (h *AuditHandler) VerifyAuditHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "VerifyAuditHandler called")
+
+	if err := h.auditor.Verify(); err != nil {
+		c.IndentedJSON(409, gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"valid": true})
+}
@@ -0,0 +1,326 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errSelfAction      = errors.New("admins cannot perform this action on their own account")
+	errStaffRoleChange = errors.New("only superadmins can change the role of an admin")
+	errStaffAction     = errors.New("only superadmins can perform this action on an admin")
+)
+
+// checkStaffTarget reports whether the caller may act on userId and
+// returns userId's role. Only superadmins may act on admins, superadmins
+// and users with no role in the registry, who may be staff that have not
+// logged in yet. Otherwise entry is audited with errStaff, 403 is written
+// and false returned.
+func (h *AuthHandler) checkStaffTarget(c *gin.Context, userId string, entry audit.Entry, errStaff error) (string, bool) {
+	role, err := h.roles.RoleOf(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return "", false
+	}
+	if role != rbac.RoleUser && middleware.GetRole(c, h.config) != rbac.RoleSuperadmin {
+		h.audit.Log(c, entry, errStaff)
+		c.IndentedJSON(403, gin.H{"error": errStaff.Error()})
+		return "", false
+	}
+	return role, true
+}
+
+// bindPathID makes the :id path parameter authoritative. A body ID is
+// accepted only if it is empty or matches; otherwise 400 is written and
+// false returned.
+func bindPathID(c *gin.Context, bodyID *string) bool {
+	id := c.Param("id")
+	if *bodyID != "" && *bodyID != id {
+		c.IndentedJSON(400, gin.H{"error": "user_id in body does not match the path"})
+		return false
+	}
+	*bodyID = id
+	return true
+}
+
+// notImplemented reports that the auth service has no RPC for an action.
+func notImplemented(c *gin.Context, rpc string) {
+	c.IndentedJSON(501, gin.H{"error": "The auth service does not provide " + rpc + " yet"})
+}
+
+// upstreamError maps an auth service error to a response, turning
+// Unimplemented into 501 and NotFound into 404.
+func upstreamError(c *gin.Context, err error, rpc string) {
+	switch status.Code(err) {
+	case codes.Unimplemented:
+		notImplemented(c, rpc)
+	case codes.NotFound:
+		c.IndentedJSON(404, gin.H{"error": "User not found"})
+	default:
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// LookupUserHandler godoc
+// @Summary Find a user by email
+// @Security BearerAuth
+// @Description Look up a user's ID by email address
+// @Tags Admin Users
+// @Produce json
+// @Param email query string true "Email address"
+// @Success 200 {object} pb.RegisterResponse
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/lookup [get]
+func (h *AuthHandler) LookupUserHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "LookupUserHandler called")
+	email := c.Query("email")
+	if email == "" {
+		c.IndentedJSON(400, gin.H{"error": "email is required"})
+		return
+	}
+
+	resp, err := h.auth.GetUserByEmail(c.Request.Context(), &pb.GetUserByEmailRequest{Email: email})
+	if err != nil {
+		upstreamError(c, err, "GetUserByEmail")
+		return
+	}
+
+	c.IndentedJSON(200, resp)
+}
+
+// GetUserHandler godoc
+// @Summary Get a user by ID
+// @Security BearerAuth
+// @Description Get a user's details by ID. Returns 501 until the auth service provides a GetUserById RPC.
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 501 {object} gin.H
+// @Router /admin/users/{id} [get]
+func (h *AuthHandler) GetUserHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "GetUserHandler called")
+	notImplemented(c, "GetUserById")
+}
+
+// ListUsersHandler godoc
+// @Summary List users
+// @Security BearerAuth
+// @Description Paginated list of users. Returns 501 until the auth service provides a ListUsers RPC.
+// @Tags Admin Users
+// @Produce json
+// @Param page query int false "Page number, starting at 1"
+// @Param limit query int false "Page size, at most 100"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 501 {object} gin.H
+// @Router /admin/users [get]
+func (h *AuthHandler) ListUsersHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ListUsersHandler called")
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.IndentedJSON(400, gin.H{"error": "page must be a positive integer"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.IndentedJSON(400, gin.H{"error": "limit must be between 1 and 100"})
+		return
+	}
+
+	notImplemented(c, "ListUsers")
+}
+
+// ActivateUserHandler godoc
+// @Summary Activate a user
+// @Security BearerAuth
+// @Description Allow a deactivated user to use the API again. Only superadmins can act on admins and superadmins.
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/activate [post]
+func (h *AuthHandler) ActivateUserHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ActivateUserHandler called")
+	h.setUserActive(c, true)
+}
+
+// DeactivateUserHandler godoc
+// @Summary Deactivate a user
+// @Security BearerAuth
+// @Description Block a user from the API. Their existing tokens are rejected immediately. Only superadmins can act on admins and superadmins.
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/deactivate [post]
+func (h *AuthHandler) DeactivateUserHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "DeactivateUserHandler called")
+	h.setUserActive(c, false)
+}
+
+// setUserActive enforces the state at the gateway, which takes effect at
+// once, and mirrors it to the auth service's IsActive flag.
+func (h *AuthHandler) setUserActive(c *gin.Context, active bool) {
+	userId := c.Param("id")
+	action := "user.activate"
+	if !active {
+		action = "user.deactivate"
+		if userId == middleware.GetUser_id(c, h.config) {
+			h.audit.Log(c, audit.Entry{Action: action, Resource: "user", ResourceID: userId}, errSelfAction)
+			c.IndentedJSON(400, gin.H{"error": errSelfAction.Error()})
+			return
+		}
+	}
+	if _, ok := h.checkStaffTarget(c, userId, audit.Entry{Action: action, Resource: "user", ResourceID: userId}, errStaffAction); !ok {
+		return
+	}
+
+	err := h.redis.SetUserActive(c.Request.Context(), userId, active)
+	h.audit.Log(c, audit.Entry{Action: action, Resource: "user", ResourceID: userId, After: gin.H{"is_active": active}}, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.updateUser(c.Request.Context(), userId, func(req *pb.UpdateUserRequest) { req.IsActive = active })
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to sync active state to auth service", "user_id", userId, "error", err.Error())
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "User updated successfully", "is_active": active})
+}
+
+// updateUser sends the auth service an update of userId made by change.
+// UpdateUserRequest has no field presence, so the role and active state
+// the gateway holds are filled in first rather than sent as "" and false.
+// Users the registry has no role for fail with rbac.ErrNoRole, as their
+// role would be wiped. Email and Password are left empty, which the auth
+// service keeps as they are; the gateway has no way to read them back.
+func (h *AuthHandler) updateUser(ctx context.Context, userId string, change func(req *pb.UpdateUserRequest)) error {
+	role, err := h.roles.RoleOf(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if role == "" {
+		return rbac.ErrNoRole
+	}
+	userStatus, err := h.redis.GetUserStatus(ctx, userId)
+	if err != nil {
+		return err
+	}
+	req := &pb.UpdateUserRequest{UserId: userId, Role: role, IsActive: !userStatus.Inactive}
+	change(req)
+	_, err = h.auth.UpdateUser(ctx, req)
+	return err
+}
+
+// ForceLogoutHandler godoc
+// @Summary Force logout
+// @Security BearerAuth
+// @Description End every session of a user. Tokens issued before now are rejected. Only superadmins can act on admins and superadmins.
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/logout [post]
+func (h *AuthHandler) ForceLogoutHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ForceLogoutHandler called")
+	userId := c.Param("id")
+	entry := audit.Entry{Action: "user.force_logout", Resource: "user", ResourceID: userId}
+	if _, ok := h.checkStaffTarget(c, userId, entry, errStaffAction); !ok {
+		return
+	}
+
+	err := h.revokeSessions(c, userId)
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "User logged out of all sessions"})
+}
+
+// revokeSessions rejects the user's current tokens at the gateway and
+// asks the auth service to drop its refresh tokens.
+func (h *AuthHandler) revokeSessions(c *gin.Context, userId string) error {
+	if err := h.redis.RevokeTokens(c.Request.Context(), userId, h.config.JWT.AccessTokenTTL); err != nil {
+		return err
+	}
+	if err := h.redis.ForgetSessions(c.Request.Context(), userId); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to clear session list", "user_id", userId, "error", err.Error())
+	}
+	if _, err := h.auth.Logout(c.Request.Context(), &pb.LogoutRequest{UserId: userId}); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Auth service logout failed", "user_id", userId, "error", err.Error())
+	}
+	return nil
+}
+
+// ResetRoleHandler godoc
+// @Summary Reset a user's role
+// @Security BearerAuth
+// @Description Reset a user's role to "user" and end their sessions so the new role applies at once
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Failure 501 {object} gin.H
+// @Router /admin/users/{id}/reset-role [post]
+func (h *AuthHandler) ResetRoleHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ResetRoleHandler called")
+	userId := c.Param("id")
+	entry := audit.Entry{Action: "user.reset_role", Resource: "user", ResourceID: userId, After: gin.H{"role": "user"}}
+
+	if userId == middleware.GetUser_id(c, h.config) {
+		h.audit.Log(c, entry, errSelfAction)
+		c.IndentedJSON(400, gin.H{"error": errSelfAction.Error()})
+		return
+	}
+
+	current, ok := h.checkStaffTarget(c, userId, entry, errStaffRoleChange)
+	if !ok {
+		return
+	}
+	entry.Before = gin.H{"role": current}
+
+	err := h.assignRole(c, userId, rbac.RoleUser)
+	h.audit.Log(c, entry, err)
+	switch {
+	case errors.Is(err, rbac.ErrLastSuperadmin), errors.Is(err, rbac.ErrNoRole):
+		c.IndentedJSON(409, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		upstreamError(c, err, "UpdateUser")
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Role reset to user"})
+}
@@ -0,0 +1,237 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/rbac"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestResetRoleHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("admin cannot demote an unregistered admin", func(t *testing.T) {
+		h := newTestAuthHandler(t)
+		router := gin.New()
+		router.POST("/admin/users/:id/reset-role", h.ResetRoleHandler)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/admin-2/reset-role", nil)
+		req.Header.Set("Authorization", testToken(t, h.config, "admin-1", rbac.RoleAdmin))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusForbidden, rec.Body)
+		}
+		if role, err := h.roles.RoleOf(req.Context(), "admin-2"); err != nil || role != "" {
+			t.Fatalf("RoleOf = %q, %v; want no role", role, err)
+		}
+	})
+
+	t.Run("superadmin cannot reset a user with no recorded role", func(t *testing.T) {
+		h := newTestAuthHandler(t)
+		router := gin.New()
+		router.POST("/admin/users/:id/reset-role", h.ResetRoleHandler)
+
+		req := httptest.NewRequest(http.MethodPost, "/admin/users/admin-2/reset-role", nil)
+		req.Header.Set("Authorization", testToken(t, h.config, "superadmin-1", rbac.RoleSuperadmin))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+		}
+	})
+}
+
+func TestUpdateUserSendsCurrentState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newTestAuthHandler(t)
+	ctx := context.Background()
+	fake := h.auth.(*fakeAuthClient)
+	if err := h.roles.Ensure(ctx, "admin-2", rbac.RoleAdmin); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.POST("/admin/users/:id/deactivate", h.DeactivateUserHandler)
+	req := httptest.NewRequest(http.MethodPost, "/admin/users/admin-2/deactivate", nil)
+	req.Header.Set("Authorization", testToken(t, h.config, "superadmin-1", rbac.RoleSuperadmin))
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("deactivate status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	// A password change of the deactivated admin must neither reactivate
+	// nor demote them.
+	if err := h.updateUser(ctx, "admin-2", func(req *pb.UpdateUserRequest) { req.Password = "new-password" }); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		role     string
+		password string
+		isActive bool
+	}{
+		{rbac.RoleAdmin, "", false},
+		{rbac.RoleAdmin, "new-password", false},
+	}
+	if len(fake.updates) != len(want) {
+		t.Fatalf("sent %d updates, want %d", len(fake.updates), len(want))
+	}
+	for i, w := range want {
+		got := fake.updates[i]
+		if got.UserId != "admin-2" || got.Role != w.role || got.Password != w.password || got.IsActive != w.isActive || got.Email != "" {
+			t.Errorf("update %d = %+v, want role %q, password %q, is_active %v", i, got, w.role, w.password, w.isActive)
+		}
+	}
+}
+
+func TestUpdateUserHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"email", `{"email":"new@example.com"}`, http.StatusOK},
+		{"role", `{"email":"new@example.com","role":"superadmin"}`, http.StatusBadRequest},
+		{"is_active", `{"email":"new@example.com","is_active":false}`, http.StatusBadRequest},
+		{"password", `{"email":"new@example.com","password":"secret"}`, http.StatusBadRequest},
+		{"no email", `{}`, http.StatusBadRequest},
+		{"other user_id", `{"user_id":"user-3","email":"new@example.com"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			fake := h.auth.(*fakeAuthClient)
+			if err := h.roles.Ensure(context.Background(), "user-2", rbac.RoleUser); err != nil {
+				t.Fatal(err)
+			}
+			router := gin.New()
+			router.PUT("/admin/update/:id", h.UpdateUserHandler)
+
+			req := httptest.NewRequest(http.MethodPut, "/admin/update/user-2", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", testToken(t, h.config, "admin-1", rbac.RoleAdmin))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if tt.status != http.StatusOK {
+				if len(fake.updates) != 0 {
+					t.Fatalf("sent %d updates, want none", len(fake.updates))
+				}
+				return
+			}
+			if len(fake.updates) != 1 {
+				t.Fatalf("sent %d updates, want 1", len(fake.updates))
+			}
+			got := fake.updates[0]
+			if got.Email != "new@example.com" || got.Role != rbac.RoleUser || !got.IsActive || got.Password != "" {
+				t.Errorf("update = %+v, want the new email with the current role and active state", got)
+			}
+		})
+	}
+}
+
+func TestStaffTargetsNeedSuperadmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	routes := []struct {
+		method, route, path string
+		handler             func(h *AuthHandler) gin.HandlerFunc
+	}{
+		{http.MethodPost, "/admin/users/:id/deactivate", "/admin/users/%s/deactivate", func(h *AuthHandler) gin.HandlerFunc { return h.DeactivateUserHandler }},
+		{http.MethodPost, "/admin/users/:id/activate", "/admin/users/%s/activate", func(h *AuthHandler) gin.HandlerFunc { return h.ActivateUserHandler }},
+		{http.MethodPost, "/admin/users/:id/logout", "/admin/users/%s/logout", func(h *AuthHandler) gin.HandlerFunc { return h.ForceLogoutHandler }},
+		{http.MethodDelete, "/admin/users/:id/sessions/:session_id", "/admin/users/%s/sessions/s-1", func(h *AuthHandler) gin.HandlerFunc { return h.AdminRevokeSessionHandler }},
+		{http.MethodDelete, "/admin/delete/:id", "/admin/delete/%s", func(h *AuthHandler) gin.HandlerFunc { return h.DeleteUserHandler }},
+	}
+	targets := []struct {
+		name          string
+		role          string
+		callerRole    string
+		wantForbidden bool
+	}{
+		{"admin on user", rbac.RoleUser, rbac.RoleAdmin, false},
+		{"admin on admin", rbac.RoleAdmin, rbac.RoleAdmin, true},
+		{"admin on superadmin", rbac.RoleSuperadmin, rbac.RoleAdmin, true},
+		{"admin on unregistered user", "", rbac.RoleAdmin, true},
+		{"superadmin on admin", rbac.RoleAdmin, rbac.RoleSuperadmin, false},
+		{"superadmin on unregistered user", "", rbac.RoleSuperadmin, false},
+	}
+	for _, route := range routes {
+		for _, tt := range targets {
+			t.Run(route.route+"/"+tt.name, func(t *testing.T) {
+				h := newTestAuthHandler(t)
+				if tt.role != "" {
+					if err := h.roles.Ensure(context.Background(), "target-1", tt.role); err != nil {
+						t.Fatal(err)
+					}
+				}
+				router := gin.New()
+				router.Handle(route.method, route.route, route.handler(h))
+
+				req := httptest.NewRequest(route.method, fmt.Sprintf(route.path, "target-1"), nil)
+				req.Header.Set("Authorization", testToken(t, h.config, "caller-1", tt.callerRole))
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+
+				if forbidden := rec.Code == http.StatusForbidden; forbidden != tt.wantForbidden {
+					t.Fatalf("status = %d, want forbidden %v: %s", rec.Code, tt.wantForbidden, rec.Body)
+				}
+			})
+		}
+	}
+}
+
+func TestUpdateUserNeedsKnownRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newTestAuthHandler(t)
+	fake := h.auth.(*fakeAuthClient)
+
+	changes := []struct {
+		name   string
+		change func(req *pb.UpdateUserRequest)
+	}{
+		{"password", func(req *pb.UpdateUserRequest) { req.Password = "new-password" }},
+		{"email", func(req *pb.UpdateUserRequest) { req.Email = "new@example.com" }},
+		{"active state", func(req *pb.UpdateUserRequest) { req.IsActive = false }},
+	}
+	for _, tt := range changes {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := h.updateUser(context.Background(), "unregistered-1", tt.change); !errors.Is(err, rbac.ErrNoRole) {
+				t.Fatalf("updateUser error = %v, want %v", err, rbac.ErrNoRole)
+			}
+		})
+	}
+
+	t.Run("update endpoint", func(t *testing.T) {
+		router := gin.New()
+		router.PUT("/admin/update/:id", h.UpdateUserHandler)
+		req := httptest.NewRequest(http.MethodPut, "/admin/update/unregistered-1", strings.NewReader(`{"email":"new@example.com"}`))
+		req.Header.Set("Authorization", testToken(t, h.config, "superadmin-1", rbac.RoleSuperadmin))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusConflict {
+			t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body)
+		}
+	})
+
+	if len(fake.updates) != 0 {
+		t.Fatalf("sent %d updates for a user with no recorded role, want none", len(fake.updates))
+	}
+}
@@ -1,32 +1,76 @@
 package auth
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"log/slog"
 
 	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/metrics"
+	"gateway-service/internal/items/msgbroker"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/certs"
+	"gateway-service/internal/pkg/oidc"
+	"gateway-service/internal/pkg/reqctx"
 
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type AuthHandler struct {
+	conn   *grpc.ClientConn
 	auth   pb.AuthServiceClient
+	redis  *redisservice.RedisService
 	logger *slog.Logger
+	broker *msgbroker.MsgBroker
+	audit  *audit.Auditor
+	roles  *rbac.Registry
+	oidc   map[string]*oidc.Provider
+	config *config.Config
 }
 
-func NewAuthHandler(logger *slog.Logger, config *config.Config) *AuthHandler {
+func NewAuthHandler(redis *redisservice.RedisService, logger *slog.Logger, broker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor, roles *rbac.Registry) *AuthHandler {
+	conn := connect(config.Upstream.Auth)
+
 	return &AuthHandler{
-		auth:   pb.NewAuthServiceClient(connect("auth", config.Server.AuthPort)),
+		conn:   conn,
+		auth:   pb.NewAuthServiceClient(conn),
+		redis:  redis,
 		logger: logger,
+		broker: broker,
+		audit:  auditor,
+		roles:  roles,
+		oidc:   newOIDCProviders(config),
+		config: config,
 	}
 }
 
-func connect(host, port string) *grpc.ClientConn {
-	address := host + port
-	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// Conn returns the connection to the auth service.
+func (h *AuthHandler) Conn() *grpc.ClientConn {
+	return h.conn
+}
+
+func (h *AuthHandler) Close() error {
+	return h.conn.Close()
+}
+
+func connect(endpoint config.Endpoint) *grpc.ClientConn {
+	creds, err := certs.GRPCCredentials(endpoint.TLS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn, err := grpc.NewClient(endpoint.Address(),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(reqctx.UnaryClientInterceptor(), metrics.UnaryClientInterceptor()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -35,7 +79,7 @@ func connect(host, port string) *grpc.ClientConn {
 
 // RegisterHandler godoc
 // @Summary Register a new user
-// @Description Register a new user with an email and password
+// @Description Register a new user with an email and password. A verification email is sent, and the budgeting API is unavailable until the address is confirmed.
 // @Tags User Auth
 // @Accept json
 // @Produce json
@@ -45,7 +89,7 @@ func connect(host, port string) *grpc.ClientConn {
 // @Failure 500 {object} gin.H
 // @Router /auth/user/register [post]
 func (h *AuthHandler) RegisterHandler(c *gin.Context) {
-	h.logger.Info("RegisterHandler called")
+	h.logger.InfoContext(c.Request.Context(), "RegisterHandler called")
 	var req pb.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -58,12 +102,21 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 		return
 	}
 
+	if err := h.redis.SetUserVerified(c.Request.Context(), resp.UserId, false); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	// The account exists by now, so a failed email is left to a resend.
+	if err := h.sendVerification(c, resp.UserId, req.Email); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to send verification email", "user_id", resp.UserId, "error", err.Error())
+	}
+
 	c.IndentedJSON(201, resp)
 }
 
 // LoginHandler godoc
 // @Summary User login
-// @Description Log in a user with email and password
+// @Description Log in a user with email and password. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.
 // @Tags User Auth
 // @Accept json
 // @Produce json
@@ -73,7 +126,7 @@ func (h *AuthHandler) RegisterHandler(c *gin.Context) {
 // @Failure 500 {object} gin.H
 // @Router /auth/user/login [post]
 func (h *AuthHandler) LoginHandler(c *gin.Context) {
-	h.logger.Info("LoginHandler called")
+	h.logger.InfoContext(c.Request.Context(), "LoginHandler called")
 	var req pb.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -86,7 +139,8 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 		return
 	}
 
-	c.IndentedJSON(200, resp)
+	h.registerRole(c, resp.AccessToken)
+	h.completeLogin(c, resp)
 }
 
 // LogoutHandler godoc
@@ -101,7 +155,7 @@ func (h *AuthHandler) LoginHandler(c *gin.Context) {
 // @Failure 500 {object} gin.H
 // @Router /auth/user/logout [post]
 func (h *AuthHandler) LogoutHandler(c *gin.Context) {
-	h.logger.Info("LogoutHandler called")
+	h.logger.InfoContext(c.Request.Context(), "LogoutHandler called")
 	var req pb.LogoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -113,13 +167,14 @@ func (h *AuthHandler) LogoutHandler(c *gin.Context) {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	h.endCurrentSession(c)
 
 	c.IndentedJSON(200, resp)
 }
 
 // AdminLoginHandler godoc
 // @Summary Admin login
-// @Description Log in an admin user with email and password
+// @Description Log in an admin user with email and password. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.
 // @Tags Admin Auth
 // @Accept json
 // @Produce json
@@ -129,7 +184,7 @@ func (h *AuthHandler) LogoutHandler(c *gin.Context) {
 // @Failure 500 {object} gin.H
 // @Router /auth/admin/login [post]
 func (h *AuthHandler) AdminLoginHandler(c *gin.Context) {
-	h.logger.Info("AdminLoginHandler called")
+	h.logger.InfoContext(c.Request.Context(), "AdminLoginHandler called")
 	var req pb.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -142,7 +197,8 @@ func (h *AuthHandler) AdminLoginHandler(c *gin.Context) {
 		return
 	}
 
-	c.IndentedJSON(200, resp)
+	h.registerRole(c, resp.AccessToken)
+	h.completeLogin(c, resp)
 }
 
 // AdminLogoutHandler godoc
@@ -157,7 +213,7 @@ func (h *AuthHandler) AdminLoginHandler(c *gin.Context) {
 // @Failure 500 {object} gin.H
 // @Router /auth/admin/logout [post]
 func (h *AuthHandler) AdminLogoutHandler(c *gin.Context) {
-	h.logger.Info("AdminLogoutHandler called")
+	h.logger.InfoContext(c.Request.Context(), "AdminLogoutHandler called")
 	var req pb.LogoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -169,34 +225,56 @@ func (h *AuthHandler) AdminLogoutHandler(c *gin.Context) {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	h.endCurrentSession(c)
 
 	c.IndentedJSON(200, resp)
 }
 
 // UpdateUserHandler godoc
 // @Summary Update user
-// @Description Update a user's information by admin
+// @Description Change a user's email address. The role, active state and password cannot be set here; use the role, activate/deactivate and password reset endpoints. Only superadmins can update admins and superadmins.
 // @Tags Admin Auth
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Param request body pb.UpdateUserRequest true "Update User Request"
+// @Param request body models.UpdateUserRequest true "New email; user_id may be omitted and must match the path if given"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
 // @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /admin/update/{id} [put]
 func (h *AuthHandler) UpdateUserHandler(c *gin.Context) {
-	h.logger.Info("UpdateUserHandler called")
-	var req pb.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	h.logger.InfoContext(c.Request.Context(), "UpdateUserHandler called")
+	var req models.UpdateUserRequest
+	// Unknown fields are refused so that a role, is_active or password in
+	// the body is an error rather than silently dropped.
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	if !bindPathID(c, &req.UserID) {
+		return
+	}
+	if req.Email == "" {
+		c.IndentedJSON(400, gin.H{"error": "email is required"})
+		return
+	}
+	entry := audit.Entry{Action: "user.update", Resource: "user", ResourceID: req.UserID, After: &req}
+	if _, ok := h.checkStaffTarget(c, req.UserID, entry, errStaffAction); !ok {
+		return
+	}
 
-	_, err := h.auth.UpdateUser(c.Request.Context(), &req)
-	if err != nil {
-		c.IndentedJSON(500, gin.H{"error": err.Error()})
+	err := h.updateUser(c.Request.Context(), req.UserID, func(update *pb.UpdateUserRequest) { update.Email = req.Email })
+	h.audit.Log(c, entry, err)
+	switch {
+	case errors.Is(err, rbac.ErrNoRole):
+		c.IndentedJSON(409, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		upstreamError(c, err, "UpdateUser")
 		return
 	}
 
@@ -205,26 +283,38 @@ func (h *AuthHandler) UpdateUserHandler(c *gin.Context) {
 
 // DeleteUserHandler godoc
 // @Summary Delete user
-// @Description Soft delete a user by admin
+// @Description Soft delete a user by admin. Requires a recent login or an X-Elevation-Token. Only superadmins can delete admins and superadmins.
 // @Tags Admin Auth
 // @Accept json
 // @Produce json
 // @Param id path string true "User ID"
-// @Param request body pb.DeleteUserRequest true "Delete User Request"
+// @Param request body pb.DeleteUserRequest false "Optional; user_id must match the path if given"
+// @Param X-Elevation-Token header string false "Token from /auth/elevate"
 // @Success 200 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
 // @Failure 403 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /admin/delete/{id} [delete]
 func (h *AuthHandler) DeleteUserHandler(c *gin.Context) {
-	h.logger.Info("DeleteUserHandler called")
+	h.logger.InfoContext(c.Request.Context(), "DeleteUserHandler called")
 	var req pb.DeleteUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.IndentedJSON(400, gin.H{"error": err.Error()})
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.IndentedJSON(400, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if !bindPathID(c, &req.UserId) {
+		return
+	}
+	entry := audit.Entry{Action: "user.delete", Resource: "user", ResourceID: req.UserId}
+	if _, ok := h.checkStaffTarget(c, req.UserId, entry, errStaffAction); !ok {
 		return
 	}
 
 	_, err := h.auth.DeleteUser(c.Request.Context(), &req)
+	h.audit.Log(c, entry, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
 		return
@@ -234,7 +324,7 @@ func (h *AuthHandler) DeleteUserHandler(c *gin.Context) {
 }
 
 // @Summary Super Admin Login
-// @Description Login as a super admin
+// @Description Login as a super admin. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.
 // @Tags Super Admin
 // @Accept json
 // @Produce json
@@ -244,7 +334,7 @@ func (h *AuthHandler) DeleteUserHandler(c *gin.Context) {
 // @Failure 500 {object} gin.H
 // @Router /auth/superadmin/login [post]
 func (h *AuthHandler) SuperAdminLoginHandler(c *gin.Context) {
-	h.logger.Info("SuperAdminLoginHandler called")
+	h.logger.InfoContext(c.Request.Context(), "SuperAdminLoginHandler called")
 	var req pb.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -257,7 +347,8 @@ func (h *AuthHandler) SuperAdminLoginHandler(c *gin.Context) {
 		return
 	}
 
-	c.IndentedJSON(200, resp)
+	h.registerRole(c, resp.AccessToken)
+	h.completeLogin(c, resp)
 }
 
 // @Summary Super Admin Logout
@@ -271,7 +362,7 @@ func (h *AuthHandler) SuperAdminLoginHandler(c *gin.Context) {
 // @Failure 500 {object} gin.H
 // @Router /auth/superadmin/logout [post]
 func (h *AuthHandler) SuperAdminLogoutHandler(c *gin.Context) {
-	h.logger.Info("SuperAdminLogoutHandler called")
+	h.logger.InfoContext(c.Request.Context(), "SuperAdminLogoutHandler called")
 	var req pb.LogoutRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
@@ -283,29 +374,41 @@ func (h *AuthHandler) SuperAdminLogoutHandler(c *gin.Context) {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
 		return
 	}
+	h.endCurrentSession(c)
 
 	c.IndentedJSON(200, resp)
 }
 
 // @Summary Create Admin
-// @Description Create a new admin user by a super admin
+// @Description Create a new admin user by a super admin. Requires a recent login or an X-Elevation-Token.
 // @Tags Super Admin
 // @Accept json
 // @Produce json
 // @Param createAdminRequest body pb.CreateAdminRequest true "Create Admin Request"
+// @Param X-Elevation-Token header string false "Token from /auth/elevate"
 // @Success 201 {object} gin.H
 // @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
 // @Failure 500 {object} gin.H
 // @Router /superadmin/createadmin [post]
 func (h *AuthHandler) SuperAdminCreateAdminHandler(c *gin.Context) {
-	h.logger.Info("SuperAdminCreateAdminHandler called")
+	h.logger.InfoContext(c.Request.Context(), "SuperAdminCreateAdminHandler called")
 	var req pb.CreateAdminRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
 		return
 	}
 
+	// The auth service makes the user an admin; record that whether or not
+	// the registry knew their role before.
 	_, err := h.auth.CreateAdmin(c.Request.Context(), &req)
+	if err == nil {
+		err = h.roles.Ensure(c.Request.Context(), req.UserId, rbac.RoleAdmin)
+	}
+	if err == nil {
+		_, err = h.roles.Assign(c.Request.Context(), req.UserId, rbac.RoleAdmin)
+	}
+	h.audit.Log(c, audit.Entry{Action: "admin.create", Resource: "user", ResourceID: req.UserId, After: &req}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
 		return
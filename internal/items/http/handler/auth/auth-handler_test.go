@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/msgbroker"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/alicebob/miniredis/v2"
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestAuthHandler returns a handler backed by an in-memory Redis, a
+// real role registry and auditor, and fakeAuthClient in place of the auth
+// service. Nothing listens on its broker's address, so every publish
+// fails with msgbroker.ErrQueued.
+func newTestAuthHandler(t *testing.T) *AuthHandler {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "test-secret"
+	cfg.JWT.AccessTokenTTL = time.Hour
+	cfg.Audit.File = filepath.Join(t.TempDir(), "audit.log")
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	broker := msgbroker.NewMsgBroker(&kafka.Writer{Addr: kafka.TCP("127.0.0.1:1"), MaxAttempts: 1}, logger)
+	auditor, err := audit.New(cfg, broker, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		auditor.Close(ctx)
+		broker.Close(ctx)
+	})
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	store := redisservice.New(client, logger, time.Minute)
+
+	enforcer, err := casbin.NewSyncedEnforcer(
+		filepath.Join("..", "..", "..", "casbin", "model.conf"),
+		filepath.Join("..", "..", "..", "casbin", "policy.csv"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &AuthHandler{
+		auth:   &fakeAuthClient{users: map[string]string{}},
+		redis:  store,
+		logger: logger,
+		audit:  auditor,
+		broker: broker,
+		roles:  rbac.New(enforcer, store),
+		config: cfg,
+	}
+}
+
+func testToken(t *testing.T, cfg *config.Config, userId, role string) string {
+	t.Helper()
+	token, err := middleware.SignToken(jwt.MapClaims{
+		"user_id": userId,
+		"role":    role,
+		"iat":     time.Now().Unix(),
+		"exp":     time.Now().Add(time.Hour).Unix(),
+	}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// fakeAuthClient is the auth service, with users by email. Methods the
+// tests do not use panic.
+type fakeAuthClient struct {
+	pb.AuthServiceClient
+
+	mu         sync.Mutex
+	users      map[string]string
+	registered []string
+	updates    []*pb.UpdateUserRequest
+	deleted    []string
+	deleteErr  error
+}
+
+func (f *fakeAuthClient) GetUserByEmail(ctx context.Context, in *pb.GetUserByEmailRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	userId, ok := f.users[in.Email]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "user not found")
+	}
+	return &pb.RegisterResponse{UserId: userId}, nil
+}
+
+func (f *fakeAuthClient) Register(ctx context.Context, in *pb.RegisterRequest, opts ...grpc.CallOption) (*pb.RegisterResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	userId := "user-" + in.Email
+	f.users[in.Email] = userId
+	f.registered = append(f.registered, in.Email)
+	return &pb.RegisterResponse{UserId: userId}, nil
+}
+
+func (f *fakeAuthClient) UpdateUser(ctx context.Context, in *pb.UpdateUserRequest, opts ...grpc.CallOption) (*pb.UpdateUserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.updates = append(f.updates, in)
+	return &pb.UpdateUserResponse{}, nil
+}
+
+func (f *fakeAuthClient) Logout(ctx context.Context, in *pb.LogoutRequest, opts ...grpc.CallOption) (*pb.LogoutResponse, error) {
+	return &pb.LogoutResponse{}, nil
+}
+
+func (f *fakeAuthClient) DeleteUser(ctx context.Context, in *pb.DeleteUserRequest, opts ...grpc.CallOption) (*pb.DeleteUserResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.deleteErr != nil {
+		return nil, f.deleteErr
+	}
+	f.deleted = append(f.deleted, in.UserId)
+	return &pb.DeleteUserResponse{}, nil
+}
@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var errStaffSelfDelete = errors.New("admin and superadmin accounts cannot be deleted this way")
+
+// DeleteMeHandler godoc
+// @Summary Delete own account
+// @Security BearerAuth
+// @Description Schedule the caller's account and budgeting data for deletion after the grace period. All sessions and personal access tokens are revoked at once; logging in again before the deletion is due cancels it. Requires a recent login or an X-Elevation-Token.
+// @Tags User Auth
+// @Produce json
+// @Param X-Elevation-Token header string false "Token from /auth/elevate"
+// @Success 202 {object} models.AccountDeletionResponse
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/me [delete]
+func (h *AuthHandler) DeleteMeHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "DeleteMeHandler called")
+	userId := middleware.GetUser_id(c, h.config)
+	now := time.Now()
+	deletion := redisservice.Deletion{UserID: userId, RequestedAt: now, DueAt: now.Add(h.config.Deletion.GracePeriod)}
+	entry := audit.Entry{Action: "user.deletion_schedule", Resource: "user", ResourceID: userId, After: gin.H{"due_at": deletion.DueAt.UTC()}}
+
+	role, err := h.roles.RoleOf(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	// A user with no role in the registry may be staff who have not
+	// logged in since it was introduced, possibly the last superadmin.
+	switch role {
+	case rbac.RoleUser:
+	case "":
+		h.audit.Log(c, entry, rbac.ErrNoRole)
+		c.IndentedJSON(409, gin.H{"error": rbac.ErrNoRole.Error()})
+		return
+	default:
+		h.audit.Log(c, entry, errStaffSelfDelete)
+		c.IndentedJSON(403, gin.H{"error": errStaffSelfDelete.Error()})
+		return
+	}
+
+	err = h.redis.ScheduleDeletion(c.Request.Context(), deletion)
+	if err == nil {
+		err = h.revokeSessions(c, userId)
+	}
+	if err == nil {
+		err = h.revokePersonalTokens(c.Request.Context(), userId)
+	}
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(202, models.AccountDeletionResponse{ScheduledFor: deletion.DueAt.UTC()})
+}
+
+func (h *AuthHandler) revokePersonalTokens(ctx context.Context, userId string) error {
+	tokens, err := h.redis.ListPersonalTokens(ctx, userId)
+	if err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if _, err := h.redis.RevokePersonalToken(ctx, userId, token.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelDeletion cancels the pending deletion of a user who has just
+// logged in.
+func (h *AuthHandler) cancelDeletion(c *gin.Context, userId string) error {
+	cancelled, err := h.redis.CancelDeletion(c.Request.Context(), userId)
+	if err != nil || !cancelled {
+		return err
+	}
+	h.audit.Log(c, audit.Entry{Action: "user.deletion_cancel", Resource: "user", ResourceID: userId}, nil)
+	return nil
+}
+
+// DeleteDueUsers deletes every user whose grace period has ended: first
+// their budgeting data through eraseData, then the user in the auth
+// service and the gateway's own records. A deletion that fails is retried
+// after deletion.retry_after.
+func (h *AuthHandler) DeleteDueUsers(ctx context.Context, eraseData func(ctx context.Context, userId string) error) error {
+	var errs []error
+	for {
+		userId, err := h.redis.ClaimDueDeletion(ctx, time.Now(), h.config.Deletion.RetryAfter)
+		if err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+		if userId == "" {
+			return errors.Join(errs...)
+		}
+		if err := h.deleteUser(ctx, userId, eraseData); err != nil {
+			errs = append(errs, err)
+		}
+	}
+}
+
+func (h *AuthHandler) deleteUser(ctx context.Context, userId string, eraseData func(ctx context.Context, userId string) error) error {
+	deletion, err := h.redis.GetDeletion(ctx, userId)
+	if err == nil {
+		err = eraseData(ctx, userId)
+	}
+	if err == nil {
+		// A retry may find the user already deleted.
+		_, err = h.auth.DeleteUser(ctx, &pb.DeleteUserRequest{UserId: userId})
+		if status.Code(err) == codes.NotFound {
+			err = nil
+		}
+	}
+	if err == nil {
+		err = h.redis.ForgetUser(ctx, userId)
+	}
+	var body []byte
+	if err == nil {
+		body, err = json.Marshal(models.UserDeletedEvent{
+			UserID:      userId,
+			RequestedAt: deletion.RequestedAt.UTC(),
+			DeletedAt:   time.Now().UTC(),
+		})
+	}
+	if err == nil {
+		err = h.broker.UserDeleted(ctx, body)
+	}
+	h.audit.LogSystem(ctx, audit.Entry{Action: "user.delete", Resource: "user", ResourceID: userId, Before: gin.H{"requested_at": deletion.RequestedAt.UTC()}}, err)
+	return err
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/msgbroker"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDeleteMeHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		role   string
+		status int
+	}{
+		{"user", rbac.RoleUser, http.StatusAccepted},
+		{"admin", rbac.RoleAdmin, http.StatusForbidden},
+		{"superadmin", rbac.RoleSuperadmin, http.StatusForbidden},
+		{"no recorded role", "", http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			ctx := context.Background()
+			if tt.role != "" {
+				if err := h.roles.Ensure(ctx, "caller-1", tt.role); err != nil {
+					t.Fatal(err)
+				}
+			}
+			router := gin.New()
+			router.DELETE("/user/me", h.DeleteMeHandler)
+
+			req := httptest.NewRequest(http.MethodDelete, "/user/me", nil)
+			// Whatever role the token claims, the registry decides.
+			req.Header.Set("Authorization", testToken(t, h.config, "caller-1", rbac.RoleUser))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			_, err := h.redis.GetDeletion(ctx, "caller-1")
+			if scheduled := err == nil; scheduled != (tt.status == http.StatusAccepted) {
+				t.Fatalf("GetDeletion error = %v with status %d", err, rec.Code)
+			}
+			if err != nil && !errors.Is(err, redisservice.ErrTokenNotFound) {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestDeleteDueUsers(t *testing.T) {
+	eraseErr := errors.New("budgeting unavailable")
+
+	tests := []struct {
+		name      string
+		due       time.Duration // from now; positive is still in the grace period
+		cancel    bool
+		eraseErr  error
+		deleteErr error
+		// erased and deleted report whether the budgeting data and the
+		// auth service user were removed.
+		erased, deleted bool
+		forgotten       bool
+		err             error
+	}{
+		{"grace period over", -time.Minute, false, nil, nil, true, true, true, msgbroker.ErrQueued},
+		{"still in the grace period", time.Hour, false, nil, nil, false, false, false, nil},
+		{"cancelled", -time.Minute, true, nil, nil, false, false, false, nil},
+		{"erasing budgeting data fails", -time.Minute, false, eraseErr, nil, false, false, false, eraseErr},
+		{"user already gone from the auth service", -time.Minute, false, nil, status.Error(codes.NotFound, "user not found"), true, false, true, msgbroker.ErrQueued},
+		{"auth service fails", -time.Minute, false, nil, status.Error(codes.Unavailable, "unavailable"), true, false, false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			auth := h.auth.(*fakeAuthClient)
+			auth.deleteErr = tt.deleteErr
+			ctx := context.Background()
+
+			if err := h.roles.Ensure(ctx, "user-1", rbac.RoleUser); err != nil {
+				t.Fatal(err)
+			}
+			now := time.Now()
+			if err := h.redis.ScheduleDeletion(ctx, redisservice.Deletion{UserID: "user-1", RequestedAt: now, DueAt: now.Add(tt.due)}); err != nil {
+				t.Fatal(err)
+			}
+			if tt.cancel {
+				if _, err := h.redis.CancelDeletion(ctx, "user-1"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var erased []string
+			err := h.DeleteDueUsers(ctx, func(ctx context.Context, userId string) error {
+				if tt.eraseErr != nil {
+					return tt.eraseErr
+				}
+				erased = append(erased, userId)
+				return nil
+			})
+			switch {
+			case tt.err != nil && !errors.Is(err, tt.err):
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			case tt.err == nil && (err == nil) != (tt.deleteErr == nil):
+				t.Fatalf("err = %v, want it to report the auth service error %v", err, tt.deleteErr)
+			}
+
+			if got := slices.Contains(erased, "user-1"); got != tt.erased {
+				t.Errorf("erased = %v, want %v", got, tt.erased)
+			}
+			if got := slices.Contains(auth.deleted, "user-1"); got != tt.deleted {
+				t.Errorf("deleted = %v, want %v", got, tt.deleted)
+			}
+			role, err := h.roles.RoleOf(ctx, "user-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if forgotten := role == ""; forgotten != tt.forgotten {
+				t.Errorf("role = %q, want forgotten %v", role, tt.forgotten)
+			}
+
+			// A failed deletion is held for retry_after, not retried at once.
+			if !tt.forgotten && tt.due < 0 && !tt.cancel {
+				if _, err := h.redis.GetDeletion(ctx, "user-1"); err != nil {
+					t.Fatalf("GetDeletion = %v, want the deletion kept for retry", err)
+				}
+				claimed, err := h.redis.ClaimDueDeletion(ctx, time.Now(), time.Minute)
+				if err != nil || claimed != "" {
+					t.Fatalf("ClaimDueDeletion = %q, %v, want nothing due before retry_after", claimed, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+var (
+	errImpersonateStaff = errors.New("admins and superadmins cannot be impersonated")
+	errImpersonateWrite = errors.New("only superadmins can allow writes while impersonating")
+	errEndImpersonation = errors.New("only the admin who started an impersonation or a superadmin can end it")
+)
+
+// ImpersonateHandler godoc
+// @Summary Impersonate a user
+// @Security BearerAuth
+// @Description Get a short-lived token that acts as the user on the budgeting routes under /user. The token is read-only unless a superadmin sets allow_write, responses carry X-Impersonated-User and X-Impersonator, and every request made with it is audited with both identities. Requires a recent login or an X-Elevation-Token.
+// @Tags Admin Users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.ImpersonateRequest true "Reason, duration and write access"
+// @Param X-Elevation-Token header string false "Token from /auth/elevate"
+// @Success 201 {object} models.ImpersonationResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/impersonate [post]
+func (h *AuthHandler) ImpersonateHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ImpersonateHandler called")
+	var req models.ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := c.Param("id")
+	adminId := middleware.GetUser_id(c, h.config)
+	entry := audit.Entry{Action: "impersonation.start", Resource: "user", ResourceID: userId, After: &req}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		c.IndentedJSON(400, gin.H{"error": "reason is required"})
+		return
+	}
+	ttl := h.config.Impersonation.DefaultTTL
+	if req.DurationMinutes != 0 {
+		ttl = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if ttl <= 0 || ttl > h.config.Impersonation.MaxTTL {
+		c.IndentedJSON(400, gin.H{"error": fmt.Sprintf("duration must be positive and at most %s", h.config.Impersonation.MaxTTL)})
+		return
+	}
+	if userId == adminId {
+		h.audit.Log(c, entry, errSelfAction)
+		c.IndentedJSON(400, gin.H{"error": errSelfAction.Error()})
+		return
+	}
+
+	adminRole, err := h.roles.RoleOf(c.Request.Context(), adminId)
+	var targetRole string
+	if err == nil {
+		targetRole, err = h.roles.RoleOf(c.Request.Context(), userId)
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if adminRole == "" {
+		adminRole = middleware.GetRole(c, h.config)
+	}
+	// A user with no role in the registry may be staff who have not
+	// logged in yet.
+	if targetRole == "" {
+		h.audit.Log(c, entry, rbac.ErrNoRole)
+		c.IndentedJSON(409, gin.H{"error": rbac.ErrNoRole.Error()})
+		return
+	}
+	if targetRole != rbac.RoleUser {
+		h.audit.Log(c, entry, errImpersonateStaff)
+		c.IndentedJSON(403, gin.H{"error": errImpersonateStaff.Error()})
+		return
+	}
+	if req.AllowWrite && adminRole != rbac.RoleSuperadmin {
+		h.audit.Log(c, entry, errImpersonateWrite)
+		c.IndentedJSON(403, gin.H{"error": errImpersonateWrite.Error()})
+		return
+	}
+
+	allScopes, err := h.roles.Scopes()
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	scopes := []string{}
+	for _, scope := range allScopes {
+		if req.AllowWrite || strings.HasSuffix(scope, ":read") {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	id, err := oidc.RandomString(16)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	err = h.redis.StartImpersonation(c.Request.Context(), redisservice.Impersonation{
+		ID:         id,
+		AdminID:    adminId,
+		UserID:     userId,
+		Reason:     req.Reason,
+		AllowWrite: req.AllowWrite,
+		ExpiresAt:  expiresAt,
+	})
+	var token string
+	if err == nil {
+		token, err = middleware.SignToken(jwt.MapClaims{
+			"jti":     id,
+			"sub":     userId,
+			"user_id": userId,
+			"role":    rbac.RoleUser,
+			"act":     map[string]string{"sub": adminId, "role": adminRole},
+			"imp_id":  id,
+			"scope":   strings.Join(scopes, " "),
+			"iat":     now.Unix(),
+			"exp":     expiresAt.Unix(),
+		}, h.config)
+	}
+	entry.After = gin.H{"impersonation_id": id, "reason": req.Reason, "allow_write": req.AllowWrite, "expires_at": expiresAt.UTC()}
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(201, models.ImpersonationResponse{
+		ID:          id,
+		AccessToken: token,
+		UserID:      userId,
+		ReadOnly:    !req.AllowWrite,
+		ExpiresAt:   expiresAt.UTC(),
+	})
+}
+
+// EndImpersonationHandler godoc
+// @Summary End an impersonation
+// @Security BearerAuth
+// @Description End an impersonation before it expires; its token is rejected from then on. Only the admin who started it or a superadmin can end it.
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "Impersonation ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/impersonations/{id} [delete]
+func (h *AuthHandler) EndImpersonationHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "EndImpersonationHandler called")
+	id := c.Param("id")
+	entry := audit.Entry{Action: "impersonation.end", Resource: "impersonation", ResourceID: id}
+
+	adminId, err := h.redis.ImpersonationAdmin(c.Request.Context(), id)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if adminId == "" {
+		c.IndentedJSON(404, gin.H{"error": "Impersonation not found or already ended"})
+		return
+	}
+	if adminId != middleware.GetUser_id(c, h.config) && middleware.GetRole(c, h.config) != rbac.RoleSuperadmin {
+		h.audit.Log(c, entry, errEndImpersonation)
+		c.IndentedJSON(403, gin.H{"error": errEndImpersonation.Error()})
+		return
+	}
+
+	ended, err := h.redis.EndImpersonation(c.Request.Context(), id)
+	if err != nil {
+		h.audit.Log(c, entry, err)
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !ended {
+		c.IndentedJSON(404, gin.H{"error": "Impersonation not found or already ended"})
+		return
+	}
+	h.audit.Log(c, entry, nil)
+
+	c.IndentedJSON(200, gin.H{"message": "Impersonation ended"})
+}
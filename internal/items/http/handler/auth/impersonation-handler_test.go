@@ -0,0 +1,121 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestImpersonateHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		targetRole string
+		callerRole string
+		allowWrite bool
+		status     int
+	}{
+		{"user", rbac.RoleUser, rbac.RoleAdmin, false, http.StatusCreated},
+		{"admin", rbac.RoleAdmin, rbac.RoleAdmin, false, http.StatusForbidden},
+		{"superadmin", rbac.RoleSuperadmin, rbac.RoleSuperadmin, false, http.StatusForbidden},
+		{"user with no recorded role", "", rbac.RoleSuperadmin, false, http.StatusConflict},
+		{"writes by an admin", rbac.RoleUser, rbac.RoleAdmin, true, http.StatusForbidden},
+		{"writes by a superadmin", rbac.RoleUser, rbac.RoleSuperadmin, true, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			ctx := context.Background()
+			if err := h.roles.Ensure(ctx, "caller-1", tt.callerRole); err != nil {
+				t.Fatal(err)
+			}
+			if tt.targetRole != "" {
+				if err := h.roles.Ensure(ctx, "target-1", tt.targetRole); err != nil {
+					t.Fatal(err)
+				}
+			}
+			router := gin.New()
+			router.POST("/admin/users/:id/impersonate", h.ImpersonateHandler)
+
+			token := testToken(t, h.config, "caller-1", tt.callerRole)
+			rec := postJSON(router, "/admin/users/target-1/impersonate", token, models.ImpersonateRequest{Reason: "ticket 1", AllowWrite: tt.allowWrite})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if rec.Code != http.StatusCreated {
+				return
+			}
+			var resp models.ImpersonationResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.ReadOnly == tt.allowWrite {
+				t.Errorf("read_only = %v with allow_write %v", resp.ReadOnly, tt.allowWrite)
+			}
+			if active, err := h.redis.ImpersonationActive(ctx, resp.ID); err != nil || !active {
+				t.Errorf("ImpersonationActive = %v, %v; want true", active, err)
+			}
+		})
+	}
+}
+
+func TestEndImpersonationHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		id         string
+		callerId   string
+		callerRole string
+		status     int
+	}{
+		{"admin who started it", "imp-1", "admin-1", rbac.RoleAdmin, http.StatusOK},
+		{"another admin", "imp-1", "admin-2", rbac.RoleAdmin, http.StatusForbidden},
+		{"superadmin", "imp-1", "superadmin-1", rbac.RoleSuperadmin, http.StatusOK},
+		{"unknown impersonation", "imp-2", "admin-1", rbac.RoleAdmin, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			ctx := context.Background()
+			err := h.redis.StartImpersonation(ctx, redisservice.Impersonation{
+				ID:        "imp-1",
+				AdminID:   "admin-1",
+				UserID:    "user-1",
+				Reason:    "ticket 1",
+				ExpiresAt: time.Now().Add(time.Hour),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			router := gin.New()
+			router.DELETE("/admin/impersonations/:id", h.EndImpersonationHandler)
+
+			req := httptest.NewRequest(http.MethodDelete, "/admin/impersonations/"+tt.id, nil)
+			req.Header.Set("Authorization", testToken(t, h.config, tt.callerId, tt.callerRole))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			active, err := h.redis.ImpersonationActive(ctx, "imp-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := tt.status != http.StatusOK; active != want {
+				t.Errorf("imp-1 active = %v, want %v", active, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,513 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/secretbox"
+	"gateway-service/internal/pkg/signedtoken"
+	"gateway-service/internal/pkg/totp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const mfaPurpose = "mfa"
+
+var (
+	errInvalidMFACode = errors.New("invalid two-factor code")
+	errMFARequired    = errors.New("two-factor authentication is required for superadmins")
+	errMFALocked      = errors.New("too many failed attempts, please try again later")
+)
+
+// mfaSecret returns the TOTP secret of userId, or "" if 2FA is off.
+func (h *AuthHandler) mfaSecret(c *gin.Context, userId string) (string, error) {
+	sealed, err := h.redis.GetMFASecret(c.Request.Context(), userId)
+	if err != nil || sealed == "" {
+		return "", err
+	}
+	secret, err := secretbox.Open(h.config.MFAKey(), sealed)
+	return string(secret), err
+}
+
+// checkMFACode accepts a TOTP code for secret that has not been used yet
+// or, if allowRecovery is set, one of the user's recovery codes, which is
+// then used up.
+func (h *AuthHandler) checkMFACode(c *gin.Context, userId, secret, code string, allowRecovery bool) (bool, error) {
+	code = strings.TrimSpace(code)
+	if step, ok := totp.Validate(secret, code, time.Now()); ok {
+		return h.redis.AcceptMFAStep(c.Request.Context(), userId, step, 2*time.Minute)
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+	return h.redis.UseRecoveryCode(c.Request.Context(), userId, hashRecoveryCode(code))
+}
+
+// mfaLocked reports whether userId is locked out after too many failed
+// second factors. If it returns true the response has been written.
+func (h *AuthHandler) mfaLocked(c *gin.Context, userId string) bool {
+	wait, err := h.redis.MFALockout(c.Request.Context(), userId, h.config.MFA.LockoutAttempts)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return true
+	}
+	if wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.IndentedJSON(429, gin.H{"error": errMFALocked.Error()})
+		return true
+	}
+	return false
+}
+
+// failMFA counts a failed second factor of userId towards the lockout,
+// which unlike MFA.MaxAttempts outlives the login challenge.
+func (h *AuthHandler) failMFA(c *gin.Context, userId string) {
+	failures, err := h.redis.FailMFA(c.Request.Context(), userId, h.config.MFA.LockoutAttempts, h.config.MFA.Lockout)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to count a failed second factor", "user_id", userId, "error", err)
+		return
+	}
+	if failures == int64(h.config.MFA.LockoutAttempts) {
+		h.logger.WarnContext(c.Request.Context(), "Second factor locked after too many failures", "user_id", userId)
+	}
+}
+
+// passMFA clears the failures of userId after a valid second factor.
+func (h *AuthHandler) passMFA(c *gin.Context, userId string) {
+	if err := h.redis.ClearMFAFailures(c.Request.Context(), userId); err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Failed to clear failed second factors", "user_id", userId, "error", err)
+	}
+}
+
+func newRecoveryCodes(n int) (codes, hashes []string, err error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for range n {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(b))
+		code = code[:4] + "-" + code[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// mfaRequired reports whether userId must use 2FA, which superadmins do.
+func (h *AuthHandler) mfaRequired(ctx context.Context, userId, role string) bool {
+	if assigned, err := h.roles.RoleOf(ctx, userId); err == nil && assigned != "" {
+		role = assigned
+	}
+	return role == rbac.RoleSuperadmin
+}
+
+// completeLogin answers a successful login. Users with 2FA get a
+// challenge token instead, and their tokens are held until
+// VerifyMFAHandler receives a valid code.
+func (h *AuthHandler) completeLogin(c *gin.Context, resp *pb.LoginResponse) {
+	userId, _ := middleware.ParseToken(resp.AccessToken, h.config)["user_id"].(string)
+	if userId == "" {
+		c.IndentedJSON(200, resp)
+		return
+	}
+
+	sealed, err := h.redis.GetMFASecret(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if sealed == "" {
+		h.releaseTokens(c, resp)
+		return
+	}
+
+	tokens, err := json.Marshal(resp)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	sealedTokens, err := secretbox.Seal(h.config.MFAKey(), tokens)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	expiresAt := time.Now().Add(h.config.MFA.ChallengeTTL)
+	challenge, id, err := signedtoken.New(h.config.JWTSecrets()[0], mfaPurpose, expiresAt)
+	if err == nil {
+		err = h.redis.StoreMFAChallenge(c.Request.Context(), id, redisservice.MFAChallenge{UserID: userId, Tokens: sealedTokens}, h.config.MFA.ChallengeTTL)
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.MFAChallengeResponse{MFARequired: true, ChallengeToken: challenge, ExpiresAt: expiresAt.UTC()})
+}
+
+// VerifyMFAHandler godoc
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge token from the login response and a TOTP or recovery code for the access and refresh tokens
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body models.MFAVerifyRequest true "Challenge token and code"
+// @Success 200 {object} pb.LoginResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFAHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "VerifyMFAHandler called")
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := signedtoken.Parse(req.ChallengeToken, mfaPurpose, h.config.JWTSecrets(), time.Now())
+	if err != nil {
+		c.IndentedJSON(401, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+	challenge, err := h.redis.GetMFAChallenge(c.Request.Context(), id)
+	if errors.Is(err, redisservice.ErrTokenNotFound) {
+		c.IndentedJSON(401, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if h.mfaLocked(c, challenge.UserID) {
+		return
+	}
+
+	secret, err := h.mfaSecret(c, challenge.UserID)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	ok := false
+	if secret != "" {
+		ok, err = h.checkMFACode(c, challenge.UserID, secret, req.Code, true)
+		if err != nil {
+			c.IndentedJSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if !ok {
+		h.failMFA(c, challenge.UserID)
+		attempts, err := h.redis.FailMFAChallenge(c.Request.Context(), id)
+		if err == nil && attempts >= int64(h.config.MFA.MaxAttempts) {
+			h.redis.EndMFAChallenge(c.Request.Context(), id)
+			h.logger.WarnContext(c.Request.Context(), "Two-factor login locked after too many attempts", "user_id", challenge.UserID)
+		}
+		c.IndentedJSON(401, gin.H{"error": errInvalidMFACode.Error()})
+		return
+	}
+	h.passMFA(c, challenge.UserID)
+
+	ended, err := h.redis.EndMFAChallenge(c.Request.Context(), id)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !ended {
+		c.IndentedJSON(401, gin.H{"error": "Invalid or expired challenge token"})
+		return
+	}
+
+	tokens, err := secretbox.Open(h.config.MFAKey(), challenge.Tokens)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	var resp pb.LoginResponse
+	if err := json.Unmarshal(tokens, &resp); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.releaseTokens(c, &resp)
+}
+
+// MFAStatusHandler godoc
+// @Summary Two-factor status
+// @Security BearerAuth
+// @Description Show whether two-factor authentication is enabled or required for the logged in user
+// @Tags MFA
+// @Produce json
+// @Success 200 {object} models.MFAStatusResponse
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/mfa [get]
+func (h *AuthHandler) MFAStatusHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "MFAStatusHandler called")
+	userId := middleware.GetUser_id(c, h.config)
+
+	sealed, err := h.redis.GetMFASecret(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	left, err := h.redis.RecoveryCodesLeft(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.MFAStatusResponse{
+		Enabled:           sealed != "",
+		Required:          h.mfaRequired(c.Request.Context(), userId, middleware.GetRole(c, h.config)),
+		RecoveryCodesLeft: left,
+	})
+}
+
+// EnrollMFAHandler godoc
+// @Summary Start two-factor enrollment
+// @Security BearerAuth
+// @Description Create a TOTP secret. Add it to an authenticator app, usually by rendering otpauth_uri as a QR code, then confirm it with /auth/mfa/activate.
+// @Tags MFA
+// @Produce json
+// @Success 200 {object} models.MFAEnrollResponse
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFAHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "EnrollMFAHandler called")
+	userId := middleware.GetUser_id(c, h.config)
+
+	current, err := h.redis.GetMFASecret(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if current != "" {
+		c.IndentedJSON(409, gin.H{"error": "Two-factor authentication is already enabled"})
+		return
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	sealed, err := secretbox.Seal(h.config.MFAKey(), []byte(secret))
+	if err == nil {
+		err = h.redis.SetPendingMFA(c.Request.Context(), userId, sealed, h.config.MFA.EnrollmentTTL)
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.MFAEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(h.config.MFA.Issuer, userId, secret),
+	})
+}
+
+// ActivateMFAHandler godoc
+// @Summary Activate two-factor authentication
+// @Security BearerAuth
+// @Description Confirm enrollment with a code from the authenticator app. Returns one-time recovery codes, shown only once. Every session is ended so the next login uses two factors.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP code"
+// @Success 200 {object} models.MFARecoveryCodesResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/mfa/activate [post]
+func (h *AuthHandler) ActivateMFAHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ActivateMFAHandler called")
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := middleware.GetUser_id(c, h.config)
+	entry := audit.Entry{Action: "mfa.enable", Resource: "user", ResourceID: userId}
+
+	sealed, err := h.redis.GetPendingMFA(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if sealed == "" {
+		c.IndentedJSON(400, gin.H{"error": "No enrollment in progress; start one with /auth/mfa/enroll"})
+		return
+	}
+	secret, err := secretbox.Open(h.config.MFAKey(), sealed)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if _, ok := totp.Validate(string(secret), strings.TrimSpace(req.Code), time.Now()); !ok {
+		h.audit.Log(c, entry, errInvalidMFACode)
+		c.IndentedJSON(401, gin.H{"error": errInvalidMFACode.Error()})
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes(h.config.MFA.RecoveryCodes)
+	if err == nil {
+		err = h.redis.EnableMFA(c.Request.Context(), userId, sealed, hashes)
+	}
+	if err == nil {
+		err = h.revokeSessions(c, userId)
+	}
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodesHandler godoc
+// @Summary Replace recovery codes
+// @Security BearerAuth
+// @Description Invalidate the remaining recovery codes and return new ones
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP code"
+// @Success 200 {object} models.MFARecoveryCodesResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodesHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "RegenerateRecoveryCodesHandler called")
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := middleware.GetUser_id(c, h.config)
+	entry := audit.Entry{Action: "mfa.recovery_codes", Resource: "user", ResourceID: userId}
+
+	if ok, err := h.confirmMFA(c, userId, req.Code, false); err != nil || !ok {
+		h.audit.Log(c, entry, errInvalidMFACode)
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes(h.config.MFA.RecoveryCodes)
+	if err == nil {
+		err = h.redis.ReplaceRecoveryCodes(c.Request.Context(), userId, hashes)
+	}
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFAHandler godoc
+// @Summary Disable two-factor authentication
+// @Security BearerAuth
+// @Description Turn off two-factor authentication with a TOTP or recovery code. Superadmins cannot disable it.
+// @Tags MFA
+// @Accept json
+// @Produce json
+// @Param request body models.MFACodeRequest true "TOTP or recovery code"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFAHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "DisableMFAHandler called")
+	var req models.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := middleware.GetUser_id(c, h.config)
+	entry := audit.Entry{Action: "mfa.disable", Resource: "user", ResourceID: userId}
+
+	if h.mfaRequired(c.Request.Context(), userId, middleware.GetRole(c, h.config)) {
+		h.audit.Log(c, entry, errMFARequired)
+		c.IndentedJSON(403, gin.H{"error": errMFARequired.Error()})
+		return
+	}
+
+	if ok, err := h.confirmMFA(c, userId, req.Code, true); err != nil || !ok {
+		h.audit.Log(c, entry, errInvalidMFACode)
+		return
+	}
+
+	err := h.redis.DisableMFA(c.Request.Context(), userId)
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Two-factor authentication disabled"})
+}
+
+// confirmMFA checks code for a user with 2FA enabled, counting it towards
+// the lockout. If it returns false the response has been written.
+func (h *AuthHandler) confirmMFA(c *gin.Context, userId, code string, allowRecovery bool) (bool, error) {
+	if h.mfaLocked(c, userId) {
+		return false, nil
+	}
+	secret, err := h.mfaSecret(c, userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return false, err
+	}
+	if secret == "" {
+		c.IndentedJSON(400, gin.H{"error": "Two-factor authentication is not enabled"})
+		return false, nil
+	}
+
+	ok, err := h.checkMFACode(c, userId, secret, code, allowRecovery)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return false, err
+	}
+	if !ok {
+		h.failMFA(c, userId)
+		c.IndentedJSON(401, gin.H{"error": errInvalidMFACode.Error()})
+		return false, nil
+	}
+	h.passMFA(c, userId)
+	return true, nil
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/secretbox"
+	"gateway-service/internal/pkg/signedtoken"
+	"gateway-service/internal/pkg/totp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// wrongCode is never a valid TOTP code, which has only digits.
+const wrongCode = "abcdef"
+
+func postJSON(router *gin.Engine, path, token string, body any) *httptest.ResponseRecorder {
+	b, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// enableTestMFA turns on 2FA for userId with a secret nobody knows.
+func enableTestMFA(t *testing.T, h *AuthHandler, userId string) {
+	t.Helper()
+	h.config.MFA.EncryptionKey = "test-mfa-key"
+	secret, err := totp.NewSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := secretbox.Seal(h.config.MFAKey(), []byte(secret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.redis.EnableMFA(context.Background(), userId, sealed, []string{hashRecoveryCode("abcd-efgh")}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMFALockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newTestAuthHandler(t)
+	h.config.MFA.MaxAttempts = 2
+	h.config.MFA.LockoutAttempts = 3
+	ctx := context.Background()
+
+	enableTestMFA(t, h, "user-1")
+
+	// newChallenge stores a login of user-1 waiting for its second factor,
+	// as completeLogin does.
+	newChallenge := func() string {
+		t.Helper()
+		token, id, err := signedtoken.New(h.config.JWTSecrets()[0], mfaPurpose, time.Now().Add(time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.redis.StoreMFAChallenge(ctx, id, redisservice.MFAChallenge{UserID: "user-1", Tokens: "sealed"}, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	router := gin.New()
+	router.POST("/auth/mfa/verify", h.VerifyMFAHandler)
+	router.POST("/auth/elevate", h.ElevateHandler)
+	verify := func(challenge string) int {
+		return postJSON(router, "/auth/mfa/verify", "", models.MFAVerifyRequest{ChallengeToken: challenge, Code: wrongCode}).Code
+	}
+
+	first := newChallenge()
+	for range h.config.MFA.MaxAttempts {
+		if code := verify(first); code != http.StatusUnauthorized {
+			t.Fatalf("wrong code status = %d, want 401", code)
+		}
+	}
+
+	// A new challenge resets the attempts per challenge, but not those of
+	// the user, which lock it out after the third failure.
+	second := newChallenge()
+	if code := verify(second); code != http.StatusUnauthorized {
+		t.Fatalf("wrong code on a new challenge status = %d, want 401", code)
+	}
+	rec := postJSON(router, "/auth/mfa/verify", "", models.MFAVerifyRequest{ChallengeToken: second, Code: wrongCode})
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status once locked out = %d, want 429: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("no Retry-After once locked out")
+	}
+	if code := verify(newChallenge()); code != http.StatusTooManyRequests {
+		t.Fatalf("status on another challenge once locked out = %d, want 429", code)
+	}
+
+	token := testToken(t, h.config, "user-1", rbac.RoleUser)
+	if rec := postJSON(router, "/auth/elevate", token, models.ElevateRequest{Code: wrongCode}); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("elevate status once locked out = %d, want 429: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestElevateFailuresLockOut(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := newTestAuthHandler(t)
+	h.config.MFA.LockoutAttempts = 2
+
+	enableTestMFA(t, h, "user-1")
+
+	router := gin.New()
+	router.POST("/auth/elevate", h.ElevateHandler)
+	token := testToken(t, h.config, "user-1", rbac.RoleUser)
+
+	for range h.config.MFA.LockoutAttempts {
+		if rec := postJSON(router, "/auth/elevate", token, models.ElevateRequest{Code: wrongCode}); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("wrong code status = %d, want 401: %s", rec.Code, rec.Body)
+		}
+	}
+	if rec := postJSON(router, "/auth/elevate", token, models.ElevateRequest{Code: wrongCode}); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status once locked out = %d, want 429: %s", rec.Code, rec.Body)
+	}
+	if wait, err := h.redis.MFALockout(context.Background(), "user-1", h.config.MFA.LockoutAttempts); err != nil || wait <= 0 || wait > h.config.MFA.Lockout {
+		t.Fatalf("MFALockout = %v, %v; want up to %v", wait, err, h.config.MFA.Lockout)
+	}
+}
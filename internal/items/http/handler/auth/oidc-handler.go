@@ -0,0 +1,285 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"sort"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/oidc"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	errUnverifiedIdentity = errors.New("the identity provider did not confirm an email address for this account")
+	errStaffSocialLogin   = errors.New("admins and superadmins must sign in with their password")
+	errUnknownRoleLogin   = errors.New("sign in with your password once before using an identity provider")
+)
+
+// newOIDCProviders builds the enabled identity providers. GitHub is not an
+// OpenID provider, so its OAuth2 endpoints are fixed here.
+func newOIDCProviders(cfg *config.Config) map[string]*oidc.Provider {
+	providers := make(map[string]*oidc.Provider)
+	for name, p := range cfg.OIDCProviders() {
+		opts := oidc.Options{
+			Name:         name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  cfg.OIDC.CallbackBaseURL + "/" + name + "/callback",
+			Scopes:       p.Scopes,
+		}
+		if name == "github" {
+			opts.Issuer = ""
+			opts.AuthURL = "https://github.com/login/oauth/authorize"
+			opts.TokenURL = "https://github.com/login/oauth/access_token"
+			opts.UserInfoURL = "https://api.github.com/user"
+			opts.EmailsURL = "https://api.github.com/user/emails"
+		}
+		providers[name] = oidc.New(opts)
+	}
+	return providers
+}
+
+// OIDCProvidersHandler godoc
+// @Summary List identity providers
+// @Description List the external identity providers users can sign in with
+// @Tags OIDC
+// @Produce json
+// @Success 200 {object} models.OIDCProvidersResponse
+// @Router /auth/oidc [get]
+func (h *AuthHandler) OIDCProvidersHandler(c *gin.Context) {
+	names := []string{}
+	for name := range h.oidc {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	c.IndentedJSON(200, models.OIDCProvidersResponse{Providers: names})
+}
+
+// OIDCLoginHandler godoc
+// @Summary Sign in with an identity provider
+// @Description Redirect the browser to the provider's login page. The provider sends it back to the callback endpoint.
+// @Tags OIDC
+// @Param provider path string true "Provider name, e.g. google or github"
+// @Success 302
+// @Failure 404 {object} gin.H
+// @Failure 502 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/oidc/{provider}/login [get]
+func (h *AuthHandler) OIDCLoginHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "OIDCLoginHandler called")
+	provider, ok := h.oidc[c.Param("provider")]
+	if !ok {
+		c.IndentedJSON(404, gin.H{"error": "Unknown identity provider"})
+		return
+	}
+
+	login := redisservice.OIDCLogin{Provider: provider.Name()}
+	state, err := oidc.RandomString(32)
+	if err == nil {
+		login.Nonce, err = oidc.RandomString(32)
+	}
+	if err == nil {
+		login.Verifier, err = oidc.RandomString(32)
+	}
+	if err == nil {
+		err = h.redis.StoreOIDCLogin(c.Request.Context(), state, login, h.config.OIDC.StateTTL)
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	target, err := provider.AuthCodeURL(c.Request.Context(), state, login.Nonce, login.Verifier)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "Identity provider unavailable", "provider", provider.Name(), "error", err.Error())
+		c.IndentedJSON(502, gin.H{"error": "Identity provider unavailable"})
+		return
+	}
+	c.Redirect(302, target)
+}
+
+// OIDCCallbackHandler godoc
+// @Summary Complete a sign-in with an identity provider
+// @Description Exchange the code the provider redirected back with for gateway tokens. The identity is linked to the user with the same verified email, or a new user is registered. Admins, superadmins and existing users who have never signed in with their password are refused. With two-factor authentication enabled the response is a models.MFAChallengeResponse to complete at /auth/mfa/verify.
+// @Tags OIDC
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from the login redirect"
+// @Success 200 {object} pb.LoginResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/oidc/{provider}/callback [get]
+func (h *AuthHandler) OIDCCallbackHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "OIDCCallbackHandler called")
+	if reason := c.Query("error"); reason != "" {
+		c.IndentedJSON(401, gin.H{"error": "Sign-in was not completed: " + reason})
+		return
+	}
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		c.IndentedJSON(400, gin.H{"error": "code and state are required"})
+		return
+	}
+
+	login, err := h.redis.TakeOIDCLogin(c.Request.Context(), state)
+	if errors.Is(err, redisservice.ErrTokenNotFound) || (err == nil && login.Provider != c.Param("provider")) {
+		c.IndentedJSON(401, gin.H{"error": "Invalid or expired sign-in state"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	provider, ok := h.oidc[login.Provider]
+	if !ok {
+		c.IndentedJSON(404, gin.H{"error": "Unknown identity provider"})
+		return
+	}
+
+	identity, err := provider.Exchange(c.Request.Context(), code, login.Verifier, login.Nonce)
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Identity provider sign-in failed", "provider", login.Provider, "error", err.Error())
+		c.IndentedJSON(401, gin.H{"error": "Sign-in with the identity provider failed"})
+		return
+	}
+
+	userId, err := h.linkIdentity(c, login.Provider, identity)
+	if errors.Is(err, errUnverifiedIdentity) {
+		c.IndentedJSON(403, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	role, err := h.roles.RoleOf(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	// The gateway signs the token below with the user role, so it must
+	// know that is the user's role. Users the registry has no role for may
+	// be staff who have not logged in with their password yet.
+	switch role {
+	case rbac.RoleUser:
+	case "":
+		c.IndentedJSON(403, gin.H{"error": errUnknownRoleLogin.Error()})
+		return
+	default:
+		c.IndentedJSON(403, gin.H{"error": errStaffSocialLogin.Error()})
+		return
+	}
+	userStatus, err := h.redis.GetUserStatus(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if userStatus.Inactive {
+		c.IndentedJSON(403, gin.H{"error": "Account is deactivated"})
+		return
+	}
+
+	// The auth service only issues tokens for a password, so the gateway
+	// signs the access token itself. There is no refresh token; clients
+	// sign in with the provider again once it expires.
+	jti, err := oidc.RandomString(16)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	now := time.Now()
+	accessToken, err := middleware.SignToken(jwt.MapClaims{
+		"jti":       jti,
+		"user_id":   userId,
+		"role":      rbac.RoleUser,
+		"iat":       now.Unix(),
+		"auth_time": now.Unix(),
+		"exp":       now.Add(h.config.JWT.AccessTokenTTL).Unix(),
+		"amr":       []string{"oidc"},
+		"idp":       login.Provider,
+	}, h.config)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.completeLogin(c, &pb.LoginResponse{AccessToken: accessToken})
+}
+
+// linkIdentity returns the user an external identity belongs to. An
+// unlinked identity is linked to the user with its email, who is
+// registered if there is none. Only emails the provider has verified are
+// trusted, as anyone could otherwise take over an account by its address.
+func (h *AuthHandler) linkIdentity(c *gin.Context, provider string, identity oidc.Identity) (string, error) {
+	userId, err := h.redis.LinkedUser(c.Request.Context(), provider, identity.Subject)
+	if err != nil || userId != "" {
+		return userId, err
+	}
+	if identity.Email == "" || !identity.EmailVerified {
+		return "", errUnverifiedIdentity
+	}
+
+	action := "user.oidc_link"
+	existing, err := h.auth.GetUserByEmail(c.Request.Context(), &pb.GetUserByEmailRequest{Email: identity.Email})
+	switch {
+	case err == nil && existing.UserId != "":
+		userId = existing.UserId
+	case err == nil || status.Code(err) == codes.NotFound:
+		// The password is never shown; the user can set one through
+		// forgot-password to sign in without the provider.
+		var generated string
+		generated, err = generatedPassword()
+		if err != nil {
+			return "", err
+		}
+		var registered *pb.RegisterResponse
+		registered, err = h.auth.Register(c.Request.Context(), &pb.RegisterRequest{Email: identity.Email, Password: generated})
+		if err != nil {
+			return "", err
+		}
+		userId, action = registered.UserId, "user.oidc_register"
+		// Register only creates users, so the role of this one is known.
+		if err = h.roles.Ensure(c.Request.Context(), userId, rbac.RoleUser); err != nil {
+			return "", err
+		}
+	default:
+		return "", err
+	}
+
+	// The provider has confirmed the address, which is all verification
+	// would do.
+	err = h.redis.SetUserVerified(c.Request.Context(), userId, true)
+	if err == nil {
+		err = h.redis.LinkIdentity(c.Request.Context(), provider, identity.Subject, userId)
+	}
+	h.audit.Log(c, audit.Entry{Action: action, Resource: "user", ResourceID: userId, After: gin.H{"provider": provider, "subject": identity.Subject}}, err)
+	return userId, err
+}
+
+// generatedPassword satisfies any reasonable password policy: it is long
+// and has every character class.
+func generatedPassword() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b) + "aA1!", nil
+}
@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/pkg/oidc"
+
+	"github.com/gin-gonic/gin"
+)
+
+// oauthProvider is an OAuth2 provider like GitHub on an httptest server.
+// It signs everyone in as subject "subject-1" with email, which it reports
+// as verified or not.
+type oauthProvider struct {
+	server   *httptest.Server
+	email    string
+	verified bool
+}
+
+func newOAuthProvider(t *testing.T, email string, verified bool) *oauthProvider {
+	t.Helper()
+	p := &oauthProvider{email: email, verified: verified}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gin.H{"access_token": "access-1"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(gin.H{"sub": "subject-1", "name": "Test User"})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode([]gin.H{{"email": p.email, "primary": true, "verified": p.verified}})
+	})
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *oauthProvider) provider(name string) *oidc.Provider {
+	return oidc.New(oidc.Options{
+		Name:        name,
+		ClientID:    "gateway",
+		RedirectURL: "http://gateway/auth/oidc/" + name + "/callback",
+		AuthURL:     p.server.URL + "/authorize",
+		TokenURL:    p.server.URL + "/token",
+		UserInfoURL: p.server.URL + "/user",
+		EmailsURL:   p.server.URL + "/user/emails",
+	})
+}
+
+type callbackTest struct {
+	h      *AuthHandler
+	router *gin.Engine
+}
+
+func newCallbackTest(t *testing.T, email string, verified bool) *callbackTest {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	h := newTestAuthHandler(t)
+	provider := newOAuthProvider(t, email, verified)
+	h.oidc = map[string]*oidc.Provider{
+		"mock":  provider.provider("mock"),
+		"other": provider.provider("other"),
+	}
+	router := gin.New()
+	router.GET("/auth/oidc/:provider/callback", h.OIDCCallbackHandler)
+	return &callbackTest{h: h, router: router}
+}
+
+// start stores a login in progress at provider, as OIDCLoginHandler does,
+// and returns its state.
+func (ct *callbackTest) start(t *testing.T, provider, state string) string {
+	t.Helper()
+	login := redisservice.OIDCLogin{Provider: provider, Nonce: "nonce-1", Verifier: "verifier-1"}
+	if err := ct.h.redis.StoreOIDCLogin(context.Background(), state, login, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	return state
+}
+
+// knownUser records userId in the role registry as a user, as their first
+// password login does.
+func (ct *callbackTest) knownUser(t *testing.T, userId string) {
+	t.Helper()
+	if err := ct.h.roles.Ensure(context.Background(), userId, rbac.RoleUser); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (ct *callbackTest) callback(provider, state string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	ct.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/auth/oidc/"+provider+"/callback?code=code-1&state="+state, nil))
+	return rec
+}
+
+func TestOIDCCallbackHandler(t *testing.T) {
+	t.Run("signs in the user linked to the identity", func(t *testing.T) {
+		ct := newCallbackTest(t, "user@example.com", true)
+		if err := ct.h.redis.LinkIdentity(context.Background(), "mock", "subject-1", "user-1"); err != nil {
+			t.Fatal(err)
+		}
+		ct.knownUser(t, "user-1")
+
+		rec := ct.callback("mock", ct.start(t, "mock", "state-1"))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		var resp struct {
+			AccessToken string `json:"access_token"`
+		}
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if userId := middleware.ParseToken(resp.AccessToken, ct.h.config)["user_id"]; userId != "user-1" {
+			t.Fatalf("token user_id = %v, want user-1", userId)
+		}
+	})
+
+	t.Run("state cannot be reused", func(t *testing.T) {
+		ct := newCallbackTest(t, "user@example.com", true)
+		if err := ct.h.redis.LinkIdentity(context.Background(), "mock", "subject-1", "user-1"); err != nil {
+			t.Fatal(err)
+		}
+		ct.knownUser(t, "user-1")
+		state := ct.start(t, "mock", "state-1")
+
+		if rec := ct.callback("mock", state); rec.Code != http.StatusOK {
+			t.Fatalf("first callback status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		if rec := ct.callback("mock", state); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("second callback status = %d, want 401: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("state of another provider is rejected and spent", func(t *testing.T) {
+		ct := newCallbackTest(t, "user@example.com", true)
+		if err := ct.h.redis.LinkIdentity(context.Background(), "mock", "subject-1", "user-1"); err != nil {
+			t.Fatal(err)
+		}
+		state := ct.start(t, "mock", "state-1")
+
+		if rec := ct.callback("other", state); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("callback at another provider status = %d, want 401: %s", rec.Code, rec.Body)
+		}
+		if rec := ct.callback("mock", state); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("callback after a mismatch status = %d, want 401: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		ct := newCallbackTest(t, "user@example.com", true)
+		if rec := ct.callback("mock", "never-issued"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want 401: %s", rec.Code, rec.Body)
+		}
+	})
+
+	t.Run("unverified email is not linked to its account", func(t *testing.T) {
+		ct := newCallbackTest(t, "victim@example.com", false)
+		fake := ct.h.auth.(*fakeAuthClient)
+		fake.users["victim@example.com"] = "victim"
+
+		rec := ct.callback("mock", ct.start(t, "mock", "state-1"))
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403: %s", rec.Code, rec.Body)
+		}
+		if userId, err := ct.h.redis.LinkedUser(context.Background(), "mock", "subject-1"); err != nil || userId != "" {
+			t.Fatalf("identity linked to %q, %v; want no link", userId, err)
+		}
+		if len(fake.registered) != 0 {
+			t.Fatalf("registered %v for an unverified email", fake.registered)
+		}
+	})
+
+	t.Run("verified email is linked to its account", func(t *testing.T) {
+		ct := newCallbackTest(t, "user@example.com", true)
+		ct.h.auth.(*fakeAuthClient).users["user@example.com"] = "user-1"
+		ct.knownUser(t, "user-1")
+
+		if rec := ct.callback("mock", ct.start(t, "mock", "state-1")); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		if userId, err := ct.h.redis.LinkedUser(context.Background(), "mock", "subject-1"); err != nil || userId != "user-1" {
+			t.Fatalf("identity linked to %q, %v; want user-1", userId, err)
+		}
+	})
+
+	t.Run("user with no recorded role is refused", func(t *testing.T) {
+		ct := newCallbackTest(t, "admin@example.com", true)
+		if err := ct.h.redis.LinkIdentity(context.Background(), "mock", "subject-1", "admin-1"); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec := ct.callback("mock", ct.start(t, "mock", "state-1")); rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403: %s", rec.Code, rec.Body)
+		}
+		if role, err := ct.h.roles.RoleOf(context.Background(), "admin-1"); err != nil || role != "" {
+			t.Fatalf("RoleOf = %q, %v; want no role recorded", role, err)
+		}
+	})
+
+	t.Run("new user is registered with the user role", func(t *testing.T) {
+		ct := newCallbackTest(t, "new@example.com", true)
+
+		if rec := ct.callback("mock", ct.start(t, "mock", "state-1")); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		if role, err := ct.h.roles.RoleOf(context.Background(), "user-new@example.com"); err != nil || role != rbac.RoleUser {
+			t.Fatalf("RoleOf = %q, %v; want user", role, err)
+		}
+	})
+
+	t.Run("staff cannot sign in with a provider", func(t *testing.T) {
+		ct := newCallbackTest(t, "admin@example.com", true)
+		if err := ct.h.redis.LinkIdentity(context.Background(), "mock", "subject-1", "admin-1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := ct.h.roles.Ensure(context.Background(), "admin-1", "admin"); err != nil {
+			t.Fatal(err)
+		}
+
+		if rec := ct.callback("mock", ct.start(t, "mock", "state-1")); rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403: %s", rec.Code, rec.Body)
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/password"
+	"gateway-service/internal/pkg/signedtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+const resetPurpose = "reset"
+
+var errWrongPassword = errors.New("current password is incorrect")
+
+// ForgotPasswordHandler godoc
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the account exists.
+// @Tags User Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Email address"
+// @Success 202 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/user/forgot-password [post]
+func (h *AuthHandler) ForgotPasswordHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ForgotPasswordHandler called")
+	var req models.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Email == "" {
+		c.IndentedJSON(400, gin.H{"error": "email is required"})
+		return
+	}
+
+	accepted := gin.H{"message": "If the account exists, a password reset email has been sent"}
+
+	user, err := h.auth.GetUserByEmail(c.Request.Context(), &pb.GetUserByEmailRequest{Email: req.Email})
+	if err != nil {
+		h.logger.InfoContext(c.Request.Context(), "Password reset for unknown email", "error", err.Error())
+		c.IndentedJSON(202, accepted)
+		return
+	}
+
+	cfg := h.config.PasswordReset
+	free, err := h.redis.AcquireCooldown(c.Request.Context(), resetPurpose+":"+user.UserId, cfg.Cooldown)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !free {
+		c.IndentedJSON(202, accepted)
+		return
+	}
+
+	link, expiresAt, err := h.issueTokenLink(c, resetPurpose, user.UserId, cfg.URL, cfg.TokenTTL)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	body, err := json.Marshal(models.PasswordResetRequestedEvent{
+		UserID:    user.UserId,
+		Email:     req.Email,
+		ResetURL:  link,
+		ExpiresAt: expiresAt,
+	})
+	if err == nil {
+		err = h.broker.PasswordResetRequested(c.Request.Context(), body)
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(202, accepted)
+}
+
+// ResetPasswordHandler godoc
+// @Summary Reset password
+// @Description Set a new password with the token from the reset email. Every existing session is ended.
+// @Tags User Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/user/reset-password [post]
+func (h *AuthHandler) ResetPasswordHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ResetPasswordHandler called")
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	// Check strength before using up the token so the user can retry.
+	if err := password.Check(req.NewPassword, h.config.Password); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := signedtoken.Parse(req.Token, resetPurpose, h.config.JWTSecrets(), time.Now())
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	userId, err := h.redis.ConsumeToken(c.Request.Context(), resetPurpose, id)
+	if errors.Is(err, redisservice.ErrTokenNotFound) {
+		c.IndentedJSON(400, gin.H{"error": "Invalid or expired reset token"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = h.setPassword(c, userId, req.NewPassword)
+	h.audit.Log(c, audit.Entry{Action: "user.password_reset", Resource: "user", ResourceID: userId}, err)
+	switch {
+	case errors.Is(err, rbac.ErrNoRole):
+		c.IndentedJSON(409, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Password reset successfully. Please log in again."})
+}
+
+// ChangePasswordHandler godoc
+// @Summary Change password
+// @Security BearerAuth
+// @Description Change the password of the logged in user. Every session, including the current one, is ended.
+// @Tags User Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Email, current password and new password"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/password [put]
+func (h *AuthHandler) ChangePasswordHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ChangePasswordHandler called")
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.NewPassword == req.CurrentPassword {
+		c.IndentedJSON(400, gin.H{"error": "new password must differ from the current one"})
+		return
+	}
+	if err := password.Check(req.NewPassword, h.config.Password, req.Email); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := middleware.GetUser_id(c, h.config)
+	entry := audit.Entry{Action: "user.password_change", Resource: "user", ResourceID: userId}
+
+	if !h.checkPassword(c, userId, req.Email, req.CurrentPassword) {
+		h.audit.Log(c, entry, errWrongPassword)
+		c.IndentedJSON(401, gin.H{"error": errWrongPassword.Error()})
+		return
+	}
+
+	err := h.setPassword(c, userId, req.NewPassword)
+	h.audit.Log(c, entry, err)
+	switch {
+	case errors.Is(err, rbac.ErrNoRole):
+		c.IndentedJSON(409, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Password changed successfully. Please log in again."})
+}
+
+// checkPassword reports whether email and currentPassword log in to
+// userId's account. The auth service has no password check of its own, so
+// this logs in and discards the tokens.
+func (h *AuthHandler) checkPassword(c *gin.Context, userId, email, currentPassword string) bool {
+	resp, err := h.auth.Login(c.Request.Context(), &pb.LoginRequest{Email: email, Password: currentPassword})
+	if err != nil {
+		return false
+	}
+	loginUserId, _ := middleware.ParseToken(resp.AccessToken, h.config)["user_id"].(string)
+	return loginUserId != "" && loginUserId == userId
+}
+
+// setPassword updates the password in the auth service and ends every
+// session of the user.
+func (h *AuthHandler) setPassword(c *gin.Context, userId, newPassword string) error {
+	err := h.updateUser(c.Request.Context(), userId, func(req *pb.UpdateUserRequest) { req.Password = newPassword })
+	if err != nil {
+		return err
+	}
+	return h.revokeSessions(c, userId)
+}
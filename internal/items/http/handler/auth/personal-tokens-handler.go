@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxTokenNameLength = 100
+
+func toPersonalToken(token redisservice.PersonalToken) models.PersonalToken {
+	result := models.PersonalToken{
+		ID:        token.ID,
+		Name:      token.Name,
+		Scopes:    token.Scopes,
+		CreatedAt: token.CreatedAt.UTC(),
+		ExpiresAt: token.ExpiresAt.UTC(),
+	}
+	if !token.LastUsedAt.IsZero() {
+		lastUsed := token.LastUsedAt.UTC()
+		result.LastUsedAt = &lastUsed
+	}
+	return result
+}
+
+// newPersonalToken returns a random token ID and the full token.
+func newPersonalToken() (id, token string, err error) {
+	b := make([]byte, 40)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	id = hex.EncodeToString(b[:8])
+	return id, middleware.PersonalTokenPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(b[8:]), nil
+}
+
+// PersonalTokenScopesHandler godoc
+// @Summary List personal access token scopes
+// @Security BearerAuth
+// @Description List the scopes personal access tokens can be given
+// @Tags Personal Access Tokens
+// @Produce json
+// @Success 200 {object} models.PersonalTokenScopesResponse
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/tokens/scopes [get]
+func (h *AuthHandler) PersonalTokenScopesHandler(c *gin.Context) {
+	scopes, err := h.roles.Scopes()
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.IndentedJSON(200, models.PersonalTokenScopesResponse{Scopes: scopes})
+}
+
+// CreatePersonalTokenHandler godoc
+// @Summary Create a personal access token
+// @Security BearerAuth
+// @Description Create a named token for scripts and integrations, sent in the Authorization header like a JWT. It only reaches the routes its scopes allow and is shown only in this response.
+// @Tags Personal Access Tokens
+// @Accept json
+// @Produce json
+// @Param request body models.CreatePersonalTokenRequest true "Name, scopes and optional expiry"
+// @Success 201 {object} models.CreatePersonalTokenResponse
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/tokens [post]
+func (h *AuthHandler) CreatePersonalTokenHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "CreatePersonalTokenHandler called")
+	var req models.CreatePersonalTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := middleware.GetUser_id(c, h.config)
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxTokenNameLength {
+		c.IndentedJSON(400, gin.H{"error": fmt.Sprintf("name is required and must be at most %d characters", maxTokenNameLength)})
+		return
+	}
+	if len(req.Scopes) == 0 {
+		c.IndentedJSON(400, gin.H{"error": "at least one scope is required"})
+		return
+	}
+	known, err := h.roles.Scopes()
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	scopes := []string{}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(known, scope) {
+			c.IndentedJSON(400, gin.H{"error": fmt.Sprintf("unknown scope %q", scope)})
+			return
+		}
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+
+	now := time.Now()
+	expiresAt := now.Add(h.config.PersonalTokens.DefaultTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(h.config.PersonalTokens.MaxTTL)) {
+		c.IndentedJSON(400, gin.H{"error": fmt.Sprintf("expires_at must be in the future and within %s", h.config.PersonalTokens.MaxTTL)})
+		return
+	}
+
+	existing, err := h.redis.ListPersonalTokens(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if len(existing) >= h.config.PersonalTokens.MaxPerUser {
+		c.IndentedJSON(409, gin.H{"error": fmt.Sprintf("a user can have at most %d personal access tokens", h.config.PersonalTokens.MaxPerUser)})
+		return
+	}
+
+	id, plaintext, err := newPersonalToken()
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	token := redisservice.PersonalToken{
+		ID:        id,
+		UserID:    userId,
+		Name:      req.Name,
+		Scopes:    scopes,
+		Hash:      middleware.HashPersonalToken(plaintext),
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	err = h.redis.CreatePersonalToken(c.Request.Context(), token)
+	created := toPersonalToken(token)
+	h.audit.Log(c, audit.Entry{Action: "pat.create", Resource: "personal_token", ResourceID: id, After: &created}, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(201, models.CreatePersonalTokenResponse{PersonalToken: created, Token: plaintext})
+}
+
+// ListPersonalTokensHandler godoc
+// @Summary List personal access tokens
+// @Security BearerAuth
+// @Description List the caller's unexpired personal access tokens with their last use
+// @Tags Personal Access Tokens
+// @Produce json
+// @Success 200 {array} models.PersonalToken
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/tokens [get]
+func (h *AuthHandler) ListPersonalTokensHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ListPersonalTokensHandler called")
+	tokens, err := h.redis.ListPersonalTokens(c.Request.Context(), middleware.GetUser_id(c, h.config))
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.After(tokens[j].CreatedAt) })
+	result := make([]models.PersonalToken, 0, len(tokens))
+	for _, token := range tokens {
+		result = append(result, toPersonalToken(token))
+	}
+	c.IndentedJSON(200, result)
+}
+
+// RevokePersonalTokenHandler godoc
+// @Summary Revoke a personal access token
+// @Security BearerAuth
+// @Description Revoke one of the caller's personal access tokens at once
+// @Tags Personal Access Tokens
+// @Produce json
+// @Param id path string true "Token ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/tokens/{id} [delete]
+func (h *AuthHandler) RevokePersonalTokenHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "RevokePersonalTokenHandler called")
+	id := c.Param("id")
+
+	revoked, err := h.redis.RevokePersonalToken(c.Request.Context(), middleware.GetUser_id(c, h.config), id)
+	if err != nil {
+		h.audit.Log(c, audit.Entry{Action: "pat.revoke", Resource: "personal_token", ResourceID: id}, err)
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !revoked {
+		c.IndentedJSON(404, gin.H{"error": "Personal access token not found"})
+		return
+	}
+	h.audit.Log(c, audit.Entry{Action: "pat.revoke", Resource: "personal_token", ResourceID: id}, nil)
+
+	c.IndentedJSON(200, gin.H{"message": "Personal access token revoked"})
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// releaseTokens records the session of a completed login, cancels a
+// pending deletion of the account and answers with its tokens.
+func (h *AuthHandler) releaseTokens(c *gin.Context, resp *pb.LoginResponse) {
+	claims := middleware.ParseToken(resp.AccessToken, h.config)
+	userId, _ := claims["user_id"].(string)
+	if userId == "" {
+		c.IndentedJSON(200, resp)
+		return
+	}
+	if err := h.cancelDeletion(c, userId); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	session := redisservice.Session{
+		ID:        hex.EncodeToString(b),
+		UserID:    userId,
+		TokenID:   middleware.TokenID(resp.AccessToken, claims),
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+		CreatedAt: time.Now(),
+	}
+	if resp.RefreshToken != "" {
+		sum := sha256.Sum256([]byte(resp.RefreshToken))
+		session.RefreshHash = hex.EncodeToString(sum[:])
+	}
+	if err := h.redis.StartSession(c.Request.Context(), session, h.config.JWT.AccessTokenTTL); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, resp)
+}
+
+// endCurrentSession revokes the session of the request's token, if it
+// carries one, on logout.
+func (h *AuthHandler) endCurrentSession(c *gin.Context) {
+	userId := middleware.GetUser_id(c, h.config)
+	if userId == "" {
+		return
+	}
+	sessionId, err := h.redis.SessionOfToken(c.Request.Context(), middleware.GetTokenID(c, h.config))
+	if err == nil && sessionId != "" {
+		_, err = h.redis.RevokeSession(c.Request.Context(), userId, sessionId, h.config.JWT.AccessTokenTTL)
+	}
+	if err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to end session on logout", "user_id", userId, "error", err.Error())
+	}
+}
+
+// listSessions answers with userId's sessions, newest first, marking
+// currentId.
+func (h *AuthHandler) listSessions(c *gin.Context, userId, currentId string) {
+	sessions, err := h.redis.ListSessions(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.After(sessions[j].CreatedAt) })
+	result := make([]models.Session, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, models.Session{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IP:         s.IP,
+			CreatedAt:  s.CreatedAt.UTC(),
+			LastSeenAt: s.LastSeenAt.UTC(),
+			Current:    s.ID == currentId,
+		})
+	}
+	c.IndentedJSON(200, result)
+}
+
+func (h *AuthHandler) revokeSession(c *gin.Context, userId, sessionId string) {
+	revoked, err := h.redis.RevokeSession(c.Request.Context(), userId, sessionId, h.config.JWT.AccessTokenTTL)
+	if err != nil {
+		h.audit.Log(c, audit.Entry{Action: "session.revoke", Resource: "session", ResourceID: sessionId}, err)
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !revoked {
+		c.IndentedJSON(404, gin.H{"error": "Session not found"})
+		return
+	}
+	h.audit.Log(c, audit.Entry{Action: "session.revoke", Resource: "session", ResourceID: sessionId, Before: gin.H{"user_id": userId}}, nil)
+
+	c.IndentedJSON(200, gin.H{"message": "Session revoked"})
+}
+
+// ListSessionsHandler godoc
+// @Summary List sessions
+// @Security BearerAuth
+// @Description List the devices the caller is logged in on, with the session of this request marked current
+// @Tags Sessions
+// @Produce json
+// @Success 200 {array} models.Session
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/sessions [get]
+func (h *AuthHandler) ListSessionsHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ListSessionsHandler called")
+	current, err := h.redis.SessionOfToken(c.Request.Context(), middleware.GetTokenID(c, h.config))
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	h.listSessions(c, middleware.GetUser_id(c, h.config), current)
+}
+
+// RevokeSessionHandler godoc
+// @Summary Revoke a session
+// @Security BearerAuth
+// @Description Log the caller out of one session. Its access token is rejected at once.
+// @Tags Sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSessionHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "RevokeSessionHandler called")
+	h.revokeSession(c, middleware.GetUser_id(c, h.config), c.Param("id"))
+}
+
+// RevokeOtherSessionsHandler godoc
+// @Summary Revoke all other sessions
+// @Security BearerAuth
+// @Description Log the caller out everywhere except the session of this request
+// @Tags Sessions
+// @Produce json
+// @Success 200 {object} models.RevokeSessionsResponse
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/sessions/revoke-others [post]
+func (h *AuthHandler) RevokeOtherSessionsHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "RevokeOtherSessionsHandler called")
+	userId := middleware.GetUser_id(c, h.config)
+	current, err := h.redis.SessionOfToken(c.Request.Context(), middleware.GetTokenID(c, h.config))
+	var sessions []redisservice.Session
+	if err == nil {
+		sessions, err = h.redis.ListSessions(c.Request.Context(), userId)
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	count := 0
+	for _, s := range sessions {
+		if s.ID == current {
+			continue
+		}
+		revoked, err := h.redis.RevokeSession(c.Request.Context(), userId, s.ID, h.config.JWT.AccessTokenTTL)
+		if err != nil {
+			h.audit.Log(c, audit.Entry{Action: "session.revoke_others", Resource: "user", ResourceID: userId}, err)
+			c.IndentedJSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if revoked {
+			count++
+		}
+	}
+	h.audit.Log(c, audit.Entry{Action: "session.revoke_others", Resource: "user", ResourceID: userId, After: gin.H{"revoked": count}}, nil)
+
+	c.IndentedJSON(200, models.RevokeSessionsResponse{Revoked: count})
+}
+
+// AdminListSessionsHandler godoc
+// @Summary List a user's sessions
+// @Security BearerAuth
+// @Description List the devices a user is logged in on
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {array} models.Session
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/sessions [get]
+func (h *AuthHandler) AdminListSessionsHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "AdminListSessionsHandler called")
+	h.listSessions(c, c.Param("id"), "")
+}
+
+// AdminRevokeSessionHandler godoc
+// @Summary Revoke a user's session
+// @Security BearerAuth
+// @Description Log a user out of one session. To end all of them use /admin/users/{id}/logout. Only superadmins can act on admins and superadmins.
+// @Tags Admin Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Param session_id path string true "Session ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /admin/users/{id}/sessions/{session_id} [delete]
+func (h *AuthHandler) AdminRevokeSessionHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "AdminRevokeSessionHandler called")
+	userId, sessionId := c.Param("id"), c.Param("session_id")
+	entry := audit.Entry{Action: "session.revoke", Resource: "session", ResourceID: sessionId, Before: gin.H{"user_id": userId}}
+	if _, ok := h.checkStaffTarget(c, userId, entry, errStaffAction); !ok {
+		return
+	}
+	h.revokeSession(c, userId, sessionId)
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// startTestSession records session id of userId for the access token tokenId.
+func startTestSession(t *testing.T, h *AuthHandler, id, userId, tokenId string) {
+	t.Helper()
+	err := h.redis.StartSession(context.Background(), redisservice.Session{
+		ID:        id,
+		UserID:    userId,
+		TokenID:   tokenId,
+		UserAgent: "test",
+		CreatedAt: time.Now(),
+	}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRevokeSessionHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		session string
+		status  int
+		revoked bool
+	}{
+		{"own session", "session-1", http.StatusOK, true},
+		{"another user's session", "session-2", http.StatusNotFound, false},
+		{"unknown session", "session-3", http.StatusNotFound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			ctx := context.Background()
+			startTestSession(t, h, "session-1", "user-1", "token-1")
+			startTestSession(t, h, "session-2", "user-2", "token-2")
+			tokens := map[string]string{"session-1": "token-1", "session-2": "token-2"}
+
+			router := gin.New()
+			router.DELETE("/user/sessions/:id", h.RevokeSessionHandler)
+			req := httptest.NewRequest(http.MethodDelete, "/user/sessions/"+tt.session, nil)
+			req.Header.Set("Authorization", testToken(t, h.config, "user-1", "user"))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			tokenId, ok := tokens[tt.session]
+			if !ok {
+				return
+			}
+			_, err := h.redis.GetSession(ctx, tt.session)
+			if gone := errors.Is(err, redisservice.ErrTokenNotFound); gone != tt.revoked {
+				t.Fatalf("GetSession error = %v, want revoked %v", err, tt.revoked)
+			}
+			valid, err := h.redis.TouchSession(ctx, tokenId, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if valid == tt.revoked {
+				t.Fatalf("token valid = %v, want %v", valid, !tt.revoked)
+			}
+		})
+	}
+}
+
+func TestRevokeOtherSessionsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		// current records a session for the request's own token.
+		current bool
+		revoked int
+	}{
+		{"keeps the current session", true, 2},
+		{"token without a session", false, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			ctx := context.Background()
+			token := testToken(t, h.config, "user-1", "user")
+			if tt.current {
+				startTestSession(t, h, "current", "user-1", middleware.TokenID(token, middleware.ParseToken(token, h.config)))
+			}
+			startTestSession(t, h, "phone", "user-1", "token-phone")
+			startTestSession(t, h, "laptop", "user-1", "token-laptop")
+			startTestSession(t, h, "other", "user-2", "token-other")
+
+			router := gin.New()
+			router.POST("/user/sessions/revoke-others", h.RevokeOtherSessionsHandler)
+			router.GET("/user/sessions", h.ListSessionsHandler)
+			rec := postJSON(router, "/user/sessions/revoke-others", token, nil)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			var resp models.RevokeSessionsResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Revoked != tt.revoked {
+				t.Fatalf("revoked = %d, want %d", resp.Revoked, tt.revoked)
+			}
+			if _, err := h.redis.GetSession(ctx, "other"); err != nil {
+				t.Fatalf("another user's session: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/user/sessions", nil)
+			req.Header.Set("Authorization", token)
+			rec = httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			var sessions []models.Session
+			if err := json.Unmarshal(rec.Body.Bytes(), &sessions); err != nil {
+				t.Fatal(err)
+			}
+			want := 0
+			if tt.current {
+				want = 1
+			}
+			if len(sessions) != want {
+				t.Fatalf("sessions left = %+v, want %d", sessions, want)
+			}
+			if tt.current && (sessions[0].ID != "current" || !sessions[0].Current) {
+				t.Fatalf("session left = %+v, want the current one marked", sessions[0])
+			}
+		})
+	}
+}
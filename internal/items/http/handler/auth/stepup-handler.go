@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/signedtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+var errReauthFailed = errors.New("re-authentication failed")
+
+// ElevateHandler godoc
+// @Summary Re-authenticate for sensitive operations
+// @Security BearerAuth
+// @Description Confirm the logged in user with a TOTP code, or with email and password, and get a short-lived token to send as X-Elevation-Token to routes that answer 401 with a step_up challenge
+// @Tags User Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ElevateRequest true "TOTP code, or email and password"
+// @Success 200 {object} models.ElevateResponse
+// @Failure 400 {object} gin.H
+// @Failure 401 {object} gin.H
+// @Failure 429 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/elevate [post]
+func (h *AuthHandler) ElevateHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ElevateHandler called")
+	var req models.ElevateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := middleware.GetUser_id(c, h.config)
+	entry := audit.Entry{Action: "auth.elevate", Resource: "user", ResourceID: userId}
+
+	// Wrong passwords count towards the lockout too, so a stolen access
+	// token cannot be used to guess either.
+	if h.mfaLocked(c, userId) {
+		h.audit.Log(c, entry, errMFALocked)
+		return
+	}
+
+	ok := false
+	switch {
+	case req.Code != "":
+		secret, err := h.mfaSecret(c, userId)
+		if err != nil {
+			c.IndentedJSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if secret == "" {
+			c.IndentedJSON(400, gin.H{"error": "Two-factor authentication is not enabled; use email and password"})
+			return
+		}
+		ok, err = h.checkMFACode(c, userId, secret, req.Code, false)
+		if err != nil {
+			c.IndentedJSON(500, gin.H{"error": err.Error()})
+			return
+		}
+	case req.Email != "" && req.Password != "":
+		ok = h.checkPassword(c, userId, req.Email, req.Password)
+	default:
+		c.IndentedJSON(400, gin.H{"error": "code, or email and password, is required"})
+		return
+	}
+	if !ok {
+		h.failMFA(c, userId)
+		h.audit.Log(c, entry, errReauthFailed)
+		c.IndentedJSON(401, gin.H{"error": errReauthFailed.Error()})
+		return
+	}
+	h.passMFA(c, userId)
+
+	ttl := h.config.StepUp.ElevationTTL
+	expiresAt := time.Now().Add(ttl)
+	token, id, err := signedtoken.New(h.config.JWTSecrets()[0], middleware.ElevationPurpose, expiresAt)
+	if err == nil {
+		err = h.redis.StoreToken(c.Request.Context(), middleware.ElevationPurpose, id, userId, ttl)
+	}
+	h.audit.Log(c, entry, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.ElevateResponse{ElevationToken: token, ExpiresAt: expiresAt.UTC()})
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"errors"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/rbac"
+	"gateway-service/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// registerRole records a user in the role registry with the role the auth
+// service issued accessToken for, the first time they log in. Admins then
+// show up in the admin list, and their role can be changed. Only tokens
+// from the auth service may be passed; one the gateway signed carries a
+// role it made up.
+func (h *AuthHandler) registerRole(c *gin.Context, accessToken string) {
+	claims := middleware.ParseToken(accessToken, h.config)
+	userId, _ := claims["user_id"].(string)
+	role, _ := claims["role"].(string)
+	if userId == "" || !rbac.IsRole(role) {
+		return
+	}
+	if err := h.roles.Ensure(c.Request.Context(), userId, role); err != nil {
+		h.logger.WarnContext(c.Request.Context(), "Failed to register role", "user_id", userId, "error", err.Error())
+	}
+}
+
+// ListAdminsHandler godoc
+// @Summary List admins
+// @Security BearerAuth
+// @Description List admins and superadmins with the time of their last request
+// @Tags Super Admin
+// @Produce json
+// @Success 200 {array} models.StaffMember
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /superadmin/admins [get]
+func (h *AuthHandler) ListAdminsHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ListAdminsHandler called")
+
+	staff := []models.StaffMember{}
+	var userIds []string
+	for _, role := range []string{rbac.RoleSuperadmin, rbac.RoleAdmin} {
+		members, err := h.roles.Members(c.Request.Context(), role)
+		if err != nil {
+			c.IndentedJSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		for _, userId := range members {
+			staff = append(staff, models.StaffMember{UserID: userId, Role: role})
+			userIds = append(userIds, userId)
+		}
+	}
+
+	lastActive, err := h.redis.StaffLastActive(c.Request.Context(), userIds)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	for i := range staff {
+		if t, ok := lastActive[staff[i].UserID]; ok {
+			staff[i].LastActiveAt = &t
+		}
+	}
+
+	c.IndentedJSON(200, staff)
+}
+
+// SetRoleHandler godoc
+// @Summary Change a user's role
+// @Security BearerAuth
+// @Description Promote a user to admin or demote an admin or superadmin to user. The last superadmin cannot be demoted, and a user's role can only be changed once they have logged in. The user's sessions are ended so the new role applies at once.
+// @Tags Super Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.SetRoleRequest true "New role: user or admin"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 409 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /superadmin/users/{id}/role [put]
+func (h *AuthHandler) SetRoleHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "SetRoleHandler called")
+	var req models.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Role != rbac.RoleUser && req.Role != rbac.RoleAdmin {
+		c.IndentedJSON(400, gin.H{"error": "role must be user or admin"})
+		return
+	}
+
+	userId := c.Param("id")
+	entry := audit.Entry{Action: "user.set_role", Resource: "user", ResourceID: userId, After: gin.H{"role": req.Role}}
+	if userId == middleware.GetUser_id(c, h.config) {
+		h.audit.Log(c, entry, rbac.ErrSelfRoleChange)
+		c.IndentedJSON(400, gin.H{"error": rbac.ErrSelfRoleChange.Error()})
+		return
+	}
+
+	previous, err := h.roles.RoleOf(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	entry.Before = gin.H{"role": previous}
+
+	err = h.assignRole(c, userId, req.Role)
+	h.audit.Log(c, entry, err)
+	switch {
+	case errors.Is(err, rbac.ErrLastSuperadmin), errors.Is(err, rbac.ErrNoRole):
+		c.IndentedJSON(409, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		upstreamError(c, err, "UpdateUser")
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Role updated successfully", "role": req.Role})
+}
+
+// assignRole updates the role in the registry, which the gateway enforces,
+// mirrors it to the auth service so new tokens carry it, and ends the
+// user's current sessions. The registry goes first so the last-superadmin
+// check holds, and is restored if the auth service is not updated.
+func (h *AuthHandler) assignRole(c *gin.Context, userId, role string) error {
+	ctx := c.Request.Context()
+	previous, err := h.roles.Assign(ctx, userId, role)
+	if err != nil {
+		return err
+	}
+
+	if err := h.updateUser(ctx, userId, func(req *pb.UpdateUserRequest) { req.Role = role }); err != nil {
+		if _, restoreErr := h.roles.Assign(ctx, userId, previous); restoreErr != nil {
+			h.logger.ErrorContext(ctx, "Failed to restore role", "user_id", userId, "role", previous, "error", restoreErr.Error())
+		}
+		return err
+	}
+	return h.revokeSessions(c, userId)
+}
+
+// RevokeAdminSessionsHandler godoc
+// @Summary Revoke an admin's sessions
+// @Security BearerAuth
+// @Description End every session of an admin or superadmin. Tokens issued before now are rejected.
+// @Tags Super Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 404 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /superadmin/admins/{id}/revoke-sessions [post]
+func (h *AuthHandler) RevokeAdminSessionsHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "RevokeAdminSessionsHandler called")
+	userId := c.Param("id")
+
+	role, err := h.roles.RoleOf(c.Request.Context(), userId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if role != rbac.RoleAdmin && role != rbac.RoleSuperadmin {
+		c.IndentedJSON(404, gin.H{"error": "Admin not found"})
+		return
+	}
+
+	err = h.revokeSessions(c, userId)
+	h.audit.Log(c, audit.Entry{Action: "admin.revoke_sessions", Resource: "user", ResourceID: userId}, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Admin logged out of all sessions"})
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"time"
+
+	pb "gateway-service/genproto/auth"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/signedtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+const verifyPurpose = "verify"
+
+// issueTokenLink stores a new one-time token for userId, replacing any
+// earlier one for purpose, and returns baseURL with the token appended.
+func (h *AuthHandler) issueTokenLink(c *gin.Context, purpose, userId, baseURL string, ttl time.Duration) (string, time.Time, error) {
+	expiresAt := time.Now().Add(ttl)
+	token, id, err := signedtoken.New(h.config.JWTSecrets()[0], purpose, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := h.redis.StoreToken(c.Request.Context(), purpose, id, userId, ttl); err != nil {
+		return "", time.Time{}, err
+	}
+
+	link, err := url.Parse(baseURL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), expiresAt.UTC(), nil
+}
+
+// sendVerification issues a new verification token for userId and
+// publishes the link for the mailer.
+func (h *AuthHandler) sendVerification(c *gin.Context, userId, email string) error {
+	link, expiresAt, err := h.issueTokenLink(c, verifyPurpose, userId, h.config.Verification.URL, h.config.Verification.TokenTTL)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(models.VerificationRequestedEvent{
+		UserID:    userId,
+		Email:     email,
+		VerifyURL: link,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return h.broker.UserVerificationRequested(c.Request.Context(), body)
+}
+
+// VerifyEmailHandler godoc
+// @Summary Verify email address
+// @Description Confirm a user's email address with the token from the verification email
+// @Tags User Auth
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/user/verify [post]
+func (h *AuthHandler) VerifyEmailHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "VerifyEmailHandler called")
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	id, err := signedtoken.Parse(req.Token, verifyPurpose, h.config.JWTSecrets(), time.Now())
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+
+	userId, err := h.redis.ConsumeToken(c.Request.Context(), verifyPurpose, id)
+	if errors.Is(err, redisservice.ErrTokenNotFound) {
+		c.IndentedJSON(400, gin.H{"error": "Invalid or expired verification token"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.redis.SetUserVerified(c.Request.Context(), userId, true); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Email verified successfully"})
+}
+
+// ResendVerificationHandler godoc
+// @Summary Resend verification email
+// @Description Send a new verification email, invalidating the previous link. The response is the same whether or not the account exists.
+// @Tags User Auth
+// @Accept json
+// @Produce json
+// @Param request body models.ResendVerificationRequest true "Email address"
+// @Success 202 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /auth/user/resend-verification [post]
+func (h *AuthHandler) ResendVerificationHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ResendVerificationHandler called")
+	var req models.ResendVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Email == "" {
+		c.IndentedJSON(400, gin.H{"error": "email is required"})
+		return
+	}
+
+	accepted := gin.H{"message": "If the account exists and is unverified, a verification email has been sent"}
+
+	user, err := h.auth.GetUserByEmail(c.Request.Context(), &pb.GetUserByEmailRequest{Email: req.Email})
+	if err != nil {
+		h.logger.InfoContext(c.Request.Context(), "Verification resend for unknown email", "error", err.Error())
+		c.IndentedJSON(202, accepted)
+		return
+	}
+
+	userStatus, err := h.redis.GetUserStatus(c.Request.Context(), user.UserId)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !userStatus.Unverified {
+		c.IndentedJSON(202, accepted)
+		return
+	}
+
+	free, err := h.redis.AcquireCooldown(c.Request.Context(), verifyPurpose+":"+user.UserId, h.config.Verification.ResendCooldown)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !free {
+		c.IndentedJSON(202, accepted)
+		return
+	}
+
+	if err := h.sendVerification(c, user.UserId, req.Email); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(202, accepted)
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"gateway-service/internal/pkg/signedtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestVerifyEmailHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		// token returns the token to submit, storing it if it should work.
+		token    func(t *testing.T, h *AuthHandler) string
+		status   int
+		verified bool
+	}{
+		{"valid token", func(t *testing.T, h *AuthHandler) string {
+			return storeTestToken(t, h, verifyPurpose, time.Hour)
+		}, http.StatusOK, true},
+		{"token already used", func(t *testing.T, h *AuthHandler) string {
+			token := storeTestToken(t, h, verifyPurpose, time.Hour)
+			router := gin.New()
+			router.POST("/auth/user/verify", h.VerifyEmailHandler)
+			if rec := postJSON(router, "/auth/user/verify", "", gin.H{"token": token}); rec.Code != http.StatusOK {
+				t.Fatalf("first use status = %d", rec.Code)
+			}
+			if err := h.redis.SetUserVerified(context.Background(), "user-1", false); err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}, http.StatusBadRequest, false},
+		{"expired token", func(t *testing.T, h *AuthHandler) string {
+			return storeTestToken(t, h, verifyPurpose, -time.Minute)
+		}, http.StatusBadRequest, false},
+		{"token for another purpose", func(t *testing.T, h *AuthHandler) string {
+			return storeTestToken(t, h, "reset", time.Hour)
+		}, http.StatusBadRequest, false},
+		{"token never stored", func(t *testing.T, h *AuthHandler) string {
+			token, _, err := signedtoken.New(h.config.JWTSecrets()[0], verifyPurpose, time.Now().Add(time.Hour))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return token
+		}, http.StatusBadRequest, false},
+		{"tampered token", func(t *testing.T, h *AuthHandler) string {
+			return storeTestToken(t, h, verifyPurpose, time.Hour) + "x"
+		}, http.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestAuthHandler(t)
+			ctx := context.Background()
+			if err := h.redis.SetUserVerified(ctx, "user-1", false); err != nil {
+				t.Fatal(err)
+			}
+			token := tt.token(t, h)
+
+			router := gin.New()
+			router.POST("/auth/user/verify", h.VerifyEmailHandler)
+			rec := postJSON(router, "/auth/user/verify", "", gin.H{"token": token})
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+
+			status, err := h.redis.GetUserStatus(ctx, "user-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status.Unverified == tt.verified {
+				t.Fatalf("unverified = %v, want %v", status.Unverified, !tt.verified)
+			}
+		})
+	}
+}
+
+// storeTestToken issues a one-time token for user-1 the way issueTokenLink
+// does, expiring after ttl.
+func storeTestToken(t *testing.T, h *AuthHandler, purpose string, ttl time.Duration) string {
+	t.Helper()
+	token, id, err := signedtoken.New(h.config.JWTSecrets()[0], purpose, time.Now().Add(ttl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.redis.StoreToken(context.Background(), purpose, id, "user-1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -2,6 +2,7 @@ package budgeting
 
 import (
 	pb "gateway-service/genproto/account"
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
 	"gateway-service/internal/items/middleware"
 	"gateway-service/internal/items/msgbroker"
@@ -18,15 +19,17 @@ type AccountHandler struct {
 	logger    *slog.Logger
 	msgbroker *msgbroker.MsgBroker
 	config    *config.Config
+	audit     *audit.Auditor
 }
 
-func NewAccountHandler(redis *redisservice.RedisService, account pb.AccountServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *AccountHandler {
+func NewAccountHandler(redis *redisservice.RedisService, account pb.AccountServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor) *AccountHandler {
 	return &AccountHandler{
 		redis:     redis,
 		account:   account,
 		logger:    logger,
 		msgbroker: msgbroker,
 		config:    config,
+		audit:     auditor,
 	}
 }
 
@@ -44,7 +47,7 @@ func NewAccountHandler(redis *redisservice.RedisService, account pb.AccountServi
 // @Failure      500                   {object}  gin.H "Failed to create account"
 // @Router       /user/account [post]
 func (h *AccountHandler) CreateAccountHandler(c *gin.Context) {
-	h.logger.Info("CreateAccountHandler")
+	h.logger.InfoContext(c.Request.Context(), "CreateAccountHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -65,13 +68,14 @@ func (h *AccountHandler) CreateAccountHandler(c *gin.Context) {
 		Balance:  req.Balance,
 		Currency: req.Currency,
 	})
+	h.audit.Log(c, audit.Entry{Action: "account.create", Resource: "account", ResourceID: resp.GetId(), After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to create account"})
 		return
 	}
 
 	if _, err := h.redis.StoreAccountInRedis(c.Request.Context(), resp); err != nil {
-		h.logger.Error("Error storing account in Redis:", slog.String("err: ", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Error storing account in Redis:", slog.String("err: ", err.Error()))
 	}
 
 	c.IndentedJSON(201, resp)
@@ -88,7 +92,7 @@ func (h *AccountHandler) CreateAccountHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get accounts"
 // @Router       /user/account [get]
 func (h *AccountHandler) GetAccountsHandler(c *gin.Context) {
-	h.logger.Info("GetAccountsHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetAccountsHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -119,7 +123,7 @@ func (h *AccountHandler) GetAccountsHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to retrieve account"
 // @Router       /user/account/{id} [get]
 func (h *AccountHandler) GetAccountByIdHandler(c *gin.Context) {
-	h.logger.Info("GetAccountByIdHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetAccountByIdHandler")
 
 	accountID := c.Param("id")
 	if accountID == "" {
@@ -129,10 +133,10 @@ func (h *AccountHandler) GetAccountByIdHandler(c *gin.Context) {
 	
 	acc, err := h.redis.GetAccountFromRedis(c.Request.Context(), accountID)
 	if err != nil {
-		h.logger.Error("Error getting account from Redis:", slog.String("err: ", err.Error()))
+		h.logger.ErrorContext(c.Request.Context(), "Error getting account from Redis:", slog.String("err: ", err.Error()))
 	}
 	if acc != nil {
-		h.logger.Info("Account found in Redis")
+		h.logger.InfoContext(c.Request.Context(), "Account found in Redis")
 		c.IndentedJSON(200, acc)
 	}
 
@@ -160,7 +164,7 @@ func (h *AccountHandler) GetAccountByIdHandler(c *gin.Context) {
 // @Failure      500                   {object}  gin.H "Failed to update account"
 // @Router       /user/account [put]
 func (h *AccountHandler) UpdateAccountHandler(c *gin.Context) {
-	h.logger.Info("UpdateAccountHandler")
+	h.logger.InfoContext(c.Request.Context(), "UpdateAccountHandler")
 
 	var req pb.UpdateAccountRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -168,7 +172,10 @@ func (h *AccountHandler) UpdateAccountHandler(c *gin.Context) {
 		return
 	}
 
+	before, err := h.account.GetAccountById(c.Request.Context(), &pb.GetAccountByIdRequest{Id: req.Id})
+	logBeforeLookup(c, h.logger, "account", req.Id, err)
 	resp, err := h.account.UpdateAccount(c.Request.Context(), &req)
+	h.audit.Log(c, audit.Entry{Action: "account.update", Resource: "account", ResourceID: req.Id, Before: before, After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to update account"})
 		return
@@ -180,16 +187,18 @@ func (h *AccountHandler) UpdateAccountHandler(c *gin.Context) {
 // DeleteAccountHandler godoc
 // @Summary      Delete account
 // @Security     BearerAuth
-// @Description  Delete account by account ID
+// @Description  Delete account by account ID. Requires a recent login or an X-Elevation-Token.
 // @Tags         User Accounts
 // @Produce      json
 // @Param        id   path      string  true  "Account ID"
+// @Param        X-Elevation-Token  header  string  false  "Token from /auth/elevate"
 // @Success      200  {object}  gin.H "message: Account deleted successfully"
 // @Failure      400  {object}  gin.H "Account ID is required"
+// @Failure      401  {object}  gin.H "Recent authentication required"
 // @Failure      500  {object}  gin.H "Failed to delete account"
 // @Router       /user/account/{id} [delete]
 func (h *AccountHandler) DeleteAccountHandler(c *gin.Context) {
-	h.logger.Info("DeleteAccountHandler")
+	h.logger.InfoContext(c.Request.Context(), "DeleteAccountHandler")
 
 	accountID := c.Param("id")
 	if accountID == "" {
@@ -197,9 +206,15 @@ func (h *AccountHandler) DeleteAccountHandler(c *gin.Context) {
 		return
 	}
 
-	_, err := h.account.DeleteAccount(c.Request.Context(), &pb.DeleteAccountRequest{
+	// The delete response carries no state, so the audit record takes it
+	// from the cache, if the account is there, rather than from another
+	// call to the account service.
+	before, err := h.redis.GetAccountFromRedis(c.Request.Context(), accountID)
+	logBeforeLookup(c, h.logger, "account", accountID, err)
+	_, err = h.account.DeleteAccount(c.Request.Context(), &pb.DeleteAccountRequest{
 		Id: accountID,
 	})
+	h.audit.Log(c, audit.Entry{Action: "account.delete", Resource: "account", ResourceID: accountID, Before: before}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to delete account"})
 		return
@@ -2,6 +2,7 @@ package budgeting
 
 import (
 	pb "gateway-service/genproto/budget"
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
 	"gateway-service/internal/items/middleware"
 	"gateway-service/internal/items/msgbroker"
@@ -17,14 +18,16 @@ type BudgetHandler struct {
 	logger    *slog.Logger
 	msgbroker *msgbroker.MsgBroker
 	config    *config.Config
+	audit     *audit.Auditor
 }
 
-func NewBudgetHandler(budget pb.BudgetServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *BudgetHandler {
+func NewBudgetHandler(budget pb.BudgetServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor) *BudgetHandler {
 	return &BudgetHandler{
 		budget:    budget,
 		logger:    logger,
 		msgbroker: msgbroker,
 		config:    config,
+		audit:     auditor,
 	}
 }
 
@@ -42,7 +45,7 @@ func NewBudgetHandler(budget pb.BudgetServiceClient, logger *slog.Logger, msgbro
 // @Failure      500                   {object}  gin.H "Failed to create budget"
 // @Router       /user/budget [post]
 func (h *BudgetHandler) CreateBudgetHandler(c *gin.Context) {
-	h.logger.Info("CreateBudgetHandler")
+	h.logger.InfoContext(c.Request.Context(), "CreateBudgetHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -64,6 +67,7 @@ func (h *BudgetHandler) CreateBudgetHandler(c *gin.Context) {
 		StartDate:  req.StartDate,
 		EndDate:    req.EndDate,
 	})
+	h.audit.Log(c, audit.Entry{Action: "budget.create", Resource: "budget", ResourceID: resp.GetId(), After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to create budget"})
 		return
@@ -83,7 +87,7 @@ func (h *BudgetHandler) CreateBudgetHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get budgets"
 // @Router       /user/budget [get]
 func (h *BudgetHandler) GetBudgetsHandler(c *gin.Context) {
-	h.logger.Info("GetBudgetsHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetBudgetsHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -114,7 +118,7 @@ func (h *BudgetHandler) GetBudgetsHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to retrieve budget"
 // @Router       /user/budget/{id} [get]
 func (h *BudgetHandler) GetBudgetByIdHandler(c *gin.Context) {
-	h.logger.Info("GetBudgetByIdHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetBudgetByIdHandler")
 
 	id := c.Param("id")
 	if id == "" {
@@ -146,7 +150,7 @@ func (h *BudgetHandler) GetBudgetByIdHandler(c *gin.Context) {
 // @Failure      500                   {object}  gin.H "Failed to update budget"
 // @Router       /user/budget [put]
 func (h *BudgetHandler) UpdateBudgetHandler(c *gin.Context) {
-	h.logger.Info("UpdateBudgetHandler")
+	h.logger.InfoContext(c.Request.Context(), "UpdateBudgetHandler")
 
 	var req pb.UpdateBudgetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -160,7 +164,10 @@ func (h *BudgetHandler) UpdateBudgetHandler(c *gin.Context) {
 		return
 	}
 
+	before, err := h.budget.GetBudgetById(c.Request.Context(), &pb.GetBudgetByIdRequest{Id: req.Id})
+	logBeforeLookup(c, h.logger, "budget", req.Id, err)
 	err = h.msgbroker.BudgetUpdated(c.Request.Context(), body)
+	h.audit.Log(c, audit.Entry{Action: "budget.update", Resource: "budget", ResourceID: req.Id, Before: before, After: &req}, err)
 	if err != nil {
 		c.IndentedJSON(400, gin.H{"error": "Error while updating budjet"})
 	}
@@ -180,7 +187,7 @@ func (h *BudgetHandler) UpdateBudgetHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to delete budget"
 // @Router       /user/budget/{id} [delete]
 func (h *BudgetHandler) DeleteBudgetHandler(c *gin.Context) {
-	h.logger.Info("DeleteBudgetHandler")
+	h.logger.InfoContext(c.Request.Context(), "DeleteBudgetHandler")
 	id := c.Param("id")
 	if id == "" {
 		c.IndentedJSON(400, gin.H{"error": "Budget ID is required"})
@@ -190,6 +197,7 @@ func (h *BudgetHandler) DeleteBudgetHandler(c *gin.Context) {
 	_, err := h.budget.DeleteBudget(c.Request.Context(), &pb.DeleteBudgetRequest{
 		Id: id,
 	})
+	h.audit.Log(c, audit.Entry{Action: "budget.delete", Resource: "budget", ResourceID: id}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to delete budget"})
 		return
@@ -1,6 +1,7 @@
 package budgeting
 
 import (
+	"context"
 	"gateway-service/genproto/account"
 	"gateway-service/genproto/budget"
 	"gateway-service/genproto/category"
@@ -8,17 +9,25 @@ import (
 	"gateway-service/genproto/notification"
 	"gateway-service/genproto/report"
 	"gateway-service/genproto/transaction"
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/jobs"
+	"gateway-service/internal/items/metrics"
 	"gateway-service/internal/items/msgbroker"
 	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/pkg/certs"
+	"gateway-service/internal/pkg/reqctx"
 	"log"
 	"log/slog"
 
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 type BudgetClientConn struct {
+	conn *grpc.ClientConn
+
 	AccountClient      account.AccountServiceClient
 	BudgetClient       budget.BudgetServiceClient
 	CategoryClient     category.CategoryServiceClient
@@ -29,46 +38,144 @@ type BudgetClientConn struct {
 }
 
 func NewBudgetClientConn(config *config.Config) *BudgetClientConn {
+	conn := connect(config.Upstream.Budgeting)
+
 	return &BudgetClientConn{
-		AccountClient:      account.NewAccountServiceClient(connect("budgeting", config.Server.BudgetingPort)),
-		BudgetClient:       budget.NewBudgetServiceClient(connect("budgeting", config.Server.BudgetingPort)),
-		CategoryClient:     category.NewCategoryServiceClient(connect("budgeting", config.Server.BudgetingPort)),
-		GoalClient:         goal.NewGoalServiceClient(connect("budgeting", config.Server.BudgetingPort)),
-		NotificationClient: notification.NewNotificationServiceClient(connect("budgeting", config.Server.BudgetingPort)),
-		ReportClient:       report.NewReportServiceClient(connect("budgeting", config.Server.BudgetingPort)),
-		TransactionClient:  transaction.NewTransactionServiceClient(connect("budgeting", config.Server.BudgetingPort)),
+		conn:               conn,
+		AccountClient:      account.NewAccountServiceClient(conn),
+		BudgetClient:       budget.NewBudgetServiceClient(conn),
+		CategoryClient:     category.NewCategoryServiceClient(conn),
+		GoalClient:         goal.NewGoalServiceClient(conn),
+		NotificationClient: notification.NewNotificationServiceClient(conn),
+		ReportClient:       report.NewReportServiceClient(conn),
+		TransactionClient:  transaction.NewTransactionServiceClient(conn),
 	}
 }
 
 type BudgetingHandler struct {
+	clientConn *BudgetClientConn
+	redis      *redisservice.RedisService
+
 	AccountHandler      *AccountHandler
 	BudgetHandler       *BudgetHandler
 	CategoryHandler     *CategoryHandler
+	ExportHandler       *ExportHandler
 	GoalHandler         *GoalHandler
 	NotificationHandler *NotificationHandler
 	ReportHandler       *ReportHandler
 	TransactionHandler  *TransactionHandler
 }
 
-func NewBudgetingHandler(redis *redisservice.RedisService, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *BudgetingHandler {
+func NewBudgetingHandler(redis *redisservice.RedisService, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor, runner *jobs.Runner) *BudgetingHandler {
 	clientConn := NewBudgetClientConn(config)
 
 	return &BudgetingHandler{
-		AccountHandler:      NewAccountHandler(redis, clientConn.AccountClient, logger, msgbroker, config),
-		BudgetHandler:       NewBudgetHandler(clientConn.BudgetClient, logger, msgbroker, config),
-		CategoryHandler:     NewCategoryHandler(clientConn.CategoryClient, logger, msgbroker, config),
-		GoalHandler:         NewGoalHandler(clientConn.GoalClient, logger, msgbroker, config),
+		clientConn:          clientConn,
+		redis:               redis,
+		AccountHandler:      NewAccountHandler(redis, clientConn.AccountClient, logger, msgbroker, config, auditor),
+		BudgetHandler:       NewBudgetHandler(clientConn.BudgetClient, logger, msgbroker, config, auditor),
+		CategoryHandler:     NewCategoryHandler(clientConn.CategoryClient, logger, msgbroker, config, auditor),
+		ExportHandler:       NewExportHandler(clientConn, redis, runner, logger, config, auditor),
+		GoalHandler:         NewGoalHandler(clientConn.GoalClient, logger, msgbroker, config, auditor),
 		NotificationHandler: NewNotificationHandler(clientConn.NotificationClient, logger, msgbroker, config),
-		ReportHandler:       NewReportHandler(clientConn.ReportClient, logger, msgbroker, config),
-		TransactionHandler:  NewTransactionHandler(clientConn.NotificationClient, clientConn.TransactionClient, logger, msgbroker, config),
+		ReportHandler:       NewReportHandler(redis, clientConn.ReportClient, logger, msgbroker, config),
+		TransactionHandler:  NewTransactionHandler(redis, clientConn.NotificationClient, clientConn.TransactionClient, logger, msgbroker, config, auditor),
 	}
 }
 
-func connect(host, port string) *grpc.ClientConn {
-	address := host + port
-	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+// EraseUserData deletes userId's transactions, budgets, goals, accounts
+// and categories, the records referring to others first, along with the
+// cached accounts. The notification service cannot delete notifications;
+// it is left to handle user_deleted.
+func (h *BudgetingHandler) EraseUserData(ctx context.Context, userId string) error {
+	clients := h.clientConn
+
+	transactions, err := clients.TransactionClient.GetTransactions(ctx, &transaction.GetTransactionsRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+	for _, t := range transactions.Transactions {
+		if _, err := clients.TransactionClient.DeleteTransaction(ctx, &transaction.DeleteTransactionRequest{Id: t.Id}); err != nil {
+			return err
+		}
+	}
+
+	budgets, err := clients.BudgetClient.GetBudgets(ctx, &budget.GetBudgetsRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+	for _, b := range budgets.Budgets {
+		if _, err := clients.BudgetClient.DeleteBudget(ctx, &budget.DeleteBudgetRequest{Id: b.Id}); err != nil {
+			return err
+		}
+	}
+
+	goals, err := clients.GoalClient.GetGoals(ctx, &goal.GetGoalsRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+	for _, g := range goals.Goals {
+		if _, err := clients.GoalClient.DeleteGoal(ctx, &goal.DeleteGoalRequest{Id: g.Id}); err != nil {
+			return err
+		}
+	}
+
+	accounts, err := clients.AccountClient.GetAccounts(ctx, &account.GetAccountsRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts.Accounts {
+		if _, err := clients.AccountClient.DeleteAccount(ctx, &account.DeleteAccountRequest{Id: a.Id}); err != nil {
+			return err
+		}
+		if err := h.redis.DeleteAccountFromRedis(ctx, a.Id); err != nil {
+			return err
+		}
+	}
+
+	categories, err := clients.CategoryClient.GetCategories(ctx, &category.GetCategoriesRequest{UserId: userId})
+	if err != nil {
+		return err
+	}
+	for _, c := range categories.Categories {
+		if _, err := clients.CategoryClient.DeleteCategory(ctx, &category.DeleteCategoryRequest{Id: c.Id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Conn returns the connection shared by all budgeting service clients.
+func (h *BudgetingHandler) Conn() *grpc.ClientConn {
+	return h.clientConn.conn
+}
+
+func (h *BudgetingHandler) Close() error {
+	return h.clientConn.conn.Close()
+}
+
+func connect(endpoint config.Endpoint) *grpc.ClientConn {
+	creds, err := certs.GRPCCredentials(endpoint.TLS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	conn, err := grpc.NewClient(endpoint.Address(),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithChainUnaryInterceptor(reqctx.UnaryClientInterceptor(), metrics.UnaryClientInterceptor()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+	)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return conn
 }
+
+// logBeforeLookup logs a failure to load the state of a resource for the
+// audit record of a change to it. The change goes ahead, audited without
+// its earlier state.
+func logBeforeLookup(c *gin.Context, logger *slog.Logger, resource, id string, err error) {
+	if err != nil {
+		logger.WarnContext(c.Request.Context(), "Failed to load state for the audit record", "resource", resource, "id", id, "error", err.Error())
+	}
+}
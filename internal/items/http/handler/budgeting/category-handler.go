@@ -2,6 +2,7 @@ package budgeting
 
 import (
 	pb "gateway-service/genproto/category"
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
 	"gateway-service/internal/items/middleware"
 	"gateway-service/internal/items/msgbroker"
@@ -16,14 +17,16 @@ type CategoryHandler struct {
 	logger    *slog.Logger
 	msgbroker *msgbroker.MsgBroker
 	config    *config.Config
+	audit     *audit.Auditor
 }
 
-func NewCategoryHandler(category pb.CategoryServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *CategoryHandler {
+func NewCategoryHandler(category pb.CategoryServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor) *CategoryHandler {
 	return &CategoryHandler{
 		category:  category,
 		logger:    logger,
 		msgbroker: msgbroker,
 		config:    config,
+		audit:     auditor,
 	}
 }
 
@@ -41,7 +44,7 @@ func NewCategoryHandler(category pb.CategoryServiceClient, logger *slog.Logger,
 // @Failure      500                     {object}  gin.H "Failed to create category"
 // @Router       /user/category [post]
 func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
-	h.logger.Info("CreateCategoryHandler")
+	h.logger.InfoContext(c.Request.Context(), "CreateCategoryHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -60,6 +63,7 @@ func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
 		Name:   req.Name,
 		Type:   req.Type,
 	})
+	h.audit.Log(c, audit.Entry{Action: "category.create", Resource: "category", ResourceID: resp.GetId(), After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to create category"})
 		return
@@ -79,7 +83,7 @@ func (h *CategoryHandler) CreateCategoryHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get categories"
 // @Router       /user/category [get]
 func (h *CategoryHandler) GetCategoriesHandler(c *gin.Context) {
-	h.logger.Info("GetCategoriesHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetCategoriesHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -110,7 +114,7 @@ func (h *CategoryHandler) GetCategoriesHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to retrieve category"
 // @Router       /user/category/{id} [get]
 func (h *CategoryHandler) GetCategoryByIdHandler(c *gin.Context) {
-	h.logger.Info("GetCategoryByIdHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetCategoryByIdHandler")
 
 	id := c.Param("id")
 	if id == "" {
@@ -142,7 +146,7 @@ func (h *CategoryHandler) GetCategoryByIdHandler(c *gin.Context) {
 // @Failure      500                     {object}  gin.H "Failed to update category"
 // @Router       /user/category [put]
 func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
-	h.logger.Info("UpdateCategoryHandler")
+	h.logger.InfoContext(c.Request.Context(), "UpdateCategoryHandler")
 
 	var req pb.UpdateCategoryRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -150,7 +154,10 @@ func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
 		return
 	}
 
+	before, err := h.category.GetCategoryById(c.Request.Context(), &pb.GetCategoryByIdRequest{Id: req.Id})
+	logBeforeLookup(c, h.logger, "category", req.Id, err)
 	resp, err := h.category.UpdateCategory(c.Request.Context(), &req)
+	h.audit.Log(c, audit.Entry{Action: "category.update", Resource: "category", ResourceID: req.Id, Before: before, After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to update category"})
 		return
@@ -171,7 +178,7 @@ func (h *CategoryHandler) UpdateCategoryHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to delete category"
 // @Router       /user/category/{id} [delete]
 func (h *CategoryHandler) DeleteCategoryHandler(c *gin.Context) {
-	h.logger.Info("DeleteCategoryHandler")
+	h.logger.InfoContext(c.Request.Context(), "DeleteCategoryHandler")
 	id := c.Param("id")
 	if id == "" {
 		c.IndentedJSON(400, gin.H{"error": "Category ID is required"})
@@ -181,6 +188,7 @@ func (h *CategoryHandler) DeleteCategoryHandler(c *gin.Context) {
 	_, err := h.category.DeleteCategory(c.Request.Context(), &pb.DeleteCategoryRequest{
 		Id: id,
 	})
+	h.audit.Log(c, audit.Entry{Action: "category.delete", Resource: "category", ResourceID: id}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to delete category"})
 		return
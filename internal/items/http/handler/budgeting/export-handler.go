@@ -0,0 +1,336 @@
+package budgeting
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
+	"net/url"
+	"strconv"
+	"time"
+
+	"gateway-service/genproto/account"
+	"gateway-service/genproto/budget"
+	"gateway-service/genproto/category"
+	"gateway-service/genproto/goal"
+	"gateway-service/genproto/notification"
+	"gateway-service/genproto/transaction"
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/export"
+	"gateway-service/internal/items/jobs"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/profile"
+	"gateway-service/internal/pkg/signedtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+const exportPurpose = "export"
+
+// The reasons a job failed are shown to the user instead of the error.
+const (
+	exportFailedReason   = "Your data could not be collected, please try again later"
+	exportTooLargeReason = "Your data is too large to export as one archive"
+)
+
+var errExportTooLarge = errors.New("export archive is too large")
+
+// cappedBuffer holds an archive of at most max bytes.
+type cappedBuffer struct {
+	bytes.Buffer
+	max int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.max {
+		return 0, errExportTooLarge
+	}
+	return b.Buffer.Write(p)
+}
+
+type ExportHandler struct {
+	clients *BudgetClientConn
+	redis   *redisservice.RedisService
+	jobs    *jobs.Runner
+	logger  *slog.Logger
+	config  *config.Config
+	audit   *audit.Auditor
+}
+
+func NewExportHandler(clients *BudgetClientConn, redis *redisservice.RedisService, jobs *jobs.Runner, logger *slog.Logger, config *config.Config, auditor *audit.Auditor) *ExportHandler {
+	return &ExportHandler{
+		clients: clients,
+		redis:   redis,
+		jobs:    jobs,
+		logger:  logger,
+		config:  config,
+		audit:   auditor,
+	}
+}
+
+func toExportJob(job redisservice.ExportJob) models.ExportJob {
+	result := models.ExportJob{
+		ID:        job.ID,
+		Status:    job.Status,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt.UTC(),
+		Size:      job.Size,
+	}
+	if !job.FinishedAt.IsZero() {
+		finished := job.FinishedAt.UTC()
+		result.FinishedAt = &finished
+	}
+	return result
+}
+
+// StartExportHandler godoc
+// @Summary      Export personal data
+// @Security     BearerAuth
+// @Description  Start collecting all of the caller's accounts, budgets, categories, goals, transactions and notifications into a ZIP archive with JSON and CSV files and a manifest. Poll the job at the Location header until it is done.
+// @Tags         User Export
+// @Produce      json
+// @Success      202  {object}  models.ExportJob
+// @Failure      403  {object}  gin.H
+// @Failure      429  {object}  gin.H "Too many exports"
+// @Failure      500  {object}  gin.H
+// @Router       /user/export [post]
+func (h *ExportHandler) StartExportHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "StartExportHandler called")
+	userId := middleware.GetUser_id(c, h.config)
+
+	allowed, wait, err := h.redis.AllowExport(c.Request.Context(), userId, h.config.Export.MaxPerWindow, h.config.Export.Window)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if !allowed {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.IndentedJSON(429, gin.H{"error": "Too many exports, please try again later"})
+		return
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	job := redisservice.ExportJob{
+		ID:        hex.EncodeToString(b),
+		UserID:    userId,
+		Status:    redisservice.ExportPending,
+		CreatedAt: time.Now(),
+	}
+	err = h.redis.CreateExportJob(c.Request.Context(), job, h.config.Export.Retention, job.CreatedAt.Add(h.config.Export.Timeout))
+	h.audit.Log(c, audit.Entry{Action: "export.create", Resource: "export", ResourceID: job.ID}, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.jobs.Go("export", h.config.Export.Timeout, func(ctx context.Context) error {
+		return h.run(ctx, job)
+	})
+
+	c.Header("Location", "/user/export/"+job.ID)
+	c.IndentedJSON(202, toExportJob(job))
+}
+
+// GetExportHandler godoc
+// @Summary      Get a data export
+// @Security     BearerAuth
+// @Description  Get the status of an export. Once it is done the response has a signed download link that expires after a few minutes; fetch the job again for a new one.
+// @Tags         User Export
+// @Produce      json
+// @Param        id   path      string  true  "Export ID"
+// @Success      200  {object}  models.ExportJob
+// @Failure      403  {object}  gin.H
+// @Failure      404  {object}  gin.H
+// @Failure      500  {object}  gin.H
+// @Router       /user/export/{id} [get]
+func (h *ExportHandler) GetExportHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "GetExportHandler called")
+	job, err := h.redis.GetExportJob(c.Request.Context(), c.Param("id"))
+	if errors.Is(err, redisservice.ErrTokenNotFound) || (err == nil && job.UserID != middleware.GetUser_id(c, h.config)) {
+		c.IndentedJSON(404, gin.H{"error": "Export not found or expired"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	result := toExportJob(job)
+	if job.Status == redisservice.ExportDone {
+		link, expiresAt, err := h.downloadLink(c, job.ID)
+		if err != nil {
+			c.IndentedJSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		result.DownloadURL = link
+		result.DownloadExpiresAt = &expiresAt
+	}
+	c.IndentedJSON(200, result)
+}
+
+// DownloadExportHandler godoc
+// @Summary      Download a data export
+// @Description  Download the archive of an export through the signed link from /user/export/{id}
+// @Tags         User Export
+// @Produce      application/zip
+// @Param        token  query     string  true  "Signed download token"
+// @Success      200    {file}    file
+// @Failure      400    {object}  gin.H
+// @Failure      500    {object}  gin.H
+// @Router       /export/download [get]
+func (h *ExportHandler) DownloadExportHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "DownloadExportHandler called")
+	id, err := signedtoken.Parse(c.Query("token"), exportPurpose, h.config.JWTSecrets(), time.Now())
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+
+	jobId, err := h.redis.LookupToken(c.Request.Context(), exportPurpose, id)
+	var archive []byte
+	if err == nil {
+		archive, err = h.redis.ExportArchive(c.Request.Context(), jobId)
+	}
+	if errors.Is(err, redisservice.ErrTokenNotFound) {
+		c.IndentedJSON(400, gin.H{"error": "Invalid or expired download link"})
+		return
+	}
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="export-`+jobId+`.zip"`)
+	c.Header("Cache-Control", "no-store")
+	c.Data(200, "application/zip", archive)
+}
+
+// downloadLink returns a signed link to the archive of job jobId. Only the
+// newest link of a job works.
+func (h *ExportHandler) downloadLink(c *gin.Context, jobId string) (string, time.Time, error) {
+	ttl := h.config.Export.LinkTTL
+	expiresAt := time.Now().Add(ttl)
+	token, id, err := signedtoken.New(h.config.JWTSecrets()[0], exportPurpose, expiresAt)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := h.redis.StoreToken(c.Request.Context(), exportPurpose, id, jobId, ttl); err != nil {
+		return "", time.Time{}, err
+	}
+
+	link, err := url.Parse(h.config.Export.DownloadURL)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String(), expiresAt.UTC(), nil
+}
+
+// run builds the archive of job and stores it, or marks the job failed.
+func (h *ExportHandler) run(ctx context.Context, job redisservice.ExportJob) error {
+	archive := &cappedBuffer{max: h.config.Export.MaxSizeMB << 20}
+	err := h.build(ctx, job, archive)
+	if err == nil {
+		err = h.redis.CompleteExportJob(ctx, job.ID, archive.Bytes(), time.Now())
+	}
+	if err != nil {
+		reason := exportFailedReason
+		if errors.Is(err, errExportTooLarge) {
+			reason = exportTooLargeReason
+		}
+		// The job's context may be what failed.
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		return errors.Join(err, h.redis.FailExportJob(ctx, job.ID, reason, time.Now()))
+	}
+	return nil
+}
+
+// FailStaleJobs marks jobs failed that are still pending after
+// Export.Timeout because the gateway running them stopped.
+func (h *ExportHandler) FailStaleJobs(ctx context.Context) error {
+	failed, err := h.redis.FailStaleExportJobs(ctx, exportFailedReason, time.Now())
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		h.logger.WarnContext(ctx, "Marked abandoned export jobs failed", "jobs", failed)
+	}
+	return nil
+}
+
+func (h *ExportHandler) build(ctx context.Context, job redisservice.ExportJob, archive io.Writer) error {
+	prefs, err := profile.Load(ctx, h.redis, job.UserID, h.config.Profile.Preferences())
+	if err != nil {
+		return err
+	}
+	amount := func(v float32) string { return prefs.FormatAmount(float64(v)) }
+	date := func(v string) string { return export.Date(prefs, v) }
+
+	accounts, err := h.clients.AccountClient.GetAccounts(ctx, &account.GetAccountsRequest{UserId: job.UserID})
+	if err != nil {
+		return err
+	}
+	budgets, err := h.clients.BudgetClient.GetBudgets(ctx, &budget.GetBudgetsRequest{UserId: job.UserID})
+	if err != nil {
+		return err
+	}
+	categories, err := h.clients.CategoryClient.GetCategories(ctx, &category.GetCategoriesRequest{UserId: job.UserID})
+	if err != nil {
+		return err
+	}
+	goals, err := h.clients.GoalClient.GetGoals(ctx, &goal.GetGoalsRequest{UserId: job.UserID})
+	if err != nil {
+		return err
+	}
+	transactions, err := h.clients.TransactionClient.GetTransactions(ctx, &transaction.GetTransactionsRequest{UserId: job.UserID})
+	if err != nil {
+		return err
+	}
+	notifications, err := h.clients.NotificationClient.GetNotifications(ctx, &notification.GetNotificationsRequest{UserId: job.UserID})
+	if err != nil {
+		return err
+	}
+
+	sections := []export.Section{
+		{Name: "accounts", Records: accounts.Accounts, Columns: []string{"id", "name", "type", "balance", "currency", "created_at", "updated_at"}},
+		{Name: "budgets", Records: budgets.Budgets, Columns: []string{"id", "category_id", "amount", "period", "start_date", "end_date", "created_at", "updated_at"}},
+		{Name: "categories", Records: categories.Categories, Columns: []string{"id", "name", "type", "created_at", "updated_at"}},
+		{Name: "goals", Records: goals.Goals, Columns: []string{"id", "name", "target_amount", "current_amount", "deadline", "status", "created_at", "updated_at"}},
+		{Name: "transactions", Records: transactions.Transactions, Columns: []string{"id", "account_id", "category_id", "amount", "type", "description", "date", "created_at", "updated_at"}},
+		{Name: "notifications", Records: notifications.Notifications, Columns: []string{"id", "message", "is_read", "created_at"}},
+	}
+	for _, a := range accounts.Accounts {
+		sections[0].Rows = append(sections[0].Rows, []string{a.Id, a.Name, a.Type, amount(a.Balance), a.Currency, date(a.CreatedAt), date(a.UpdatedAt)})
+	}
+	for _, b := range budgets.Budgets {
+		sections[1].Rows = append(sections[1].Rows, []string{b.Id, b.CategoryId, amount(b.Amount), b.Period, date(b.StartDate), date(b.EndDate), date(b.CreatedAt), date(b.UpdatedAt)})
+	}
+	for _, c := range categories.Categories {
+		sections[2].Rows = append(sections[2].Rows, []string{c.Id, c.Name, c.Type, date(c.CreatedAt), date(c.UpdatedAt)})
+	}
+	for _, g := range goals.Goals {
+		sections[3].Rows = append(sections[3].Rows, []string{g.Id, g.Name, amount(g.TargetAmount), amount(g.CurrentAmount), date(g.Deadline), g.Status, date(g.CreatedAt), date(g.UpdatedAt)})
+	}
+	for _, t := range transactions.Transactions {
+		sections[4].Rows = append(sections[4].Rows, []string{t.Id, t.AccountId, t.CategoryId, amount(t.Amount), t.Type, t.Description, date(t.Date), date(t.CreatedAt), date(t.UpdatedAt)})
+	}
+	for _, n := range notifications.Notifications {
+		sections[5].Rows = append(sections[5].Rows, []string{n.Id, n.Message, strconv.FormatBool(n.IsRead), date(n.CreatedAt)})
+	}
+
+	return export.Write(archive, export.Manifest{UserID: job.UserID, CreatedAt: time.Now().UTC(), Profile: prefs}, sections)
+}
@@ -2,6 +2,7 @@ package budgeting
 
 import (
 	pb "gateway-service/genproto/goal"
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
 	"gateway-service/internal/items/middleware"
 	"gateway-service/internal/items/msgbroker"
@@ -17,14 +18,16 @@ type GoalHandler struct {
 	logger    *slog.Logger
 	msgbroker *msgbroker.MsgBroker
 	config    *config.Config
+	audit     *audit.Auditor
 }
 
-func NewGoalHandler(goal pb.GoalServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *GoalHandler {
+func NewGoalHandler(goal pb.GoalServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor) *GoalHandler {
 	return &GoalHandler{
 		goal:      goal,
 		logger:    logger,
 		msgbroker: msgbroker,
 		config:    config,
+		audit:     auditor,
 	}
 }
 
@@ -42,7 +45,7 @@ func NewGoalHandler(goal pb.GoalServiceClient, logger *slog.Logger, msgbroker *m
 // @Failure      500                {object}  gin.H "Failed to create goal"
 // @Router       /user/goal [post]
 func (h *GoalHandler) CreateGoalHandler(c *gin.Context) {
-	h.logger.Info("CreateGoalHandler")
+	h.logger.InfoContext(c.Request.Context(), "CreateGoalHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -64,6 +67,7 @@ func (h *GoalHandler) CreateGoalHandler(c *gin.Context) {
 		Deadline:      req.Deadline,
 		Status:        req.Status,
 	})
+	h.audit.Log(c, audit.Entry{Action: "goal.create", Resource: "goal", ResourceID: resp.GetId(), After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to create goal"})
 		return
@@ -83,7 +87,7 @@ func (h *GoalHandler) CreateGoalHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get goals"
 // @Router       /user/goal [get]
 func (h *GoalHandler) GetGoalsHandler(c *gin.Context) {
-	h.logger.Info("GetGoalsHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetGoalsHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -115,7 +119,7 @@ func (h *GoalHandler) GetGoalsHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get goal"
 // @Router       /user/goal/{id} [get]
 func (h *GoalHandler) GetGoalByIdHandler(c *gin.Context) {
-	h.logger.Info("GetGoalByIdHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetGoalByIdHandler")
 	id := c.Param("id")
 	if id == "" {
 		c.IndentedJSON(400, gin.H{"error": "Category ID is required"})
@@ -146,7 +150,7 @@ func (h *GoalHandler) GetGoalByIdHandler(c *gin.Context) {
 // @Failure      500                {object}  gin.H "Failed to update goal"
 // @Router       /user/goal [put]
 func (h *GoalHandler) UpdateGoalHandler(c *gin.Context) {
-	h.logger.Info("UpdateGoalHandler")
+	h.logger.InfoContext(c.Request.Context(), "UpdateGoalHandler")
 
 	var req pb.UpdateGoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -160,7 +164,10 @@ func (h *GoalHandler) UpdateGoalHandler(c *gin.Context) {
 		return
 	}
 
+	before, err := h.goal.GetGoalById(c.Request.Context(), &pb.GetGoalByIdRequest{Id: req.Id})
+	logBeforeLookup(c, h.logger, "goal", req.Id, err)
 	err = h.msgbroker.GoalProgressUpdated(c.Request.Context(), body)
+	h.audit.Log(c, audit.Entry{Action: "goal.update", Resource: "goal", ResourceID: req.Id, Before: before, After: &req}, err)
 	if err != nil {
 		c.IndentedJSON(400, gin.H{"error": "Error while updating goal"})
 	}
@@ -180,7 +187,7 @@ func (h *GoalHandler) UpdateGoalHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to delete goal"
 // @Router       /user/goal/{id} [delete]
 func (h *GoalHandler) DeleteGoalHandler(c *gin.Context) {
-	h.logger.Info("DeleteGoalHandler")
+	h.logger.InfoContext(c.Request.Context(), "DeleteGoalHandler")
 	id := c.Param("id")
 	if id == "" {
 		c.IndentedJSON(400, gin.H{"error": "Category ID is required"})
@@ -190,6 +197,7 @@ func (h *GoalHandler) DeleteGoalHandler(c *gin.Context) {
 	_, err := h.goal.DeleteGoal(c.Request.Context(), &pb.DeleteGoalRequest{
 		Id: id,
 	})
+	h.audit.Log(c, audit.Entry{Action: "goal.delete", Resource: "goal", ResourceID: id}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to delete goal"})
 		return
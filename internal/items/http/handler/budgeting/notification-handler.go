@@ -38,7 +38,7 @@ func NewNotificationHandler(notification pb.NotificationServiceClient, logger *s
 // @Failure      500  {object}  gin.H "Failed to retrieve notifications"
 // @Router       /user/notification/ [get]
 func (h *NotificationHandler) GetNotifications(c *gin.Context) {
-	h.logger.Info("GetNotifications")
+	h.logger.InfoContext(c.Request.Context(), "GetNotifications")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -70,7 +70,7 @@ func (h *NotificationHandler) GetNotifications(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to mark notification as read"
 // @Router       /user/notification/{id} [put]
 func (h *NotificationHandler) MarkNotificationAsRead(c *gin.Context) {
-	h.logger.Info("MarkNotificationAsRead")
+	h.logger.InfoContext(c.Request.Context(), "MarkNotificationAsRead")
 
 	id := c.Param("id")
 	if id == "" {
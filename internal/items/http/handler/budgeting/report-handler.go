@@ -6,20 +6,24 @@ import (
 	"gateway-service/internal/items/middleware"
 	"gateway-service/internal/items/msgbroker"
 	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/profile"
 	"log/slog"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type ReportHandler struct {
+	profiles  profile.Store
 	report    pb.ReportServiceClient
 	logger    *slog.Logger
 	msgbroker *msgbroker.MsgBroker
 	config    *config.Config
 }
 
-func NewReportHandler(report pb.ReportServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *ReportHandler {
+func NewReportHandler(profiles profile.Store, report pb.ReportServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *ReportHandler {
 	return &ReportHandler{
+		profiles:  profiles,
 		report:    report,
 		logger:    logger,
 		msgbroker: msgbroker,
@@ -27,10 +31,39 @@ func NewReportHandler(report pb.ReportServiceClient, logger *slog.Logger, msgbro
 	}
 }
 
+// dateRange fills a missing start or end date from the user's current
+// period, by default the month to date in their timezone. It answers the
+// request itself and returns false if that fails.
+func (h *ReportHandler) dateRange(c *gin.Context, userId, period, start, end string) (string, string, bool) {
+	if start != "" && end != "" {
+		return start, end, true
+	}
+	if period == "" {
+		period = profile.PeriodMonth
+	}
+	prefs, err := profile.Load(c.Request.Context(), h.profiles, userId, h.config.Profile.Preferences())
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return "", "", false
+	}
+	from, to, err := prefs.Range(period, time.Now())
+	if err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return "", "", false
+	}
+	if start == "" {
+		start = from
+	}
+	if end == "" {
+		end = to
+	}
+	return start, end, true
+}
+
 // GetSpendingReportHandler godoc
 // @Summary      Get spending report
 // @Security     BearerAuth
-// @Description  Retrieve a spending report for a user between the specified start and end dates
+// @Description  Retrieve a spending report for a user between the specified start and end dates. Missing dates default to the current period in the user's timezone, the month unless period is week or year.
 // @Tags         User Reports
 // @Produce      json
 // @Param        request  body  models.GetSpendingReportRequest  true  "Get Spending Report Request"
@@ -39,7 +72,7 @@ func NewReportHandler(report pb.ReportServiceClient, logger *slog.Logger, msgbro
 // @Failure      500     {object}  gin.H "Failed to retrieve spending report"
 // @Router       /user/report/spending [post]
 func (h *ReportHandler) GetSpendingReportHandler(c *gin.Context) {
-	h.logger.Info("GetSpendingReportHandler called")
+	h.logger.InfoContext(c.Request.Context(), "GetSpendingReportHandler called")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -52,11 +85,15 @@ func (h *ReportHandler) GetSpendingReportHandler(c *gin.Context) {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	start, end, ok := h.dateRange(c, userId, req.Period, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
 
 	resp, err := h.report.GetSpendingReport(c.Request.Context(), &pb.GetSpendingReportRequest{
 		UserId:    userId,
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
+		StartDate: start,
+		EndDate:   end,
 	})
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
@@ -69,7 +106,7 @@ func (h *ReportHandler) GetSpendingReportHandler(c *gin.Context) {
 // GetIncomeReportHandler godoc
 // @Summary      Get income report
 // @Security     BearerAuth
-// @Description  Retrieve an income report for a user between the specified start and end dates
+// @Description  Retrieve an income report for a user between the specified start and end dates. Missing dates default to the current period in the user's timezone, the month unless period is week or year.
 // @Tags         User Reports
 // @Produce      json
 // @Param        request  body  models.GetIncomeReportRequest  true  "Get Income Report Request"
@@ -78,7 +115,7 @@ func (h *ReportHandler) GetSpendingReportHandler(c *gin.Context) {
 // @Failure      500     {object}  gin.H "Failed to retrieve income report"
 // @Router       /user/report/incoming [post]
 func (h *ReportHandler) GetIncomeReportHandler(c *gin.Context) {
-	h.logger.Info("GetIncomeReportHandler called")
+	h.logger.InfoContext(c.Request.Context(), "GetIncomeReportHandler called")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -91,11 +128,15 @@ func (h *ReportHandler) GetIncomeReportHandler(c *gin.Context) {
 		c.IndentedJSON(400, gin.H{"error": err.Error()})
 		return
 	}
+	start, end, ok := h.dateRange(c, userId, req.Period, req.StartDate, req.EndDate)
+	if !ok {
+		return
+	}
 
 	resp, err := h.report.GetIncomeReport(c.Request.Context(), &pb.GetIncomeReportRequest{
 		UserId:    userId,
-		StartDate: req.StartDate,
-		EndDate:   req.EndDate,
+		StartDate: start,
+		EndDate:   end,
 	})
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": err.Error()})
@@ -118,7 +159,7 @@ func (h *ReportHandler) GetIncomeReportHandler(c *gin.Context) {
 // @Failure      500    {object}  gin.H "Failed to retrieve budget performance report"
 // @Router       /user/report/bugdet [post]
 func (h *ReportHandler) GetBudgetPerformanceReportHandler(c *gin.Context) {
-	h.logger.Info("GetBudgetPerformanceReportHandler called")
+	h.logger.InfoContext(c.Request.Context(), "GetBudgetPerformanceReportHandler called")
 
 	id := c.Param("id")
 	if id == "" {
@@ -157,7 +198,7 @@ func (h *ReportHandler) GetBudgetPerformanceReportHandler(c *gin.Context) {
 // @Failure      500    {object}  gin.H "Failed to retrieve goal progress report"
 // @Router       /user/report/goal [post]
 func (h *ReportHandler) GetGoalProgressReportHandler(c *gin.Context) {
-	h.logger.Info("GetGoalProgressReportHandler called")
+	h.logger.InfoContext(c.Request.Context(), "GetGoalProgressReportHandler called")
 
 	id := c.Param("id")
 	if id == "" {
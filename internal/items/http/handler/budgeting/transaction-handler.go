@@ -6,10 +6,12 @@ import (
 	pb "gateway-service/genproto/transaction"
 	"time"
 
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
 	"gateway-service/internal/items/middleware"
 	"gateway-service/internal/items/msgbroker"
 	"gateway-service/internal/models"
+	"gateway-service/internal/pkg/profile"
 	"log/slog"
 
 	"github.com/gin-gonic/gin"
@@ -17,27 +19,31 @@ import (
 )
 
 type TransactionHandler struct {
+	profiles     profile.Store
 	transaction  pb.TransactionServiceClient
 	notification not_pb.NotificationServiceClient
 	logger       *slog.Logger
 	msgbroker    *msgbroker.MsgBroker
 	config       *config.Config
+	audit        *audit.Auditor
 }
 
-func NewTransactionHandler(notification not_pb.NotificationServiceClient, transaction pb.TransactionServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config) *TransactionHandler {
+func NewTransactionHandler(profiles profile.Store, notification not_pb.NotificationServiceClient, transaction pb.TransactionServiceClient, logger *slog.Logger, msgbroker *msgbroker.MsgBroker, config *config.Config, auditor *audit.Auditor) *TransactionHandler {
 	return &TransactionHandler{
+		profiles:     profiles,
 		transaction:  transaction,
 		notification: notification,
 		logger:       logger,
 		msgbroker:    msgbroker,
 		config:       config,
+		audit:        auditor,
 	}
 }
 
 // CreateTransactionHandler godoc
 // @Summary      Create a transaction
 // @Security     BearerAuth
-// @Description  Create a new financial transaction for the authenticated user
+// @Description  Create a new financial transaction for the authenticated user, dated today in the user's timezone. Without account_id it goes to the user's default account.
 // @Tags         User Transactions
 // @Accept       json
 // @Produce      json
@@ -48,7 +54,7 @@ func NewTransactionHandler(notification not_pb.NotificationServiceClient, transa
 // @Failure      500                       {object}  gin.H "Failed to create transaction"
 // @Router       /user/transaction [post]
 func (h *TransactionHandler) CreateTransactionHandler(c *gin.Context) {
-	h.logger.Info("CreateTransactionHandler")
+	h.logger.InfoContext(c.Request.Context(), "CreateTransactionHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -62,6 +68,15 @@ func (h *TransactionHandler) CreateTransactionHandler(c *gin.Context) {
 		return
 	}
 
+	prefs, err := profile.Load(c.Request.Context(), h.profiles, userId, h.config.Profile.Preferences())
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	if req.AccountID == "" {
+		req.AccountID = prefs.DefaultAccountID
+	}
+
 	var request = pb.CreateTransactionRequest{
 		UserId:      userId,
 		AccountId:   req.AccountID,
@@ -69,7 +84,7 @@ func (h *TransactionHandler) CreateTransactionHandler(c *gin.Context) {
 		Amount:      req.Amount,
 		Type:        req.Type,
 		Description: req.Description,
-		Date:        time.Now().Format("2006-01-02"),
+		Date:        prefs.Date(time.Now()),
 	}
 
 	body, err := protojson.Marshal(&request)
@@ -79,6 +94,7 @@ func (h *TransactionHandler) CreateTransactionHandler(c *gin.Context) {
 	}
 
 	err = h.msgbroker.TransactionCreated(c.Request.Context(), body)
+	h.audit.Log(c, audit.Entry{Action: "transaction.create", Resource: "transaction", After: &request}, err)
 	if err != nil {
 		c.IndentedJSON(400, gin.H{"error": "Error while creating transaction"})
 	}
@@ -113,7 +129,7 @@ func (h *TransactionHandler) CreateTransactionHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get transactions"
 // @Router       /user/transaction [get]
 func (h *TransactionHandler) GetTransactionsHandler(c *gin.Context) {
-	h.logger.Info("GetTransactionsHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetTransactionsHandler")
 
 	userId := middleware.GetUser_id(c, h.config)
 	if userId == "" {
@@ -144,7 +160,7 @@ func (h *TransactionHandler) GetTransactionsHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to get transaction"
 // @Router       /user/transaction/{id} [get]
 func (h *TransactionHandler) GetTransactionByIdHandler(c *gin.Context) {
-	h.logger.Info("GetTransactionByIdHandler")
+	h.logger.InfoContext(c.Request.Context(), "GetTransactionByIdHandler")
 	id := c.Param("id")
 	if id == "" {
 		c.IndentedJSON(400, gin.H{"error": "Invalid transaction ID"})
@@ -175,7 +191,7 @@ func (h *TransactionHandler) GetTransactionByIdHandler(c *gin.Context) {
 // @Failure      500                       {object}  gin.H "Failed to update transaction"
 // @Router       /user/transaction [put]
 func (h *TransactionHandler) UpdateTransactionHandler(c *gin.Context) {
-	h.logger.Info("UpdateTransactionHandler")
+	h.logger.InfoContext(c.Request.Context(), "UpdateTransactionHandler")
 
 	var req pb.UpdateTransactionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -183,7 +199,10 @@ func (h *TransactionHandler) UpdateTransactionHandler(c *gin.Context) {
 		return
 	}
 
+	before, err := h.transaction.GetTransactionById(c.Request.Context(), &pb.GetTransactionByIdRequest{Id: req.Id})
+	logBeforeLookup(c, h.logger, "transaction", req.Id, err)
 	resp, err := h.transaction.UpdateTransaction(c.Request.Context(), &req)
+	h.audit.Log(c, audit.Entry{Action: "transaction.update", Resource: "transaction", ResourceID: req.Id, Before: before, After: resp}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to update transaction"})
 		return
@@ -204,7 +223,7 @@ func (h *TransactionHandler) UpdateTransactionHandler(c *gin.Context) {
 // @Failure      500  {object}  gin.H "Failed to delete transaction"
 // @Router       /user/transaction/{id} [delete]
 func (h *TransactionHandler) DeleteTransactionHandler(c *gin.Context) {
-	h.logger.Info("DeleteTransactionHandler")
+	h.logger.InfoContext(c.Request.Context(), "DeleteTransactionHandler")
 	id := c.Param("id")
 	if id == "" {
 		c.IndentedJSON(400, gin.H{"error": "Invalid transaction ID"})
@@ -214,6 +233,7 @@ func (h *TransactionHandler) DeleteTransactionHandler(c *gin.Context) {
 	_, err := h.transaction.DeleteTransaction(c.Request.Context(), &pb.DeleteTransactionRequest{
 		Id: id,
 	})
+	h.audit.Log(c, audit.Entry{Action: "transaction.delete", Resource: "transaction", ResourceID: id}, err)
 	if err != nil {
 		c.IndentedJSON(500, gin.H{"error": "Failed to delete transaction"})
 		return
@@ -0,0 +1,66 @@
+package configuration
+
+import (
+	"log/slog"
+	"time"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigHandler struct {
+	store  *config.Store
+	logger *slog.Logger
+}
+
+func NewConfigHandler(store *config.Store, logger *slog.Logger) *ConfigHandler {
+	return &ConfigHandler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// GetConfigHandler godoc
+// @Summary Active configuration
+// @Security BearerAuth
+// @Description Returns the active config version, when it was loaded and the effective settings with secrets masked
+// @Tags Admin Config
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Router /admin/config [get]
+func (h *ConfigHandler) GetConfigHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "GetConfigHandler called")
+
+	snapshot := h.store.Snapshot()
+	c.IndentedJSON(200, gin.H{
+		"version":           snapshot.Version,
+		"checksum":          snapshot.Checksum,
+		"loaded_at":         snapshot.LoadedAt.Format(time.RFC3339),
+		"file":              snapshot.Config.File(),
+		"last_reload_error": h.store.LastError(),
+		"settings":          snapshot.Config.Effective(),
+	})
+}
+
+// ReloadConfigHandler godoc
+// @Summary Reload configuration
+// @Security BearerAuth
+// @Description Reloads the configuration from every source. An invalid configuration is rejected and the active one is kept.
+// @Tags Admin Config
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Router /admin/config/reload [post]
+func (h *ConfigHandler) ReloadConfigHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "ReloadConfigHandler called")
+
+	if err := h.store.Reload(); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error(), "version": h.store.Snapshot().Version})
+		return
+	}
+
+	c.IndentedJSON(200, gin.H{"message": "Config reloaded", "version": h.store.Snapshot().Version})
+}
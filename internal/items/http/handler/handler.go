@@ -1,28 +1,80 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 
+	"gateway-service/internal/items/audit"
 	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/healthcheck"
+	"gateway-service/internal/items/jobs"
+	"gateway-service/internal/items/lifecycle"
+	"gateway-service/internal/items/rbac"
 	"gateway-service/internal/items/redisservice"
 
+	audithandler "gateway-service/internal/items/http/handler/audit"
 	"gateway-service/internal/items/http/handler/auth"
 	"gateway-service/internal/items/http/handler/budgeting"
+	"gateway-service/internal/items/http/handler/configuration"
+	"gateway-service/internal/items/http/handler/health"
+	"gateway-service/internal/items/http/handler/profile"
 	msgbroker "gateway-service/internal/items/msgbroker"
-
-	"github.com/segmentio/kafka-go"
 )
 
 type Handler struct {
 	AuthRepo      *auth.AuthHandler
 	BudgetingRepo *budgeting.BudgetingHandler
+	HealthRepo    *health.HealthHandler
+	ConfigRepo    *configuration.ConfigHandler
+	AuditRepo     *audithandler.AuditHandler
+	ProfileRepo   *profile.ProfileHandler
+
+	// Redis is shared with middleware that checks user state.
+	Redis *redisservice.RedisService
+	// Auditor is shared with middleware that audits whole requests.
+	Auditor *audit.Auditor
+	// Jobs runs work started by requests, such as exports, and periodic
+	// work, such as account deletion, in the background; shutdown waits
+	// for it.
+	Jobs *jobs.Runner
 }
 
-func New(redis *redisservice.RedisService, logger *slog.Logger, config *config.Config, writer *kafka.Writer) *Handler {
-	msgbroker := msgbroker.NewMsgBroker(writer, logger)
+func New(redis *redisservice.RedisService, logger *slog.Logger, store *config.Store, broker *msgbroker.MsgBroker, lc *lifecycle.Lifecycle, auditor *audit.Auditor, roles *rbac.Registry) *Handler {
+	config := store.Config()
+
+	runner := jobs.New(logger)
+	authRepo := auth.NewAuthHandler(redis, logger, broker, config, auditor, roles)
+	budgetingRepo := budgeting.NewBudgetingHandler(redis, logger, broker, config, auditor, runner)
+
+	runner.Every("account deletion", config.Deletion.SweepInterval, config.Deletion.Timeout, func(ctx context.Context) error {
+		return authRepo.DeleteDueUsers(ctx, budgetingRepo.EraseUserData)
+	})
+	runner.Every("stale exports", config.Export.Timeout, config.Export.Timeout, budgetingRepo.ExportHandler.FailStaleJobs)
+
+	checker := healthcheck.New(
+		healthcheck.Check{Name: "redis", Probe: redis.Ping},
+		healthcheck.Check{Name: "kafka", Probe: func(ctx context.Context) error {
+			return msgbroker.CheckTopics(ctx, config)
+		}},
+		healthcheck.Check{Name: "auth", Probe: healthcheck.GRPC(authRepo.Conn())},
+		healthcheck.Check{Name: "budgeting", Probe: healthcheck.GRPC(budgetingRepo.Conn())},
+	)
 
 	return &Handler{
-		AuthRepo:      auth.NewAuthHandler(logger, config),
-		BudgetingRepo: budgeting.NewBudgetingHandler(redis, logger, msgbroker, config),
+		AuthRepo:      authRepo,
+		BudgetingRepo: budgetingRepo,
+		HealthRepo:    health.NewHealthHandler(checker, lc, broker, logger),
+		ConfigRepo:    configuration.NewConfigHandler(store, logger),
+		AuditRepo:     audithandler.NewAuditHandler(auditor, logger),
+		ProfileRepo:   profile.NewProfileHandler(redis, logger, config, auditor),
+		Redis:         redis,
+		Auditor:       auditor,
+		Jobs:          runner,
 	}
 }
+
+// Close closes the gRPC connections to the upstream services.
+func (h *Handler) Close() error {
+	return errors.Join(h.AuthRepo.Close(), h.BudgetingRepo.Close())
+}
@@ -0,0 +1,97 @@
+package health
+
+import (
+	"log/slog"
+	"runtime"
+	"time"
+
+	"gateway-service/internal/items/healthcheck"
+	"gateway-service/internal/items/lifecycle"
+	"gateway-service/internal/items/msgbroker"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	checker   *healthcheck.Checker
+	lifecycle *lifecycle.Lifecycle
+	msgbroker *msgbroker.MsgBroker
+	logger    *slog.Logger
+	startedAt time.Time
+}
+
+func NewHealthHandler(checker *healthcheck.Checker, lifecycle *lifecycle.Lifecycle, msgbroker *msgbroker.MsgBroker, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{
+		checker:   checker,
+		lifecycle: lifecycle,
+		msgbroker: msgbroker,
+		logger:    logger,
+		startedAt: time.Now(),
+	}
+}
+
+// LivenessHandler godoc
+// @Summary Liveness probe
+// @Description Reports that the gateway process is alive
+// @Tags Health
+// @Produce json
+// @Success 200 {object} gin.H
+// @Router /healthz [get]
+func (h *HealthHandler) LivenessHandler(c *gin.Context) {
+	c.IndentedJSON(200, gin.H{"status": "alive"})
+}
+
+// ReadinessHandler godoc
+// @Summary Readiness probe
+// @Description Reports per-dependency status and latency. Fails while the gateway is starting up or draining.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} healthcheck.Report
+// @Failure 503 {object} healthcheck.Report
+// @Router /readyz [get]
+func (h *HealthHandler) ReadinessHandler(c *gin.Context) {
+	state := h.lifecycle.State()
+	if state != lifecycle.Ready {
+		c.IndentedJSON(503, gin.H{"status": healthcheck.StatusDown, "state": state.String()})
+		return
+	}
+
+	report := h.checker.Run(c.Request.Context())
+	for i := range report.Dependencies {
+		report.Dependencies[i].Error = ""
+	}
+
+	if report.Status != healthcheck.StatusUp {
+		h.logger.WarnContext(c.Request.Context(), "Readiness check failed")
+		c.IndentedJSON(503, report)
+		return
+	}
+
+	c.IndentedJSON(200, report)
+}
+
+// StatusHandler godoc
+// @Summary Detailed gateway status
+// @Security BearerAuth
+// @Description Detailed dependency status with errors, lifecycle state, uptime and Kafka outbox size
+// @Tags Admin Status
+// @Produce json
+// @Success 200 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Router /admin/status [get]
+func (h *HealthHandler) StatusHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "StatusHandler called")
+
+	report := h.checker.Run(c.Request.Context())
+
+	c.IndentedJSON(200, gin.H{
+		"status":         report.Status,
+		"state":          h.lifecycle.State().String(),
+		"started_at":     h.startedAt.Format(time.RFC3339),
+		"uptime":         time.Since(h.startedAt).Round(time.Second).String(),
+		"go_version":     runtime.Version(),
+		"goroutines":     runtime.NumGoroutine(),
+		"outbox_pending": h.msgbroker.Pending(),
+		"dependencies":   report.Dependencies,
+	})
+}
@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gateway-service/internal/items/healthcheck"
+	"gateway-service/internal/items/lifecycle"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	up := func(ctx context.Context) error { return nil }
+	down := func(ctx context.Context) error { return errors.New("dial tcp redis:6379: connection refused") }
+
+	tests := []struct {
+		name   string
+		state  lifecycle.State
+		redis  func(ctx context.Context) error
+		status int
+		// probed reports whether dependencies are checked at all.
+		probed bool
+	}{
+		{"ready", lifecycle.Ready, up, http.StatusOK, true},
+		{"dependency down", lifecycle.Ready, down, http.StatusServiceUnavailable, true},
+		{"starting", lifecycle.Starting, up, http.StatusServiceUnavailable, false},
+		{"draining", lifecycle.Draining, up, http.StatusServiceUnavailable, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			life := lifecycle.New(logger, 0)
+			life.SetState(tt.state)
+			probed := false
+			checker := healthcheck.New(
+				healthcheck.Check{Name: "redis", Probe: func(ctx context.Context) error {
+					probed = true
+					return tt.redis(ctx)
+				}},
+				healthcheck.Check{Name: "auth", Probe: up},
+			)
+			h := NewHealthHandler(checker, life, nil, logger)
+
+			router := gin.New()
+			router.GET("/readyz", h.ReadinessHandler)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if probed != tt.probed {
+				t.Fatalf("probed = %v, want %v", probed, tt.probed)
+			}
+			if !tt.probed {
+				return
+			}
+
+			var report healthcheck.Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+			if len(report.Dependencies) != 2 || report.Dependencies[0].Name != "redis" || report.Dependencies[1].Name != "auth" {
+				t.Fatalf("dependencies = %+v, want redis and auth in order", report.Dependencies)
+			}
+			for _, d := range report.Dependencies {
+				if d.Error != "" {
+					t.Errorf("%s error %q is exposed on the public probe", d.Name, d.Error)
+				}
+			}
+			wantRedis := healthcheck.StatusUp
+			if rec.Code != http.StatusOK {
+				wantRedis = healthcheck.StatusDown
+			}
+			if report.Dependencies[0].Status != wantRedis || report.Dependencies[1].Status != healthcheck.StatusUp {
+				t.Fatalf("dependencies = %+v", report.Dependencies)
+			}
+		})
+	}
+}
+
+func TestLivenessHandlerIgnoresState(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	for _, state := range []lifecycle.State{lifecycle.Starting, lifecycle.Ready, lifecycle.Draining} {
+		t.Run(state.String(), func(t *testing.T) {
+			life := lifecycle.New(logger, 0)
+			life.SetState(state)
+			h := NewHealthHandler(healthcheck.New(), life, nil, logger)
+
+			router := gin.New()
+			router.GET("/healthz", h.LivenessHandler)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+			}
+		})
+	}
+}
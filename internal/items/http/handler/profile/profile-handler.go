@@ -0,0 +1,97 @@
+package profile
+
+import (
+	"log/slog"
+	"strings"
+
+	"gateway-service/internal/items/audit"
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/middleware"
+	"gateway-service/internal/models"
+	prefs "gateway-service/internal/pkg/profile"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProfileHandler struct {
+	store  prefs.Store
+	logger *slog.Logger
+	config *config.Config
+	audit  *audit.Auditor
+}
+
+func NewProfileHandler(store prefs.Store, logger *slog.Logger, config *config.Config, auditor *audit.Auditor) *ProfileHandler {
+	return &ProfileHandler{
+		store:  store,
+		logger: logger,
+		config: config,
+		audit:  auditor,
+	}
+}
+
+// GetProfileHandler godoc
+// @Summary Get profile preferences
+// @Security BearerAuth
+// @Description Get the caller's currency, locale, timezone, first day of week and default account. Settings the user has not chosen show the gateway's defaults.
+// @Tags User Profile
+// @Produce json
+// @Success 200 {object} models.Profile
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/profile [get]
+func (h *ProfileHandler) GetProfileHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "GetProfileHandler called")
+	p, err := prefs.Load(c.Request.Context(), h.store, middleware.GetUser_id(c, h.config), h.config.Profile.Preferences())
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.Profile(p))
+}
+
+// UpdateProfileHandler godoc
+// @Summary Update profile preferences
+// @Security BearerAuth
+// @Description Replace the caller's preferences. Empty fields fall back to the gateway's defaults. The timezone decides the date of new transactions and the default report ranges; the locale decides how exports format numbers and dates.
+// @Tags User Profile
+// @Accept json
+// @Produce json
+// @Param request body models.Profile true "Preferences"
+// @Success 200 {object} models.Profile
+// @Failure 400 {object} gin.H
+// @Failure 403 {object} gin.H
+// @Failure 500 {object} gin.H
+// @Router /user/profile [put]
+func (h *ProfileHandler) UpdateProfileHandler(c *gin.Context) {
+	h.logger.InfoContext(c.Request.Context(), "UpdateProfileHandler called")
+	var req models.Profile
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+	userId := middleware.GetUser_id(c, h.config)
+
+	p := prefs.Preferences(req)
+	p.Currency = strings.ToUpper(strings.TrimSpace(p.Currency))
+	p.Locale = strings.TrimSpace(p.Locale)
+	p.Timezone = strings.TrimSpace(p.Timezone)
+	p.FirstDayOfWeek = strings.ToLower(strings.TrimSpace(p.FirstDayOfWeek))
+	p.DefaultAccountID = strings.TrimSpace(p.DefaultAccountID)
+	if err := p.Validate(); err != nil {
+		c.IndentedJSON(400, gin.H{"error": err.Error()})
+		return
+	}
+
+	before, err := h.store.GetPreferences(c.Request.Context(), userId)
+	if err == nil {
+		err = h.store.SavePreferences(c.Request.Context(), userId, p)
+	}
+	h.audit.Log(c, audit.Entry{Action: "profile.update", Resource: "profile", ResourceID: userId, Before: before, After: &p}, err)
+	if err != nil {
+		c.IndentedJSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.IndentedJSON(200, models.Profile(p.WithDefaults(h.config.Profile.Preferences())))
+}
@@ -0,0 +1,93 @@
+// Package jobs runs work that outlives the request starting it, such as
+// data exports, and periodic work, and lets shutdown wait for it to
+// finish.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type Runner struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	stop   chan struct{}
+	once   sync.Once
+	wg     sync.WaitGroup
+	logger *slog.Logger
+}
+
+func New(logger *slog.Logger) *Runner {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Runner{
+		ctx:    ctx,
+		cancel: cancel,
+		stop:   make(chan struct{}),
+		logger: logger,
+	}
+}
+
+// Go runs fn in the background with a context that is cancelled after
+// timeout or when shutdown gives up waiting. A returned error is logged.
+func (r *Runner) Go(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ctx, cancel := context.WithTimeout(r.ctx, timeout)
+		defer cancel()
+
+		start := time.Now()
+		if err := fn(ctx); err != nil {
+			r.logger.Error("Background job failed", "job", name, "duration", time.Since(start).String(), "error", err.Error())
+			return
+		}
+		r.logger.Info("Background job completed", "job", name, "duration", time.Since(start).String())
+	}()
+}
+
+// Every runs fn each interval until shutdown, each run limited to
+// timeout. A returned error is logged and the next run happens as usual.
+func (r *Runner) Every(name string, interval, timeout time.Duration, fn func(ctx context.Context) error) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-r.stop:
+				return
+			case <-ticker.C:
+			}
+
+			ctx, cancel := context.WithTimeout(r.ctx, timeout)
+			if err := fn(ctx); err != nil {
+				r.logger.Error("Periodic job failed", "job", name, "error", err.Error())
+			}
+			cancel()
+		}
+	}()
+}
+
+// Shutdown stops periodic jobs and waits for running ones until ctx is
+// done, then cancels them and waits for them to return.
+func (r *Runner) Shutdown(ctx context.Context) error {
+	r.once.Do(func() { close(r.stop) })
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		r.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
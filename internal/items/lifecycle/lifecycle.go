@@ -0,0 +1,138 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type State int32
+
+const (
+	Starting State = iota
+	Ready
+	Draining
+)
+
+func (s State) String() string {
+	switch s {
+	case Starting:
+		return "starting"
+	case Ready:
+		return "ready"
+	case Draining:
+		return "draining"
+	default:
+		return "unknown"
+	}
+}
+
+// Lifecycle tracks the process state and the ordered list of shutdown steps.
+type Lifecycle struct {
+	state      atomic.Int32
+	logger     *slog.Logger
+	drainDelay time.Duration
+
+	mu    sync.Mutex
+	steps []step
+}
+
+type step struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// New returns a Lifecycle whose Shutdown waits drainDelay after switching
+// to Draining, so load balancers see /readyz fail and stop sending
+// requests before the listener closes.
+func New(logger *slog.Logger, drainDelay time.Duration) *Lifecycle {
+	return &Lifecycle{logger: logger, drainDelay: drainDelay}
+}
+
+func (l *Lifecycle) State() State {
+	return State(l.state.Load())
+}
+
+func (l *Lifecycle) SetState(s State) {
+	l.state.Store(int32(s))
+	l.logger.Info("Lifecycle state changed", "state", s.String())
+}
+
+// OnShutdown registers a step that runs during Shutdown. Steps run in the
+// order they were registered, so register the HTTP server first and the
+// connections it depends on after it.
+func (l *Lifecycle) OnShutdown(name string, fn func(ctx context.Context) error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.steps = append(l.steps, step{name: name, fn: fn})
+}
+
+// Shutdown switches the state to Draining, waits out the drain delay and
+// runs every registered step. A failing step does not stop the remaining
+// ones; all errors are joined.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+	l.SetState(Draining)
+
+	if l.drainDelay > 0 {
+		l.logger.Info("Waiting before shutdown", "drain_delay", l.drainDelay.String())
+		select {
+		case <-ctx.Done():
+		case <-time.After(l.drainDelay):
+		}
+	}
+
+	l.mu.Lock()
+	steps := l.steps
+	l.mu.Unlock()
+
+	var errs []error
+	for _, s := range steps {
+		start := time.Now()
+		if err := s.fn(ctx); err != nil {
+			l.logger.Error("Shutdown step failed", "step", s.name, "error", err.Error())
+			errs = append(errs, fmt.Errorf("%s: %w", s.name, err))
+			continue
+		}
+		l.logger.Info("Shutdown step completed", "step", s.name, "duration", time.Since(start).String())
+	}
+
+	return errors.Join(errs...)
+}
+
+// WaitFor calls probe until it succeeds or ctx is done, doubling the delay
+// between attempts up to maxBackoff. Each attempt gets its own timeout.
+func WaitFor(ctx context.Context, logger *slog.Logger, name string, probe func(ctx context.Context) error) error {
+	const (
+		initialBackoff = 500 * time.Millisecond
+		maxBackoff     = 10 * time.Second
+		probeTimeout   = 5 * time.Second
+	)
+
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := probe(probeCtx)
+		cancel()
+		if err == nil {
+			logger.Info("Dependency ready", "dependency", name, "attempts", attempt)
+			return nil
+		}
+
+		logger.Warn("Dependency not ready", "dependency", name, "attempt", attempt, "retry_in", backoff.String(), "error", err.Error())
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s not ready: %w", name, errors.Join(ctx.Err(), err))
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestShutdown(t *testing.T) {
+	stepErr := errors.New("flush failed")
+
+	tests := []struct {
+		name    string
+		failing string
+		ran     []string
+		err     error
+	}{
+		{"every step runs in order", "", []string{"http", "kafka", "redis"}, nil},
+		{"a failing step does not stop the rest", "kafka", []string{"http", "kafka", "redis"}, stepErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(slog.New(slog.NewTextHandler(io.Discard, nil)), 0)
+			l.SetState(Ready)
+
+			var ran []string
+			for _, name := range []string{"http", "kafka", "redis"} {
+				l.OnShutdown(name, func(ctx context.Context) error {
+					// Readiness must already fail while steps run.
+					if l.State() != Draining {
+						t.Errorf("%s ran in state %s", name, l.State())
+					}
+					ran = append(ran, name)
+					if name == tt.failing {
+						return stepErr
+					}
+					return nil
+				})
+			}
+
+			err := l.Shutdown(context.Background())
+			if !errors.Is(err, tt.err) || (tt.err == nil) != (err == nil) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !slices.Equal(ran, tt.ran) {
+				t.Fatalf("ran = %v, want %v", ran, tt.ran)
+			}
+		})
+	}
+}
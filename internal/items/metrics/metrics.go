@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "gateway"
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route template, method and status.",
+	}, []string{"route", "method", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route template, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	grpcCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_calls_total",
+		Help:      "Upstream gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	grpcDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_client_call_duration_seconds",
+		Help:      "Upstream gRPC call latency by method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	kafkaPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_publish_total",
+		Help:      "Kafka publish attempts by topic and result.",
+	}, []string{"topic", "result"})
+
+	kafkaDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_outbox_dropped_total",
+		Help:      "Messages dropped from the Kafka outbox without being delivered, by topic.",
+	}, []string{"topic"})
+
+	kafkaDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "kafka_publish_duration_seconds",
+		Help:      "Kafka publish latency by topic.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"topic"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_cache_lookups_total",
+		Help:      "Redis cache lookups by cache and result (hit, miss or error).",
+	}, []string{"cache", "result"})
+
+	authzDecisions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "casbin_decisions_total",
+		Help:      "Casbin authorization decisions by role and decision.",
+	}, []string{"role", "decision"})
+)
+
+// Handler serves the Prometheus exposition format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
+
+// GinMiddleware records request counts and latency. Requests that match no
+// route are grouped under "unmatched" to keep the label set bounded.
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+
+		httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		httpDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// UnaryClientInterceptor records every upstream gRPC call.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		code := status.Code(err).String()
+
+		grpcCalls.WithLabelValues(method, code).Inc()
+		grpcDuration.WithLabelValues(method, code).Observe(time.Since(start).Seconds())
+		return err
+	}
+}
+
+func KafkaPublish(topic string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	kafkaPublished.WithLabelValues(topic, result).Inc()
+	kafkaDuration.WithLabelValues(topic).Observe(duration.Seconds())
+}
+
+// KafkaDropped counts a message of topic that will never be delivered.
+func KafkaDropped(topic string) {
+	kafkaDropped.WithLabelValues(topic).Inc()
+}
+
+func CacheHit(cache string) {
+	cacheLookups.WithLabelValues(cache, "hit").Inc()
+}
+
+func CacheMiss(cache string) {
+	cacheLookups.WithLabelValues(cache, "miss").Inc()
+}
+
+func CacheError(cache string) {
+	cacheLookups.WithLabelValues(cache, "error").Inc()
+}
+
+func AuthzDecision(role string, allowed bool) {
+	if role == "" {
+		role = "anonymous"
+	}
+	decision := "deny"
+	if allowed {
+		decision = "allow"
+	}
+	authzDecisions.WithLabelValues(role, decision).Inc()
+}
@@ -0,0 +1,130 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGinMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(GinMiddleware())
+	router.GET("/user/account/get/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/user/account/create", func(c *gin.Context) { c.Status(http.StatusBadRequest) })
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		labels []string // route, method, status
+	}{
+		{"route template, not the path", http.MethodGet, "/user/account/get/42", []string{"/user/account/get/:id", "GET", "200"}},
+		{"error status", http.MethodPost, "/user/account/create", []string{"/user/account/create", "POST", "400"}},
+		{"unmatched path", http.MethodGet, "/no/such/route/123", []string{"unmatched", "GET", "404"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := httpRequests.WithLabelValues(tt.labels...)
+			before := testutil.ToFloat64(counter)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Fatalf("%v counted %v times, want 1", tt.labels, got)
+			}
+		})
+	}
+}
+
+func TestUnaryClientInterceptor(t *testing.T) {
+	interceptor := UnaryClientInterceptor()
+
+	tests := []struct {
+		name string
+		err  error
+		code string
+	}{
+		{"success", nil, "OK"},
+		{"status error", status.Error(codes.NotFound, "no such user"), "NotFound"},
+		{"plain error", errors.New("boom"), "Unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := "/auth.AuthService/" + strings.ReplaceAll(tt.name, " ", "_")
+			invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				return tt.err
+			}
+
+			counter := grpcCalls.WithLabelValues(method, tt.code)
+			before := testutil.ToFloat64(counter)
+
+			err := interceptor(context.Background(), method, nil, nil, nil, invoker)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want the upstream error %v", err, tt.err)
+			}
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Fatalf("calls with code %s counted %v times, want 1", tt.code, got)
+			}
+		})
+	}
+}
+
+func TestCounters(t *testing.T) {
+	tests := []struct {
+		name   string
+		record func()
+		value  func() float64
+	}{
+		{"kafka success", func() { KafkaPublish("test_topic", time.Millisecond, nil) },
+			func() float64 { return testutil.ToFloat64(kafkaPublished.WithLabelValues("test_topic", "success")) }},
+		{"kafka failure", func() { KafkaPublish("test_topic", time.Millisecond, errors.New("down")) },
+			func() float64 { return testutil.ToFloat64(kafkaPublished.WithLabelValues("test_topic", "failure")) }},
+		{"kafka dropped", func() { KafkaDropped("test_topic") },
+			func() float64 { return testutil.ToFloat64(kafkaDropped.WithLabelValues("test_topic")) }},
+		{"cache hit", func() { CacheHit("test_cache") },
+			func() float64 { return testutil.ToFloat64(cacheLookups.WithLabelValues("test_cache", "hit")) }},
+		{"cache miss", func() { CacheMiss("test_cache") },
+			func() float64 { return testutil.ToFloat64(cacheLookups.WithLabelValues("test_cache", "miss")) }},
+		{"cache error", func() { CacheError("test_cache") },
+			func() float64 { return testutil.ToFloat64(cacheLookups.WithLabelValues("test_cache", "error")) }},
+		{"authz allow", func() { AuthzDecision("test_role", true) },
+			func() float64 { return testutil.ToFloat64(authzDecisions.WithLabelValues("test_role", "allow")) }},
+		{"authz deny without a role", func() { AuthzDecision("", false) },
+			func() float64 { return testutil.ToFloat64(authzDecisions.WithLabelValues("anonymous", "deny")) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := tt.value()
+			tt.record()
+			if got := tt.value() - before; got != 1 {
+				t.Fatalf("counter moved by %v, want 1", got)
+			}
+		})
+	}
+}
+
+func TestHandlerExposesMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	KafkaDropped("exposed_topic")
+
+	router := gin.New()
+	router.GET("/metrics", Handler())
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d", rec.Code)
+	}
+	if want := `gateway_kafka_outbox_dropped_total{topic="exposed_topic"} `; !strings.Contains(rec.Body.String(), want) {
+		t.Fatalf("metrics do not contain %q", want)
+	}
+}
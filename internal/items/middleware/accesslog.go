@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/pkg/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AccessLog writes one structured log line per request once it completes.
+// The request ID is added by the logger from the request context.
+func AccessLog(logger *slog.Logger, config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		ctx, timings := reqctx.WithTimings(c.Request.Context())
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		claims := parseClaims(c, config)
+		userId, _ := claims["user_id"].(string)
+		role, _ := claims["role"].(string)
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", c.Writer.Status()),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+			slog.String("user_id", userId),
+			slog.String("role", role),
+			slog.Any("upstream_ms", timings.Milliseconds()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case c.Writer.Status() >= 500:
+			level = slog.LevelError
+		case c.Writer.Status() >= 400:
+			level = slog.LevelWarn
+		}
+
+		logger.LogAttrs(ctx, level, "HTTP request", attrs...)
+	}
+}
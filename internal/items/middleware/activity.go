@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackActivity records when the authenticated user last made a request.
+// It is used on the admin and superadmin groups so superadmins can see
+// when each admin last acted. Failures are ignored; the timestamp is
+// informational and must not block the request.
+func TrackActivity(redis *redisservice.RedisService, config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userId := GetUser_id(c, config); userId != "" {
+			redis.TouchStaffActivity(c.Request.Context(), userId)
+		}
+		c.Next()
+	}
+}
@@ -2,6 +2,8 @@ package middleware
 
 import (
 	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/metrics"
+	"gateway-service/internal/items/rbac"
 
 	casbin "github.com/casbin/casbin/v2"
 
@@ -9,14 +11,32 @@ import (
 	"github.com/golang-jwt/jwt"
 )
 
-func AuthzMiddleware(path string, enforcer *casbin.Enforcer, config *config.Config) gin.HandlerFunc {
+// AuthzMiddleware enforces the Casbin policy for path. Users with a role
+// assigned in the policy are checked by their ID, so promotions and
+// demotions apply before their token's role claim catches up; everyone
+// else is checked by that claim. A personal access token or impersonation
+// token must in addition have a scope whose "scope:" subject allows the
+// request's full path.
+func AuthzMiddleware(path string, enforcer *casbin.SyncedEnforcer, config *config.Config) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role := getRole(c, config)
-		ok, err := enforcer.Enforce(role, path, c.Request.Method)
+		claims := parseClaims(c, config)
+		role, _ := claims["role"].(string)
+		subject := role
+		if userId, _ := claims["user_id"].(string); userId != "" {
+			if roles, err := enforcer.GetRolesForUser(userId); err == nil && len(roles) > 0 {
+				subject = userId
+			}
+		}
+
+		ok, err := enforcer.Enforce(subject, path, c.Request.Method)
+		if scopes, limited := tokenScopes(claims); ok && err == nil && limited {
+			ok, err = scopeAllows(enforcer, scopes, c.Request.URL.Path, c.Request.Method)
+		}
 		if err != nil {
 			c.AbortWithStatusJSON(500, gin.H{"error": "Authorization error"})
 			return
 		}
+		metrics.AuthzDecision(role, ok)
 		if !ok {
 			c.AbortWithStatusJSON(403, gin.H{"error": "Unauthorized"})
 			return
@@ -25,79 +45,71 @@ func AuthzMiddleware(path string, enforcer *casbin.Enforcer, config *config.Conf
 	}
 }
 
-func getRole(c *gin.Context, config *config.Config) string {
-	tokenString := c.GetHeader("Authorization")
-	if tokenString == "" {
-		return ""
-	}
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
+func scopeAllows(enforcer *casbin.SyncedEnforcer, scopes []string, path, method string) (bool, error) {
+	for _, scope := range scopes {
+		ok, err := enforcer.Enforce(rbac.ScopePrefix+scope, path, method)
+		if ok || err != nil {
+			return ok, err
 		}
-		return []byte(config.JWT.SecretKey), nil
-	})
-
-	if err != nil || !token.Valid {
-		return ""
-	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return ""
-	}
-
-	role, ok := claims["role"].(string)
-	if !ok {
-		return ""
 	}
+	return false, nil
+}
 
+func GetRole(c *gin.Context, config *config.Config) string {
+	role, _ := parseClaims(c, config)["role"].(string)
 	return role
 }
 
 func GetUser_id(c *gin.Context, config *config.Config) string {
-	tokenString := c.GetHeader("Authorization")
+	userId, _ := parseClaims(c, config)["user_id"].(string)
+	return userId
+}
+
+// parseClaims validates the Authorization header and returns its claims,
+// or nil if the token is missing or invalid. A personal access token has
+// been resolved to claims by PersonalAccessToken already.
+func parseClaims(c *gin.Context, config *config.Config) jwt.MapClaims {
+	if claims, ok := c.Get(patClaimsKey); ok {
+		return claims.(jwt.MapClaims)
+	}
+	return ParseToken(c.GetHeader("Authorization"), config)
+}
+
+// ParseToken validates tokenString and returns its claims, or nil if it is
+// empty or invalid.
+func ParseToken(tokenString string, config *config.Config) jwt.MapClaims {
 	if tokenString == "" {
-		return ""
+		return nil
 	}
 
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
+	// Try the current key first, then the previous one during rotation.
+	var token *jwt.Token
+	for _, secret := range config.JWTSecrets() {
+		parsed, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, jwt.NewValidationError("unexpected signing method", jwt.ValidationErrorSignatureInvalid)
+			}
+			return []byte(secret), nil
+		})
+		if err == nil && parsed.Valid {
+			token = parsed
+			break
 		}
-		return []byte(config.JWT.SecretKey), nil
-	})
-
-	if err != nil || !token.Valid {
-		return ""
 	}
-
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return ""
+	if token == nil {
+		return nil
 	}
 
-	user_id, ok := claims["user_id"].(string)
+	claims, ok := token.Claims.(jwt.MapClaims)
 	if !ok {
-		return ""
+		return nil
 	}
 
-	return user_id
+	return claims
 }
 
-func CORSMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		c.Writer.Header().Set("Content-Type", "application/json")
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Max-Age", "86400")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE, UPDATE")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-Max")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(200)
-		} else {
-			c.Next()
-		}
-	}
+// SignToken issues an access token with claims, signed with the current
+// key, for logins the gateway completes without the auth service.
+func SignToken(claims jwt.MapClaims, config *config.Config) (string, error) {
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.JWTSecrets()[0]))
 }
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// CORS applies an allow-list CORS policy, chosen per top-level route group,
+// that can be replaced at runtime with Update. It is installed on the
+// router rather than on each group so it also answers preflight requests,
+// which never match a registered route.
+type CORS struct {
+	policy atomic.Pointer[corsPolicy]
+}
+
+type corsPolicy struct {
+	base   gin.HandlerFunc
+	groups map[string]gin.HandlerFunc // first path segment -> handler
+}
+
+func NewCORS(cfg config.CORSConfig) *CORS {
+	c := &CORS{}
+	c.Update(cfg)
+	return c
+}
+
+func (c *CORS) Update(cfg config.CORSConfig) {
+	c.policy.Store(&corsPolicy{
+		base: newCORSHandler(cfg, config.CORSOverride{}),
+		groups: map[string]gin.HandlerFunc{
+			"auth":       newCORSHandler(cfg, cfg.Groups.Auth),
+			"admin":      newCORSHandler(cfg, cfg.Groups.Admin),
+			"superadmin": newCORSHandler(cfg, cfg.Groups.Superadmin),
+			"user":       newCORSHandler(cfg, cfg.Groups.User),
+		},
+	})
+}
+
+func (c *CORS) Middleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		policy := c.policy.Load()
+		group, _, _ := strings.Cut(strings.TrimPrefix(ctx.Request.URL.Path, "/"), "/")
+		if handler, ok := policy.groups[group]; ok {
+			handler(ctx)
+			return
+		}
+		policy.base(ctx)
+	}
+}
+
+func newCORSHandler(cfg config.CORSConfig, override config.CORSOverride) gin.HandlerFunc {
+	origins := cfg.AllowOrigins
+	if len(override.AllowOrigins) > 0 {
+		origins = override.AllowOrigins
+	}
+	methods := cfg.AllowMethods
+	if len(override.AllowMethods) > 0 {
+		methods = override.AllowMethods
+	}
+	headers := cfg.AllowHeaders
+	if len(override.AllowHeaders) > 0 {
+		headers = override.AllowHeaders
+	}
+
+	corsConfig := cors.Config{
+		AllowMethods:              methods,
+		AllowHeaders:              headers,
+		ExposeHeaders:             cfg.ExposeHeaders,
+		AllowCredentials:          cfg.AllowCredentials,
+		MaxAge:                    cfg.MaxAge,
+		OptionsResponseStatusCode: http.StatusNoContent,
+	}
+	for _, origin := range origins {
+		if origin == "*" {
+			corsConfig.AllowAllOrigins = true
+		}
+	}
+	if !corsConfig.AllowAllOrigins {
+		// Always matched by function so an empty list rejects every
+		// cross-origin request instead of failing cors.New.
+		corsConfig.AllowOriginFunc = originMatcher(origins)
+	}
+	return cors.New(corsConfig)
+}
+
+// originMatcher matches origins exactly, or by wildcard subdomain for
+// patterns such as https://*.example.com. The wildcard needs at least one
+// label, so https://example.com itself is not matched by it.
+func originMatcher(patterns []string) func(string) bool {
+	exact := make(map[string]bool)
+	var wildcards []*url.URL
+	for _, p := range patterns {
+		if strings.Contains(p, "://*.") {
+			if u, err := url.Parse(p); err == nil {
+				wildcards = append(wildcards, u)
+			}
+			continue
+		}
+		exact[strings.ToLower(p)] = true
+	}
+
+	return func(origin string) bool {
+		origin = strings.ToLower(origin)
+		if exact[origin] {
+			return true
+		}
+		u, err := url.Parse(origin)
+		if err != nil {
+			return false
+		}
+		for _, w := range wildcards {
+			suffix := strings.ToLower(strings.TrimPrefix(w.Hostname(), "*"))
+			if u.Scheme == w.Scheme && u.Port() == w.Port() &&
+				strings.HasSuffix(u.Hostname(), suffix) && len(u.Hostname()) > len(suffix) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOriginMatcher(t *testing.T) {
+	match := originMatcher([]string{"https://app.example.com", "https://*.example.org", "http://localhost:3000"})
+
+	tests := []struct {
+		origin string
+		want   bool
+	}{
+		{"https://app.example.com", true},
+		{"https://APP.example.com", true},
+		{"http://app.example.com", false},
+		{"https://evil.example.com", false},
+		{"https://eu.example.org", true},
+		{"https://a.b.example.org", true},
+		{"https://example.org", false},
+		{"https://evilexample.org", false},
+		{"https://eu.example.org:8443", false},
+		{"http://localhost:3000", true},
+		{"http://localhost:3001", false},
+		{"null", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			if got := match(tt.origin); got != tt.want {
+				t.Fatalf("match(%q) = %v, want %v", tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default().CORS
+	cfg.AllowOrigins = []string{"https://app.example.com"}
+	cfg.AllowCredentials = true
+	cfg.MaxAge = 5 * time.Minute
+	cfg.Groups.Admin = config.CORSOverride{
+		AllowOrigins: []string{"https://admin.example.com"},
+		AllowMethods: []string{"GET"},
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		origin  string
+		status  int
+		allowed string // expected Access-Control-Allow-Origin
+		methods string // expected Access-Control-Allow-Methods on preflight
+	}{
+		{"allowed origin", http.MethodGet, "/user/profile", "https://app.example.com", http.StatusOK, "https://app.example.com", ""},
+		{"unlisted origin", http.MethodGet, "/user/profile", "https://evil.example.com", http.StatusForbidden, "", ""},
+		{"same-origin request", http.MethodGet, "/user/profile", "", http.StatusOK, "", ""},
+		{"preflight", http.MethodOptions, "/user/profile", "https://app.example.com", http.StatusNoContent, "https://app.example.com", "GET,POST,PUT,DELETE,OPTIONS"},
+		{"group override origin", http.MethodGet, "/admin/users", "https://admin.example.com", http.StatusOK, "https://admin.example.com", ""},
+		{"group override rejects the base origin", http.MethodGet, "/admin/users", "https://app.example.com", http.StatusForbidden, "", ""},
+		{"group override methods", http.MethodOptions, "/admin/users", "https://admin.example.com", http.StatusNoContent, "https://admin.example.com", "GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(NewCORS(cfg).Middleware())
+			ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+			router.GET("/user/profile", ok)
+			router.GET("/admin/users", ok)
+
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			if tt.method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tt.allowed {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.allowed)
+			}
+			if tt.allowed != "" && rec.Header().Get("Access-Control-Allow-Credentials") != "true" {
+				t.Fatal("credentials are not allowed")
+			}
+			if tt.method == http.MethodOptions {
+				if got := rec.Header().Get("Access-Control-Allow-Methods"); got != tt.methods {
+					t.Fatalf("Access-Control-Allow-Methods = %q, want %q", got, tt.methods)
+				}
+				if got := rec.Header().Get("Access-Control-Max-Age"); got != "300" {
+					t.Fatalf("Access-Control-Max-Age = %q, want 300", got)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSUpdate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default().CORS
+	cfg.AllowOrigins = []string{"https://old.example.com"}
+	c := NewCORS(cfg)
+
+	router := gin.New()
+	router.Use(c.Middleware())
+	router.GET("/user/profile", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(origin string) int {
+		req := httptest.NewRequest(http.MethodGet, "/user/profile", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	cfg.AllowOrigins = []string{"https://new.example.com"}
+	c.Update(cfg)
+	if code := request("https://old.example.com"); code != http.StatusForbidden {
+		t.Fatalf("old origin status = %d, want %d", code, http.StatusForbidden)
+	}
+	if code := request("https://new.example.com"); code != http.StatusOK {
+		t.Fatalf("new origin status = %d, want %d", code, http.StatusOK)
+	}
+}
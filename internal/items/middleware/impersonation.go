@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	HeaderImpersonatedUser    = "X-Impersonated-User"
+	HeaderImpersonator        = "X-Impersonator"
+	HeaderImpersonationExpiry = "X-Impersonation-Expires"
+)
+
+// Impersonation handles tokens issued by POST /admin/users/:id/impersonate,
+// which carry the admin in an act claim (RFC 8693). It rejects them once
+// the impersonation has been ended and marks every response so clients
+// can show that someone is acting as the user. What the token may reach is
+// limited by its scopes in AuthzMiddleware.
+func Impersonation(redis *redisservice.RedisService, config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := parseClaims(c, config)
+		admin := impersonator(claims)
+		if admin == "" {
+			c.Next()
+			return
+		}
+
+		impId, _ := claims["imp_id"].(string)
+		active, err := redis.ImpersonationActive(c.Request.Context(), impId)
+		if err != nil {
+			c.AbortWithStatusJSON(503, gin.H{"error": "Unable to verify impersonation"})
+			return
+		}
+		if !active {
+			c.AbortWithStatusJSON(401, gin.H{"error": "Impersonation has ended"})
+			return
+		}
+
+		userId, _ := claims["user_id"].(string)
+		c.Header(HeaderImpersonatedUser, userId)
+		c.Header(HeaderImpersonator, admin)
+		if exp, ok := claims["exp"].(float64); ok {
+			c.Header(HeaderImpersonationExpiry, time.Unix(int64(exp), 0).UTC().Format(time.RFC3339))
+		}
+		c.Next()
+	}
+}
+
+// GetImpersonator returns the admin acting through the request's token,
+// or "" if the user is acting themselves.
+func GetImpersonator(c *gin.Context, config *config.Config) string {
+	return impersonator(parseClaims(c, config))
+}
+
+func impersonator(claims jwt.MapClaims) string {
+	act, _ := claims["act"].(map[string]interface{})
+	sub, _ := act["sub"].(string)
+	return sub
+}
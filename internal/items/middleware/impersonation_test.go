@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	casbin "github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+// impersonationToken returns a token for admin-1 acting as user-1 through
+// impersonation imp-1 with scope.
+func impersonationToken(t *testing.T, cfg *config.Config, scope string, exp time.Time) string {
+	t.Helper()
+	token, err := SignToken(jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "user",
+		"act":     map[string]interface{}{"sub": "admin-1", "role": "admin"},
+		"imp_id":  "imp-1",
+		"scope":   scope,
+		"iat":     time.Now().Unix(),
+		"exp":     exp.Unix(),
+	}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestImpersonation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "test-secret"
+	exp := time.Now().Add(10 * time.Minute)
+
+	own, err := SignToken(jwt.MapClaims{"user_id": "user-1", "role": "user", "iat": time.Now().Unix()}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		active  bool
+		status  int
+		headers bool
+	}{
+		{"user's own token", own, false, http.StatusOK, false},
+		{"active impersonation", impersonationToken(t, cfg, "accounts:read", exp), true, http.StatusOK, true},
+		{"ended impersonation", impersonationToken(t, cfg, "accounts:read", exp), false, http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRedis(t)
+			if tt.active {
+				if err := store.StartImpersonation(context.Background(), redisservice.Impersonation{
+					ID: "imp-1", AdminID: "admin-1", UserID: "user-1", ExpiresAt: exp,
+				}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			router := gin.New()
+			router.GET("/user/account/list", Impersonation(store, cfg), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/user/account/list", nil)
+			req.Header.Set("Authorization", tt.token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+			want := map[string]string{
+				HeaderImpersonatedUser:    "user-1",
+				HeaderImpersonator:        "admin-1",
+				HeaderImpersonationExpiry: exp.UTC().Format(time.RFC3339),
+			}
+			for key, value := range want {
+				if !tt.headers {
+					value = ""
+				}
+				if got := rec.Header().Get(key); got != value {
+					t.Errorf("%s = %q, want %q", key, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestAuthzImpersonationScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "test-secret"
+	enforcer, err := casbin.NewSyncedEnforcer(
+		filepath.Join("..", "casbin", "model.conf"),
+		filepath.Join("..", "casbin", "policy.csv"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readOnly := "accounts:read transactions:read profile:read"
+	tests := []struct {
+		name   string
+		scope  string
+		method string
+		path   string
+		status int
+	}{
+		{"read in scope", readOnly, http.MethodGet, "/user/account/list", http.StatusOK},
+		{"write with read-only scopes", readOnly, http.MethodPost, "/user/account/create", http.StatusForbidden},
+		{"delete with read-only scopes", readOnly, http.MethodDelete, "/user/transaction/delete/1", http.StatusForbidden},
+		{"read outside the scopes", readOnly, http.MethodGet, "/user/goal/list", http.StatusForbidden},
+		{"route with no scope", readOnly, http.MethodGet, "/user/sessions", http.StatusForbidden},
+		{"write when allowed", readOnly + " accounts:write", http.MethodPost, "/user/account/create", http.StatusOK},
+		{"no scopes", "", http.MethodGet, "/user/account/list", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Any("/user/*path", AuthzMiddleware("/user", enforcer, cfg), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("Authorization", impersonationToken(t, cfg, tt.scope, time.Now().Add(time.Minute)))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireMFA rejects users who have not enabled two-factor authentication.
+// It guards the superadmin routes; superadmins can still log in with a
+// password alone to enroll under /auth/mfa.
+func RequireMFA(redis *redisservice.RedisService, config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sealed, err := redis.GetMFASecret(c.Request.Context(), GetUser_id(c, config))
+		if err != nil {
+			c.AbortWithStatusJSON(503, gin.H{"error": "Unable to verify session"})
+			return
+		}
+		if sealed == "" {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Two-factor authentication must be enabled; enroll at /auth/mfa/enroll"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+const rateLimitIdleTTL = 10 * time.Minute
+
+// RateLimiter is a per-client-IP token bucket whose limits can be changed
+// at runtime with Update.
+type RateLimiter struct {
+	mu        sync.Mutex
+	cfg       config.RateLimitConfig
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens   float64
+	lastSeen time.Time
+}
+
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		cfg:       cfg,
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *RateLimiter) Update(cfg config.RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cfg = cfg
+}
+
+// Allow takes a token for key and, if none is left, reports how long until
+// the next one is available.
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.cfg.Enabled {
+		return true, 0
+	}
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) > rateLimitIdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > rateLimitIdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	perSecond := float64(l.cfg.RequestsPerMinute) / 60
+	burst := float64(l.cfg.Burst)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.lastSeen).Seconds()*perSecond)
+	b.lastSeen = now
+
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *RateLimiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ok, wait := l.Allow(c.ClientIP())
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(429, gin.H{"error": "Too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
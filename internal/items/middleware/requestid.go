@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"gateway-service/internal/pkg/reqctx"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID accepts a well-formed X-Request-ID from the client or generates
+// one, stores it in the gin and request contexts and echoes it back.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(reqctx.HeaderRequestID)
+		if !reqctx.ValidRequestID(id) {
+			id = reqctx.NewRequestID()
+		}
+
+		c.Set("request_id", id)
+		c.Request = c.Request.WithContext(reqctx.WithRequestID(c.Request.Context(), id))
+		c.Header(reqctx.HeaderRequestID, id)
+
+		c.Next()
+	}
+}
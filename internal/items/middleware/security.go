@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SecurityHeaders sets the configured security headers on every response.
+func SecurityHeaders(cfg config.SecurityConfig) gin.HandlerFunc {
+	hsts := ""
+	if cfg.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(cfg.HSTSMaxAge.Seconds()))
+		if cfg.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		setHeader(header, "Strict-Transport-Security", hsts)
+		setHeader(header, "X-Content-Type-Options", cfg.ContentTypeOptions)
+		setHeader(header, "X-Frame-Options", cfg.FrameOptions)
+		setHeader(header, "Referrer-Policy", cfg.ReferrerPolicy)
+		if strings.HasPrefix(c.Request.URL.Path, "/swagger/") {
+			setHeader(header, "Content-Security-Policy", cfg.SwaggerContentSecurityPolicy)
+		} else {
+			setHeader(header, "Content-Security-Policy", cfg.ContentSecurityPolicy)
+		}
+		c.Next()
+	}
+}
+
+func setHeader(header http.Header, key, value string) {
+	if value != "" {
+		header.Set(key, value)
+	}
+}
+
+// RequestLimits rejects request bodies that are too large (413), have an
+// unexpected Content-Type (415) or nest JSON deeper than allowed (400).
+// Requests without a body pass through.
+func RequestLimits(cfg config.SecurityConfig) gin.HandlerFunc {
+	maxBytes := int64(cfg.MaxBodyBytes)
+
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.ContentLength == 0 {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+			return
+		}
+
+		mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+		if err != nil || !slices.Contains(cfg.AllowedContentTypes, mediaType) {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported content type"})
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body too large"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+			return
+		}
+
+		if mediaType == "application/json" {
+			if err := checkJSONDepth(body, cfg.MaxJSONDepth); err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// checkJSONDepth fails if body nests objects or arrays deeper than max.
+// Malformed JSON is left for the handler to report.
+func checkJSONDepth(body []byte, max int) error {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			if depth > max {
+				return fmt.Errorf("JSON nested deeper than %d levels", max)
+			}
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default().Security
+
+	tests := []struct {
+		name   string
+		modify func(cfg *config.SecurityConfig)
+		path   string
+		want   map[string]string
+	}{
+		{"defaults", func(*config.SecurityConfig) {}, "/user/profile", map[string]string{
+			"Strict-Transport-Security": "max-age=15552000; includeSubDomains",
+			"X-Content-Type-Options":    "nosniff",
+			"X-Frame-Options":           "DENY",
+			"Referrer-Policy":           "no-referrer",
+			"Content-Security-Policy":   cfg.ContentSecurityPolicy,
+		}},
+		{"swagger policy", func(*config.SecurityConfig) {}, "/swagger/index.html", map[string]string{
+			"Content-Security-Policy": cfg.SwaggerContentSecurityPolicy,
+		}},
+		{"hsts without subdomains", func(cfg *config.SecurityConfig) {
+			cfg.HSTSMaxAge = time.Hour
+			cfg.HSTSIncludeSubdomains = false
+		}, "/user/profile", map[string]string{"Strict-Transport-Security": "max-age=3600"}},
+		{"disabled headers are omitted", func(cfg *config.SecurityConfig) {
+			cfg.HSTSMaxAge = 0
+			cfg.FrameOptions = ""
+		}, "/user/profile", map[string]string{"Strict-Transport-Security": "", "X-Frame-Options": ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default().Security
+			tt.modify(&cfg)
+			router := gin.New()
+			router.Use(SecurityHeaders(cfg))
+			router.GET("/*path", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			for key, want := range tt.want {
+				if got := rec.Header().Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRequestLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default().Security
+	cfg.MaxBodyBytes = 64
+	cfg.MaxJSONDepth = 3
+
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		// chunked hides the length so the limit is hit while reading.
+		chunked bool
+		status  int
+	}{
+		{"no body", "", "", false, http.StatusOK},
+		{"json", `{"a":{"b":[1]}}`, "application/json", false, http.StatusOK},
+		{"json with charset", `{"a":1}`, "application/json; charset=utf-8", false, http.StatusOK},
+		{"declared too large", strings.Repeat("a", 65), "application/json", false, http.StatusRequestEntityTooLarge},
+		{"read too large", `"` + strings.Repeat("a", 70) + `"`, "application/json", true, http.StatusRequestEntityTooLarge},
+		{"form body", "a=1", "application/x-www-form-urlencoded", false, http.StatusUnsupportedMediaType},
+		{"missing content type", `{"a":1}`, "", false, http.StatusUnsupportedMediaType},
+		{"nested too deep", `{"a":{"b":{"c":[1]}}}`, "application/json", false, http.StatusBadRequest},
+		{"malformed json is left to the handler", `{"a":`, "application/json", false, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(RequestLimits(cfg))
+			router.POST("/user/transactions", func(c *gin.Context) {
+				body, err := io.ReadAll(c.Request.Body)
+				if err != nil || string(body) != tt.body {
+					t.Errorf("handler body = %q, %v, want %q", body, err, tt.body)
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/user/transactions", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
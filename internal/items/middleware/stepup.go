@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"fmt"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+	"gateway-service/internal/pkg/signedtoken"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// HeaderElevationToken carries the token from POST /auth/elevate.
+	HeaderElevationToken = "X-Elevation-Token"
+	// ElevationPurpose scopes elevation tokens so no other kind of token
+	// is accepted in their place.
+	ElevationPurpose = "elevate"
+)
+
+// StepUp marks a route as sensitive. The request passes if the token's
+// login is newer than step_up.max_auth_age, judged by its auth_time claim
+// or else iat, or if it carries an elevation token issued to the same
+// user. Otherwise it gets 401 with a challenge describing how to elevate.
+// Personal access and impersonation tokens are refused with 403: their iat
+// is not a login, and they cannot be elevated.
+func StepUp(redis *redisservice.RedisService, config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := parseClaims(c, config)
+		userId, _ := claims["user_id"].(string)
+
+		if _, limited := tokenScopes(claims); limited {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Personal access and impersonation tokens cannot perform this action"})
+			return
+		}
+
+		authTime, ok := claims["auth_time"].(float64)
+		if !ok {
+			authTime, _ = claims["iat"].(float64)
+		}
+		if time.Since(time.Unix(int64(authTime), 0)) <= config.StepUp.MaxAuthAge {
+			c.Next()
+			return
+		}
+
+		if token := c.GetHeader(HeaderElevationToken); token != "" && userId != "" {
+			id, err := signedtoken.Parse(token, ElevationPurpose, config.JWTSecrets(), time.Now())
+			if err == nil {
+				elevated, err := redis.LookupToken(c.Request.Context(), ElevationPurpose, id)
+				if err == nil && elevated == userId {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		methods := []string{"password"}
+		if sealed, err := redis.GetMFASecret(c.Request.Context(), userId); err == nil && sealed != "" {
+			methods = append(methods, "totp")
+		}
+		maxAge := int(config.StepUp.MaxAuthAge.Seconds())
+		c.Header("WWW-Authenticate", fmt.Sprintf(`Elevation endpoint="/auth/elevate", header="%s", max_age=%d`, HeaderElevationToken, maxAge))
+		c.AbortWithStatusJSON(401, gin.H{
+			"error": "Recent authentication required",
+			"step_up": gin.H{
+				"endpoint":        "/auth/elevate",
+				"header":          HeaderElevationToken,
+				"methods":         methods,
+				"max_age_seconds": maxAge,
+			},
+		})
+	}
+}
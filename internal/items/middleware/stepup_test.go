@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestStepUpRejectsDelegatedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "test-secret"
+	now := float64(time.Now().Unix())
+
+	impersonation, err := SignToken(jwt.MapClaims{
+		"user_id": "user-1",
+		"role":    "user",
+		"act":     map[string]interface{}{"sub": "admin-1", "role": "admin"},
+		"scope":   "accounts:read",
+		"iat":     now,
+		"exp":     now + 600,
+	}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		setup func(c *gin.Context)
+	}{
+		{"personal access token", func(c *gin.Context) {
+			c.Set(patClaimsKey, jwt.MapClaims{"user_id": "user-1", "role": "user", "iat": now, "pat_id": "pat-1", "scope": "accounts:write"})
+		}},
+		{"impersonation token", func(c *gin.Context) {
+			c.Request.Header.Set("Authorization", impersonation)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.DELETE("/user/me", tt.setup, StepUp(nil, cfg), func(c *gin.Context) {
+				c.Status(http.StatusNoContent)
+			})
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/user/me", nil))
+			if rec.Code != http.StatusForbidden {
+				t.Fatalf("status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+)
+
+// newTestRedis returns a RedisService backed by an in-memory Redis.
+func newTestRedis(t *testing.T) *redisservice.RedisService {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { client.Close() })
+	return redisservice.New(client, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Minute)
+}
+
+func TestTokenGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "test-secret"
+
+	tests := []struct {
+		name string
+		// issued is the token's iat relative to the revocation, in seconds.
+		issued     int64
+		revoke     bool
+		session    bool
+		deactivate bool
+		status     int
+	}{
+		{"no revocation", 0, false, false, false, http.StatusOK},
+		{"issued before the revocation", -1, true, true, false, http.StatusUnauthorized},
+		{"issued in its second without a session", 0, true, false, false, http.StatusUnauthorized},
+		{"issued in its second with a session", 0, true, true, false, http.StatusOK},
+		{"issued after the revocation", 1, true, false, false, http.StatusOK},
+		{"deactivated user", 0, false, false, true, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRedis(t)
+			ctx := context.Background()
+
+			revokedAt := time.Now().Unix()
+			if tt.revoke {
+				if err := store.RevokeTokens(ctx, "user-1", time.Hour); err != nil {
+					t.Fatal(err)
+				}
+				status, err := store.GetUserStatus(ctx, "user-1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				revokedAt = status.RevokedBefore.Unix()
+			}
+			// A token cannot be issued in the future.
+			for time.Now().Unix() < revokedAt+tt.issued {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if tt.deactivate {
+				if err := store.SetUserActive(ctx, "user-1", false); err != nil {
+					t.Fatal(err)
+				}
+			}
+			token, err := SignToken(jwt.MapClaims{
+				"jti":     "token-1",
+				"user_id": "user-1",
+				"role":    "user",
+				"iat":     revokedAt + tt.issued,
+				"exp":     revokedAt + 3600,
+			}, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.session {
+				session := redisservice.Session{ID: "session-1", UserID: "user-1", TokenID: "token-1", CreatedAt: time.Now()}
+				if err := store.StartSession(ctx, session, time.Hour); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			router := gin.New()
+			router.GET("/user/profile", TokenGuard(store, cfg), func(c *gin.Context) { c.Status(http.StatusOK) })
+			req := httptest.NewRequest(http.MethodGet, "/user/profile", nil)
+			req.Header.Set("Authorization", token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireVerified rejects users who have not confirmed their email yet.
+func RequireVerified(redis *redisservice.RedisService, config *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userId := GetUser_id(c, config)
+		if userId == "" {
+			c.Next()
+			return
+		}
+
+		status, err := redis.GetUserStatus(c.Request.Context(), userId)
+		if err != nil {
+			c.AbortWithStatusJSON(503, gin.H{"error": "Unable to verify session"})
+			return
+		}
+		if status.Unverified {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Email address is not verified"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/redisservice"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/golang-jwt/jwt"
+)
+
+func TestRequireVerified(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := config.Default()
+	cfg.JWT.SecretKey = "test-secret"
+
+	// unreachable stands in for Redis being down.
+	unreachable := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	t.Cleanup(func() { unreachable.Close() })
+
+	tests := []struct {
+		name       string
+		token      bool
+		unverified bool
+		redisDown  bool
+		status     int
+	}{
+		{"anonymous request", false, false, false, http.StatusOK},
+		{"user without a marker", true, false, false, http.StatusOK},
+		{"unverified user", true, true, false, http.StatusForbidden},
+		{"status lookup fails", true, false, true, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newTestRedis(t)
+			if tt.unverified {
+				if err := store.SetUserVerified(context.Background(), "user-1", false); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.redisDown {
+				store = redisservice.New(unreachable, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Minute)
+			}
+
+			router := gin.New()
+			router.GET("/user/accounts", RequireVerified(store, cfg), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/user/accounts", nil)
+			if tt.token {
+				token, err := SignToken(jwt.MapClaims{
+					"user_id": "user-1",
+					"role":    "user",
+					"iat":     time.Now().Unix(),
+					"exp":     time.Now().Add(time.Hour).Unix(),
+				}, cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req.Header.Set("Authorization", token)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tt.status {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package msgbroker
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gateway-service/internal/pkg/reqctx"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestHeaderCarrier(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []kafka.Header
+		set     [2]string
+		keys    []string
+	}{
+		{"adds a header", nil, [2]string{"traceparent", "v1"}, []string{"traceparent"}},
+		{"keeps other headers", []kafka.Header{{Key: "X-Request-ID", Value: []byte("req-1")}}, [2]string{"traceparent", "v1"}, []string{"X-Request-ID", "traceparent"}},
+		{"replaces a header", []kafka.Header{{Key: "traceparent", Value: []byte("old")}}, [2]string{"traceparent", "v1"}, []string{"traceparent"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := kafka.Message{Headers: tt.headers}
+			carrier := headerCarrier{msg: &msg}
+			carrier.Set(tt.set[0], tt.set[1])
+
+			if got := carrier.Get(tt.set[0]); got != tt.set[1] {
+				t.Fatalf("Get(%q) = %q, want %q", tt.set[0], got, tt.set[1])
+			}
+			if got := carrier.Get("missing"); got != "" {
+				t.Fatalf("Get(missing) = %q", got)
+			}
+			if got := carrier.Keys(); !slices.Equal(got, tt.keys) {
+				t.Fatalf("Keys = %v, want %v", got, tt.keys)
+			}
+		})
+	}
+}
+
+// useTestTracer installs a recording tracer provider and the trace-context
+// propagator until the test ends.
+func useTestTracer(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func TestPublishCarriesTraceContext(t *testing.T) {
+	recorder := useTestTracer(t)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	// Publishing fails, so the message, headers included, stays in the outbox.
+	b := NewMsgBroker(&kafka.Writer{Addr: kafka.TCP("127.0.0.1:1"), MaxAttempts: 1}, logger)
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		b.Close(ctx)
+	})
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "POST /user/transaction/create")
+	ctx = reqctx.WithRequestID(ctx, "req-1")
+	b.TransactionCreated(ctx, []byte(`{}`))
+	parent.End()
+
+	b.mu.Lock()
+	msg := b.outbox[0]
+	b.mu.Unlock()
+	carrier := headerCarrier{msg: &msg}
+	traceID := parent.SpanContext().TraceID().String()
+	if got := carrier.Get("traceparent"); !strings.Contains(got, traceID) {
+		t.Fatalf("traceparent = %q, want trace %s", got, traceID)
+	}
+	if got := carrier.Get(reqctx.HeaderRequestID); got != "req-1" {
+		t.Fatalf("%s = %q, want req-1", reqctx.HeaderRequestID, got)
+	}
+
+	var publish sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "transaction_created publish" {
+			publish = span
+		}
+	}
+	if publish == nil {
+		t.Fatal("no publish span recorded")
+	}
+	if publish.SpanKind() != trace.SpanKindProducer || publish.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("publish span kind %s, parent %s, want a producer child of the request span", publish.SpanKind(), publish.Parent().SpanID())
+	}
+	if publish.Status().Code != codes.Error {
+		t.Fatalf("publish span status = %v, want an error", publish.Status())
+	}
+}
@@ -2,22 +2,80 @@ package msgbroker
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"gateway-service/internal/items/config"
+	"gateway-service/internal/items/metrics"
+	"gateway-service/internal/pkg/reqctx"
+	"gateway-service/internal/pkg/tracing"
 	"log/slog"
+	"sync"
+	"time"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	outboxSize          = 1000
+	outboxRetryInterval = 5 * time.Second
+)
+
+var (
+	// ErrQueued is returned for a message that failed to publish and was
+	// queued in the outbox. It may still be delivered, or never be.
+	ErrQueued = errors.New("kafka publish failed; message queued for retry")
+	// ErrOutboxFull is returned for a message that failed to publish and
+	// was dropped.
+	ErrOutboxFull = errors.New("kafka outbox is full")
+)
+
+// Topics lists every topic the gateway publishes to.
+var Topics = []string{
+	"transaction_created",
+	"budget_updated",
+	"goal_progress_updated",
+	"notification_created",
+	"user_verification_requested",
+	"password_reset_requested",
+	"user_deleted",
+	AuditTopic,
+}
+
+// AuditTopic receives every audit record.
+const AuditTopic = "audit_events"
+
+// MsgBroker publishes messages to Kafka. Messages that fail to publish are
+// kept in an in-memory outbox and retried in the background until Close.
+//
+// Delivery through the outbox is at most once: it does not survive a crash
+// or restart, and messages still in it after the final flush in Close are
+// dropped. Dropped messages are counted in kafka_outbox_dropped_total, and
+// a publish that was only queued still returns an error wrapping ErrQueued
+// so callers never take it for a delivery.
 type MsgBroker struct {
 	writer *kafka.Writer
 	logger *slog.Logger
+
+	mu     sync.Mutex
+	outbox []kafka.Message
+
+	stop chan struct{}
+	done chan struct{}
 }
 
 func NewMsgBroker(writer *kafka.Writer, logger *slog.Logger) *MsgBroker {
-	return &MsgBroker{
+	b := &MsgBroker{
 		writer: writer,
 		logger: logger,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
 	}
+	go b.retryLoop()
+	return b
 }
 
 func (b *MsgBroker) TransactionCreated(ctx context.Context, body []byte) error {
@@ -36,35 +94,204 @@ func (b *MsgBroker) NotificationCreated(ctx context.Context, body []byte) error
 	return b.publishMessage(ctx, "notification_created", body)
 }
 
+func (b *MsgBroker) UserVerificationRequested(ctx context.Context, body []byte) error {
+	return b.publishMessage(ctx, "user_verification_requested", body)
+}
+
+func (b *MsgBroker) PasswordResetRequested(ctx context.Context, body []byte) error {
+	return b.publishMessage(ctx, "password_reset_requested", body)
+}
+
+func (b *MsgBroker) UserDeleted(ctx context.Context, body []byte) error {
+	return b.publishMessage(ctx, "user_deleted", body)
+}
+
+func (b *MsgBroker) AuditRecorded(ctx context.Context, body []byte) error {
+	return b.publishMessage(ctx, AuditTopic, body)
+}
+
 func (b *MsgBroker) publishMessage(ctx context.Context, topic string, body []byte) error {
-	err := b.writer.WriteMessages(ctx, kafka.Message{
+	ctx, span := tracing.Tracer().Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+		),
+	)
+	defer span.End()
+
+	msg := kafka.Message{
 		Topic: topic,
 		Value: body,
-	})
+	}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{msg: &msg})
+	if id := reqctx.RequestID(ctx); id != "" {
+		headerCarrier{msg: &msg}.Set(reqctx.HeaderRequestID, id)
+	}
+
+	start := time.Now()
+	err := b.writer.WriteMessages(ctx, msg)
+	metrics.KafkaPublish(topic, time.Since(start), err)
+	reqctx.RecordUpstream(ctx, "kafka "+topic, time.Since(start))
 	if err != nil {
-		b.logger.Error("Failed to publish message", "topic", topic, "error", err.Error())
-		return err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		b.logger.ErrorContext(ctx, "Failed to publish message", "topic", topic, "error", err.Error())
+		if queueErr := b.enqueue(msg); queueErr != nil {
+			return errors.Join(err, queueErr)
+		}
+		return fmt.Errorf("%w: %w", ErrQueued, err)
 	}
 
-	b.logger.Info("Message published", "topic", topic)
+	b.logger.InfoContext(ctx, "Message published", "topic", topic)
 	return nil
 }
 
-func CreateTopics(config *config.Config, logger *slog.Logger) error {
-	topics := []string{
-		"transaction_created",
-		"budget_updated",
-		"goal_progress_updated",
-		"notification_created",
+func (b *MsgBroker) enqueue(msg kafka.Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(b.outbox) >= outboxSize {
+		metrics.KafkaDropped(msg.Topic)
+		b.logger.Error("Outbox full, dropping message", "topic", msg.Topic)
+		return ErrOutboxFull
+	}
+	b.outbox = append(b.outbox, msg)
+	b.logger.Warn("Message queued in outbox", "topic", msg.Topic, "pending", len(b.outbox))
+	return nil
+}
+
+func (b *MsgBroker) retryLoop() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(outboxRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), outboxRetryInterval)
+			b.flush(ctx)
+			cancel()
+		}
+	}
+}
+
+// flush tries to publish everything in the outbox and keeps what still fails.
+func (b *MsgBroker) flush(ctx context.Context) error {
+	b.mu.Lock()
+	pending := b.outbox
+	b.outbox = nil
+	b.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	err := b.writer.WriteMessages(ctx, pending...)
+	if err == nil {
+		b.logger.Info("Outbox flushed", "messages", len(pending))
+		return nil
 	}
 
-	conn, err := kafka.DialContext(context.Background(), "tcp", config.Kafka.Brokers)
+	var failed []kafka.Message
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for i, werr := range writeErrs {
+			if werr != nil {
+				failed = append(failed, pending[i])
+			}
+		}
+	} else {
+		failed = pending
+	}
+
+	b.mu.Lock()
+	b.outbox = append(failed, b.outbox...)
+	b.mu.Unlock()
+
+	b.logger.Error("Failed to flush outbox", "pending", len(failed), "error", err.Error())
+	return err
+}
+
+// Pending returns the number of messages waiting in the outbox.
+func (b *MsgBroker) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.outbox)
+}
+
+// Close stops the retry loop, makes a final attempt to flush the outbox,
+// drops what is still in it and closes the underlying writer, which
+// flushes any buffered batches.
+func (b *MsgBroker) Close(ctx context.Context) error {
+	close(b.stop)
+	<-b.done
+
+	flushErr := b.flush(ctx)
+	b.mu.Lock()
+	dropped := b.outbox
+	b.outbox = nil
+	b.mu.Unlock()
+	if len(dropped) > 0 {
+		for _, msg := range dropped {
+			metrics.KafkaDropped(msg.Topic)
+		}
+		b.logger.Error("Dropping undelivered outbox messages", "messages", len(dropped))
+	}
+
+	return errors.Join(flushErr, b.writer.Close())
+}
+
+// Ping checks that the broker accepts connections.
+func Ping(ctx context.Context, config *config.Config) error {
+	conn, err := Dialer(config).DialContext(ctx, "tcp", config.Kafka.BrokerList()[0])
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// CheckTopics checks that the broker is reachable and every topic exists.
+func CheckTopics(ctx context.Context, config *config.Config) error {
+	conn, err := Dialer(config).DialContext(ctx, "tcp", config.Kafka.BrokerList()[0])
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	partitions, err := conn.ReadPartitions(Topics...)
+	if err != nil {
+		return err
+	}
+
+	found := make(map[string]bool, len(partitions))
+	for _, p := range partitions {
+		found[p.Topic] = true
+	}
+	for _, topic := range Topics {
+		if !found[topic] {
+			return fmt.Errorf("topic %s does not exist", topic)
+		}
+	}
+
+	return nil
+}
+
+func CreateTopics(config *config.Config, logger *slog.Logger) error {
+	conn, err := Dialer(config).DialContext(context.Background(), "tcp", config.Kafka.BrokerList()[0])
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	for _, topic := range topics {
+	for _, topic := range Topics {
 		partitions, err := conn.ReadPartitions(topic)
 		if err == nil && len(partitions) > 0 {
 			logger.Info("Topic already exists", "topic", topic)
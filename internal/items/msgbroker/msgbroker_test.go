@@ -0,0 +1,33 @@
+package msgbroker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestPublishFailureIsNotReportedAsDelivered(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	// Nothing listens on port 1, so every publish fails.
+	b := NewMsgBroker(&kafka.Writer{Addr: kafka.TCP("127.0.0.1:1"), MaxAttempts: 1}, logger)
+
+	err := b.UserDeleted(context.Background(), []byte(`{"user_id":"user-1"}`))
+	if !errors.Is(err, ErrQueued) {
+		t.Fatalf("publish error = %v, want %v", err, ErrQueued)
+	}
+	if pending := b.Pending(); pending != 1 {
+		t.Fatalf("Pending = %d, want 1", pending)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	b.Close(ctx)
+	if pending := b.Pending(); pending != 0 {
+		t.Fatalf("Pending after Close = %d, want the undelivered message dropped", pending)
+	}
+}
@@ -0,0 +1,65 @@
+package msgbroker
+
+import (
+	"context"
+	"time"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// Dialer returns the dialer used for every broker connection, with SASL
+// authentication when kafka.sasl_mechanism is set.
+func Dialer(config *config.Config) *kafka.Dialer {
+	dialer := &kafka.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+	}
+	if config.Kafka.SASLMechanism != "none" {
+		dialer.SASLMechanism = credentialMechanism{config: config}
+	}
+	return dialer
+}
+
+// credentialMechanism builds the configured SASL mechanism on every new
+// connection, reading the credentials from the secrets manager so a
+// rotated password is used without restarting the writer.
+type credentialMechanism struct {
+	config *config.Config
+}
+
+func (m credentialMechanism) Name() string {
+	switch m.config.Kafka.SASLMechanism {
+	case "scram-sha-256":
+		return scram.SHA256.Name()
+	case "scram-sha-512":
+		return scram.SHA512.Name()
+	default:
+		return plain.Mechanism{}.Name()
+	}
+}
+
+func (m credentialMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	username, password := m.config.KafkaCredentials()
+
+	var (
+		mechanism sasl.Mechanism
+		err       error
+	)
+	switch m.config.Kafka.SASLMechanism {
+	case "scram-sha-256":
+		mechanism, err = scram.Mechanism(scram.SHA256, username, password)
+	case "scram-sha-512":
+		mechanism, err = scram.Mechanism(scram.SHA512, username, password)
+	default:
+		mechanism = plain.Mechanism{Username: username, Password: password}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return mechanism.Start(ctx)
+}
@@ -0,0 +1,210 @@
+// Package rbac keeps per-user role assignments in a Store and mirrors them
+// into the Casbin grouping policy, on top of the static role hierarchy in
+// policy.csv. The policy file itself is never written.
+package rbac
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	casbin "github.com/casbin/casbin/v2"
+)
+
+const (
+	RoleUser       = "user"
+	RoleAdmin      = "admin"
+	RoleSuperadmin = "superadmin"
+)
+
+// ScopePrefix marks the policy subjects that grant personal access token
+// scopes, e.g. "scope:transactions:read".
+const ScopePrefix = "scope:"
+
+// Roles lists the roles from least to most privileged.
+var Roles = []string{RoleUser, RoleAdmin, RoleSuperadmin}
+
+var (
+	ErrUnknownRole    = errors.New("unknown role")
+	ErrLastSuperadmin = errors.New("cannot remove the last superadmin")
+	ErrSelfRoleChange = errors.New("cannot change your own role")
+	// ErrNoRole is returned for users the registry has no role for, who
+	// may be staff that have not logged in since it was introduced.
+	ErrNoRole = errors.New("user's role is not known until they log in")
+)
+
+// Store keeps role assignments where every gateway instance sees them.
+type Store interface {
+	// Roles returns every assignment by user ID, along with the version
+	// they were read at.
+	Roles(ctx context.Context) (map[string]string, int64, error)
+	// RolesVersion changes whenever an assignment does.
+	RolesVersion(ctx context.Context) (int64, error)
+	// Role returns the role assigned to userId, or "" if none is.
+	Role(ctx context.Context, userId string) (string, error)
+	RoleMembers(ctx context.Context, role string) ([]string, error)
+	// SetRole assigns role to userId and returns the role assigned before.
+	// With onlyIfUnset it leaves an assigned role alone; without, it fails
+	// with ErrNoRole if none is assigned. It fails with ErrLastSuperadmin
+	// instead of demoting the only superadmin.
+	SetRole(ctx context.Context, userId, role string, onlyIfUnset bool) (string, error)
+}
+
+type Registry struct {
+	enforcer *casbin.SyncedEnforcer
+	store    Store
+	// mu keeps the enforcer's user groupings and version in step.
+	mu      sync.Mutex
+	version int64
+}
+
+// New returns a registry backed by store. Groupings added to enforcer are
+// kept in memory only; call Load to fill them in.
+func New(enforcer *casbin.SyncedEnforcer, store Store) *Registry {
+	enforcer.EnableAutoSave(false)
+	return &Registry{enforcer: enforcer, store: store}
+}
+
+func IsRole(name string) bool {
+	return slices.Contains(Roles, name)
+}
+
+// RoleOf returns the role assigned to userId, or "" if none is.
+func (r *Registry) RoleOf(ctx context.Context, userId string) (string, error) {
+	return r.store.Role(ctx, userId)
+}
+
+// Scopes returns the personal access token scopes defined in the policy,
+// sorted.
+func (r *Registry) Scopes() ([]string, error) {
+	subjects, err := r.enforcer.GetAllSubjects()
+	if err != nil {
+		return nil, err
+	}
+	scopes := []string{}
+	for _, subject := range subjects {
+		if scope, ok := strings.CutPrefix(subject, ScopePrefix); ok && !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+	slices.Sort(scopes)
+	return scopes, nil
+}
+
+// Members returns the user IDs directly assigned role, sorted.
+func (r *Registry) Members(ctx context.Context, role string) ([]string, error) {
+	members, err := r.store.RoleMembers(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(members)
+	return members, nil
+}
+
+// Assign replaces userId's role and returns the one it replaced. Users
+// without a role fail with ErrNoRole, since the registry cannot tell
+// whether they are staff, and demoting the only superadmin fails with
+// ErrLastSuperadmin.
+func (r *Registry) Assign(ctx context.Context, userId, role string) (string, error) {
+	if !IsRole(role) {
+		return "", ErrUnknownRole
+	}
+	previous, err := r.store.SetRole(ctx, userId, role, false)
+	if err != nil {
+		return "", err
+	}
+	return previous, r.apply(userId, role)
+}
+
+// Ensure assigns role to userId only if no role is assigned yet. It
+// records users with the role the auth service gave them the first time
+// they log in, and admins it has just created.
+func (r *Registry) Ensure(ctx context.Context, userId, role string) error {
+	if !IsRole(role) {
+		return ErrUnknownRole
+	}
+	previous, err := r.store.SetRole(ctx, userId, role, true)
+	if err != nil {
+		return err
+	}
+	if previous != "" {
+		role = previous
+	}
+	return r.apply(userId, role)
+}
+
+// apply sets userId's grouping in the enforcer to role.
+func (r *Registry) apply(userId, role string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.enforcer.DeleteRolesForUser(userId); err != nil {
+		return err
+	}
+	_, err := r.enforcer.AddRoleForUser(userId, role)
+	return err
+}
+
+// Load replaces the user groupings in the enforcer with the assignments in
+// the store. Groupings between roles, from policy.csv, are kept.
+func (r *Registry) Load(ctx context.Context) error {
+	roles, version, err := r.store.Roles(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	groupings, err := r.enforcer.GetGroupingPolicy()
+	if err != nil {
+		return err
+	}
+	for _, rule := range groupings {
+		if len(rule) < 2 || IsRole(rule[0]) || roles[rule[0]] == rule[1] {
+			continue
+		}
+		if _, err := r.enforcer.RemoveGroupingPolicy(rule[0], rule[1]); err != nil {
+			return err
+		}
+	}
+	for userId, role := range roles {
+		if !IsRole(role) {
+			continue
+		}
+		if _, err := r.enforcer.AddGroupingPolicy(userId, role); err != nil {
+			return err
+		}
+	}
+	r.version = version
+	return nil
+}
+
+// Watch reloads the assignments every interval, if another gateway changed
+// them, until ctx is done.
+func (r *Registry) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			version, err := r.store.RolesVersion(ctx)
+			r.mu.Lock()
+			changed := version != r.version
+			r.mu.Unlock()
+			if err == nil && changed {
+				err = r.Load(ctx)
+			}
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Failed to reload role assignments", "error", err.Error())
+			}
+		}
+	}
+}
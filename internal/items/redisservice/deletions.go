@@ -0,0 +1,143 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Deletion is a user's request to delete their account, carried out once
+// DueAt has passed.
+type Deletion struct {
+	UserID      string
+	RequestedAt time.Time
+	DueAt       time.Time
+}
+
+// deletionsKey is a sorted set of user IDs scored by when their deletion
+// is due.
+const deletionsKey = "deletions"
+
+func deletionKey(userId string) string {
+	return fmt.Sprintf("deletion:%s", userId)
+}
+
+// claimDeletion returns a user whose deletion is due at ARGV[1] and
+// postpones it to ARGV[2], so a deletion that fails, or whose gateway
+// stops, is retried then. Claimed deletions can no longer be cancelled.
+var claimDeletion = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1], "LIMIT", 0, 1)
+if #ids == 0 then
+	return false
+end
+redis.call("ZADD", KEYS[1], ARGV[2], ids[1])
+redis.call("HSET", "deletion:" .. ids[1], "started", "1")
+return ids[1]
+`)
+
+// cancelDeletion removes the deletion of ARGV[1] unless it has started.
+var cancelDeletion = redis.NewScript(`
+if redis.call("HGET", KEYS[2], "started") then
+	return 0
+end
+redis.call("DEL", KEYS[2])
+return redis.call("ZREM", KEYS[1], ARGV[1])
+`)
+
+// ScheduleDeletion records deletion, replacing an earlier one of the same
+// user.
+func (r *RedisService) ScheduleDeletion(ctx context.Context, deletion Deletion) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, deletionKey(deletion.UserID),
+			"requested_at", deletion.RequestedAt.Unix(),
+			"due_at", deletion.DueAt.Unix(),
+		)
+		pipe.ZAdd(ctx, deletionsKey, &redis.Z{Score: float64(deletion.DueAt.Unix()), Member: deletion.UserID})
+		return nil
+	})
+	return err
+}
+
+// GetDeletion returns ErrTokenNotFound if userId has no deletion pending.
+func (r *RedisService) GetDeletion(ctx context.Context, userId string) (Deletion, error) {
+	values, err := r.redisDb.HGetAll(ctx, deletionKey(userId)).Result()
+	if err != nil {
+		return Deletion{}, err
+	}
+	if values["requested_at"] == "" {
+		return Deletion{}, ErrTokenNotFound
+	}
+
+	deletion := Deletion{UserID: userId}
+	for field, t := range map[string]*time.Time{"requested_at": &deletion.RequestedAt, "due_at": &deletion.DueAt} {
+		unix, err := strconv.ParseInt(values[field], 10, 64)
+		if err != nil {
+			return Deletion{}, err
+		}
+		*t = time.Unix(unix, 0)
+	}
+	return deletion, nil
+}
+
+// CancelDeletion reports whether userId had a deletion pending that has
+// not started yet, and cancels it.
+func (r *RedisService) CancelDeletion(ctx context.Context, userId string) (bool, error) {
+	cancelled, err := cancelDeletion.Run(ctx, r.redisDb, []string{deletionsKey, deletionKey(userId)}, userId).Int()
+	return cancelled == 1, err
+}
+
+// ClaimDueDeletion returns a user whose deletion is due at now, or "", and
+// holds it for lease.
+func (r *RedisService) ClaimDueDeletion(ctx context.Context, now time.Time, lease time.Duration) (string, error) {
+	userId, err := claimDeletion.Run(ctx, r.redisDb, []string{deletionsKey}, now.Unix(), now.Add(lease).Unix()).Text()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return userId, err
+}
+
+// ForgetUser removes everything the gateway stores about userId once the
+// user has been deleted, including their role and pending deletion. The
+// marker rejecting their tokens is kept until it expires.
+func (r *RedisService) ForgetUser(ctx context.Context, userId string) error {
+	if err := r.ForgetSessions(ctx, userId); err != nil {
+		return err
+	}
+	tokens, err := r.redisDb.SMembers(ctx, userPatsKey(userId)).Result()
+	if err != nil {
+		return err
+	}
+	links, err := r.redisDb.SMembers(ctx, userOIDCLinksKey(userId)).Result()
+	if err != nil {
+		return err
+	}
+
+	keys := []string{
+		profileKey(userId),
+		userPatsKey(userId),
+		userOIDCLinksKey(userId),
+		mfaKey("secret", userId),
+		mfaKey("pending", userId),
+		mfaKey("recovery", userId),
+		mfaKey("last_step", userId),
+		mfaKey("failures", userId),
+		inactiveKey(userId),
+		unverifiedKey(userId),
+		deletionKey(userId),
+	}
+	for _, id := range tokens {
+		keys = append(keys, patKey(id))
+	}
+	keys = append(keys, links...)
+
+	_, err = r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, keys...)
+		pipe.ZRem(ctx, deletionsKey, userId)
+		removeRole.Eval(ctx, pipe, []string{rolesKey, rolesVersionKey}, userId)
+		return nil
+	})
+	return err
+}
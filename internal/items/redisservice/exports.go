@@ -0,0 +1,180 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Export job states.
+const (
+	ExportPending = "pending"
+	ExportDone    = "done"
+	ExportFailed  = "failed"
+)
+
+// ExportJob is a data export of UserID. Its archive is stored next to it
+// once Status is ExportDone.
+type ExportJob struct {
+	ID         string
+	UserID     string
+	Status     string
+	Error      string
+	Size       int64
+	CreatedAt  time.Time
+	FinishedAt time.Time
+}
+
+// exportsPendingKey is a sorted set of pending export jobs scored by when
+// they should have finished.
+const exportsPendingKey = "exports:pending"
+
+func exportKey(id string) string {
+	return fmt.Sprintf("export:%s", id)
+}
+
+func exportArchiveKey(id string) string {
+	return fmt.Sprintf("export:archive:%s", id)
+}
+
+func exportLimitKey(userId string) string {
+	return fmt.Sprintf("export:limit:%s", userId)
+}
+
+// finishExport takes job ARGV[1] off the pending jobs KEYS[3], stores the
+// archive ARGV[2], unless it is empty, for as long as the job KEYS[1] has
+// left and sets the remaining ARGV as job fields. It does nothing else if
+// the job has expired.
+var finishExport = redis.NewScript(`
+redis.call("ZREM", KEYS[3], ARGV[1])
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl <= 0 then
+	return 0
+end
+if ARGV[2] ~= "" then
+	redis.call("SET", KEYS[2], ARGV[2], "PX", ttl)
+end
+redis.call("HSET", KEYS[1], unpack(ARGV, 3))
+return 1
+`)
+
+// failStaleExports marks the pending jobs in KEYS[1] that should have
+// finished by ARGV[1] failed with reason ARGV[2] at ARGV[3], and returns
+// how many there were.
+var failStaleExports = redis.NewScript(`
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+local failed = 0
+for _, id in ipairs(ids) do
+	redis.call("ZREM", KEYS[1], id)
+	local key = "export:" .. id
+	if redis.call("HGET", key, "status") == "pending" then
+		redis.call("HSET", key, "status", "failed", "error", ARGV[2], "finished_at", ARGV[3])
+		failed = failed + 1
+	end
+end
+return failed
+`)
+
+// countExport counts an export against a fixed window of ARGV[1]
+// milliseconds and returns the count and the time left in the window.
+var countExport = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {n, redis.call("PTTL", KEYS[1])}
+`)
+
+// AllowExport counts an export by userId and reports whether it is within
+// limit per window, and if not, how long until the window ends.
+func (r *RedisService) AllowExport(ctx context.Context, userId string, limit int, window time.Duration) (bool, time.Duration, error) {
+	result, err := countExport.Run(ctx, r.redisDb, []string{exportLimitKey(userId)}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	if result[0] > int64(limit) {
+		return false, time.Duration(result[1]) * time.Millisecond, nil
+	}
+	return true, 0, nil
+}
+
+// CreateExportJob stores job, which with its archive is kept for ttl. It
+// counts as stale if it is still pending at deadline.
+func (r *RedisService) CreateExportJob(ctx context.Context, job ExportJob, ttl time.Duration, deadline time.Time) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, exportKey(job.ID),
+			"user_id", job.UserID,
+			"status", job.Status,
+			"created_at", job.CreatedAt.Unix(),
+		)
+		pipe.Expire(ctx, exportKey(job.ID), ttl)
+		pipe.ZAdd(ctx, exportsPendingKey, &redis.Z{Score: float64(deadline.Unix()), Member: job.ID})
+		return nil
+	})
+	return err
+}
+
+// GetExportJob returns ErrTokenNotFound once the job has expired.
+func (r *RedisService) GetExportJob(ctx context.Context, id string) (ExportJob, error) {
+	values, err := r.redisDb.HGetAll(ctx, exportKey(id)).Result()
+	if err != nil {
+		return ExportJob{}, err
+	}
+	if values["user_id"] == "" {
+		return ExportJob{}, ErrTokenNotFound
+	}
+
+	job := ExportJob{
+		ID:     id,
+		UserID: values["user_id"],
+		Status: values["status"],
+		Error:  values["error"],
+	}
+	if values["size"] != "" {
+		if job.Size, err = strconv.ParseInt(values["size"], 10, 64); err != nil {
+			return ExportJob{}, err
+		}
+	}
+	for field, t := range map[string]*time.Time{"created_at": &job.CreatedAt, "finished_at": &job.FinishedAt} {
+		if values[field] == "" {
+			continue
+		}
+		unix, err := strconv.ParseInt(values[field], 10, 64)
+		if err != nil {
+			return ExportJob{}, err
+		}
+		*t = time.Unix(unix, 0)
+	}
+	return job, nil
+}
+
+// CompleteExportJob stores the archive of job id and marks it done.
+func (r *RedisService) CompleteExportJob(ctx context.Context, id string, archive []byte, t time.Time) error {
+	return finishExport.Run(ctx, r.redisDb, []string{exportKey(id), exportArchiveKey(id), exportsPendingKey},
+		id, archive, "status", ExportDone, "size", len(archive), "finished_at", t.Unix()).Err()
+}
+
+// FailExportJob marks job id failed with reason.
+func (r *RedisService) FailExportJob(ctx context.Context, id, reason string, t time.Time) error {
+	return finishExport.Run(ctx, r.redisDb, []string{exportKey(id), exportArchiveKey(id), exportsPendingKey},
+		id, "", "status", ExportFailed, "error", reason, "finished_at", t.Unix()).Err()
+}
+
+// FailStaleExportJobs marks jobs still pending past their deadline at t
+// failed with reason and returns how many there were.
+func (r *RedisService) FailStaleExportJobs(ctx context.Context, reason string, t time.Time) (int64, error) {
+	return failStaleExports.Run(ctx, r.redisDb, []string{exportsPendingKey}, t.Unix(), reason, t.Unix()).Int64()
+}
+
+// ExportArchive returns the archive of job id, or ErrTokenNotFound once it
+// has expired.
+func (r *RedisService) ExportArchive(ctx context.Context, id string) ([]byte, error) {
+	archive, err := r.redisDb.Get(ctx, exportArchiveKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrTokenNotFound
+	}
+	return archive, err
+}
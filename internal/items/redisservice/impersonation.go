@@ -0,0 +1,63 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Impersonation is an admin acting as a user until ExpiresAt.
+type Impersonation struct {
+	ID         string
+	AdminID    string
+	UserID     string
+	Reason     string
+	AllowWrite bool
+	ExpiresAt  time.Time
+}
+
+func impersonationKey(id string) string {
+	return fmt.Sprintf("impersonation:%s", id)
+}
+
+// StartImpersonation records imp until it expires.
+func (r *RedisService) StartImpersonation(ctx context.Context, imp Impersonation) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, impersonationKey(imp.ID),
+			"admin_id", imp.AdminID,
+			"user_id", imp.UserID,
+			"reason", imp.Reason,
+			"allow_write", imp.AllowWrite,
+			"expires_at", imp.ExpiresAt.Unix(),
+		)
+		pipe.ExpireAt(ctx, impersonationKey(imp.ID), imp.ExpiresAt)
+		return nil
+	})
+	return err
+}
+
+// ImpersonationActive reports whether impersonation id has neither
+// expired nor been ended.
+func (r *RedisService) ImpersonationActive(ctx context.Context, id string) (bool, error) {
+	n, err := r.redisDb.Exists(ctx, impersonationKey(id)).Result()
+	return n == 1, err
+}
+
+// ImpersonationAdmin returns the admin who started impersonation id, or
+// "" if it has expired or been ended.
+func (r *RedisService) ImpersonationAdmin(ctx context.Context, id string) (string, error) {
+	adminId, err := r.redisDb.HGet(ctx, impersonationKey(id), "admin_id").Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return adminId, err
+}
+
+// EndImpersonation ends impersonation id early and reports whether it was
+// still active.
+func (r *RedisService) EndImpersonation(ctx context.Context, id string) (bool, error) {
+	deleted, err := r.redisDb.Del(ctx, impersonationKey(id)).Result()
+	return deleted == 1, err
+}
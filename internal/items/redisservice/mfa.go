@@ -0,0 +1,201 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// MFAChallenge is a login waiting for its second factor. Tokens holds the
+// sealed tokens that are released once a valid code is given.
+type MFAChallenge struct {
+	UserID string
+	Tokens string
+}
+
+func mfaKey(kind, userId string) string {
+	return fmt.Sprintf("mfa:%s:%s", kind, userId)
+}
+
+func mfaChallengeKey(id string) string {
+	return fmt.Sprintf("mfa:challenge:%s", id)
+}
+
+// acceptStep stores ARGV[1] as the last used TOTP step unless it is not
+// newer than the one stored, so each code works only once.
+var acceptStep = redis.NewScript(`
+local last = tonumber(redis.call("GET", KEYS[1]) or "-1")
+if tonumber(ARGV[1]) <= last then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "EX", ARGV[2])
+return 1
+`)
+
+// countAttempt increments the attempts of a challenge that still exists,
+// so a late wrong code cannot recreate an expired one without a TTL.
+var countAttempt = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return 0
+end
+return redis.call("HINCRBY", KEYS[1], "attempts", 1)
+`)
+
+// countFailure counts a failed second factor in a window of ARGV[2]
+// milliseconds. From the ARGV[1]th failure on, each one restarts the
+// window, so the lockout lasts a full window after the last of them.
+var countFailure = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 or n >= tonumber(ARGV[1]) then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return n
+`)
+
+// SetPendingMFA keeps a sealed secret until it is activated or ttl passes.
+func (r *RedisService) SetPendingMFA(ctx context.Context, userId, sealedSecret string, ttl time.Duration) error {
+	return r.redisDb.Set(ctx, mfaKey("pending", userId), sealedSecret, ttl).Err()
+}
+
+// GetPendingMFA returns the secret awaiting activation, or "" if none is.
+func (r *RedisService) GetPendingMFA(ctx context.Context, userId string) (string, error) {
+	sealed, err := r.redisDb.Get(ctx, mfaKey("pending", userId)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return sealed, err
+}
+
+// EnableMFA activates sealedSecret for userId and replaces the recovery
+// codes with codeHashes.
+func (r *RedisService) EnableMFA(ctx context.Context, userId, sealedSecret string, codeHashes []string) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, mfaKey("secret", userId), sealedSecret, 0)
+		pipe.Del(ctx, mfaKey("pending", userId), mfaKey("recovery", userId), mfaKey("last_step", userId))
+		pipe.SAdd(ctx, mfaKey("recovery", userId), stringsToAny(codeHashes)...)
+		return nil
+	})
+	return err
+}
+
+// ReplaceRecoveryCodes discards userId's remaining recovery codes in
+// favour of codeHashes.
+func (r *RedisService) ReplaceRecoveryCodes(ctx context.Context, userId string, codeHashes []string) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, mfaKey("recovery", userId))
+		pipe.SAdd(ctx, mfaKey("recovery", userId), stringsToAny(codeHashes)...)
+		return nil
+	})
+	return err
+}
+
+func (r *RedisService) DisableMFA(ctx context.Context, userId string) error {
+	return r.redisDb.Del(ctx, mfaKey("secret", userId), mfaKey("recovery", userId), mfaKey("last_step", userId)).Err()
+}
+
+// GetMFASecret returns the sealed secret of userId, or "" if 2FA is off.
+func (r *RedisService) GetMFASecret(ctx context.Context, userId string) (string, error) {
+	sealed, err := r.redisDb.Get(ctx, mfaKey("secret", userId)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return sealed, err
+}
+
+// AcceptMFAStep reports whether the TOTP step is newer than the last one
+// used by userId and records it. ttl only needs to cover the accepted
+// clock skew.
+func (r *RedisService) AcceptMFAStep(ctx context.Context, userId string, step int64, ttl time.Duration) (bool, error) {
+	accepted, err := acceptStep.Run(ctx, r.redisDb, []string{mfaKey("last_step", userId)}, step, int(ttl.Seconds())).Int()
+	return accepted == 1, err
+}
+
+// UseRecoveryCode removes codeHash from userId's recovery codes and
+// reports whether it was there.
+func (r *RedisService) UseRecoveryCode(ctx context.Context, userId, codeHash string) (bool, error) {
+	removed, err := r.redisDb.SRem(ctx, mfaKey("recovery", userId), codeHash).Result()
+	return removed == 1, err
+}
+
+func (r *RedisService) RecoveryCodesLeft(ctx context.Context, userId string) (int64, error) {
+	return r.redisDb.SCard(ctx, mfaKey("recovery", userId)).Result()
+}
+
+func (r *RedisService) StoreMFAChallenge(ctx context.Context, id string, challenge MFAChallenge, ttl time.Duration) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, mfaChallengeKey(id), "user_id", challenge.UserID, "tokens", challenge.Tokens, "attempts", 0)
+		pipe.Expire(ctx, mfaChallengeKey(id), ttl)
+		return nil
+	})
+	return err
+}
+
+// GetMFAChallenge returns ErrTokenNotFound once the challenge has expired
+// or been used.
+func (r *RedisService) GetMFAChallenge(ctx context.Context, id string) (MFAChallenge, error) {
+	values, err := r.redisDb.HGetAll(ctx, mfaChallengeKey(id)).Result()
+	if err != nil {
+		return MFAChallenge{}, err
+	}
+	if values["user_id"] == "" {
+		return MFAChallenge{}, ErrTokenNotFound
+	}
+	return MFAChallenge{UserID: values["user_id"], Tokens: values["tokens"]}, nil
+}
+
+// FailMFAChallenge counts a wrong code and returns the attempts so far.
+func (r *RedisService) FailMFAChallenge(ctx context.Context, id string) (int64, error) {
+	return countAttempt.Run(ctx, r.redisDb, []string{mfaChallengeKey(id)}).Int64()
+}
+
+// EndMFAChallenge deletes the challenge and reports whether this call did
+// so, so concurrent verifications release the tokens only once.
+func (r *RedisService) EndMFAChallenge(ctx context.Context, id string) (bool, error) {
+	deleted, err := r.redisDb.Del(ctx, mfaChallengeKey(id)).Result()
+	return deleted == 1, err
+}
+
+// MFALockout returns how long userId stays locked out after limit failed
+// second factors, or 0 if the user is not locked out.
+func (r *RedisService) MFALockout(ctx context.Context, userId string, limit int) (time.Duration, error) {
+	var failures *redis.StringCmd
+	var ttl *redis.DurationCmd
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		failures = pipe.Get(ctx, mfaKey("failures", userId))
+		ttl = pipe.PTTL(ctx, mfaKey("failures", userId))
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return 0, err
+	}
+	n, err := failures.Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil || n < int64(limit) || ttl.Val() <= 0 {
+		return 0, err
+	}
+	return ttl.Val(), nil
+}
+
+// FailMFA counts a failed second factor of userId within window and
+// returns the failures so far. Reaching limit locks the user out until
+// window has passed without another failure.
+func (r *RedisService) FailMFA(ctx context.Context, userId string, limit int, window time.Duration) (int64, error) {
+	return countFailure.Run(ctx, r.redisDb, []string{mfaKey("failures", userId)}, limit, window.Milliseconds()).Int64()
+}
+
+// ClearMFAFailures forgets the failed second factors of userId.
+func (r *RedisService) ClearMFAFailures(ctx context.Context, userId string) error {
+	return r.redisDb.Del(ctx, mfaKey("failures", userId)).Err()
+}
+
+func stringsToAny(values []string) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
@@ -0,0 +1,78 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// OIDCLogin is a login in progress at an identity provider, kept under
+// its state parameter until the provider redirects back.
+type OIDCLogin struct {
+	Provider string
+	Nonce    string
+	Verifier string
+}
+
+func oidcStateKey(state string) string {
+	return fmt.Sprintf("oidc:state:%s", state)
+}
+
+func oidcLinkKey(provider, subject string) string {
+	return fmt.Sprintf("oidc:link:%s:%s", provider, subject)
+}
+
+func userOIDCLinksKey(userId string) string {
+	return fmt.Sprintf("oidc:links:user:%s", userId)
+}
+
+func (r *RedisService) StoreOIDCLogin(ctx context.Context, state string, login OIDCLogin, ttl time.Duration) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, oidcStateKey(state), "provider", login.Provider, "nonce", login.Nonce, "verifier", login.Verifier)
+		pipe.Expire(ctx, oidcStateKey(state), ttl)
+		return nil
+	})
+	return err
+}
+
+// TakeOIDCLogin returns and deletes the login for state, so a callback
+// can only be completed once. It returns ErrTokenNotFound for an unknown
+// or expired state.
+func (r *RedisService) TakeOIDCLogin(ctx context.Context, state string) (OIDCLogin, error) {
+	var values *redis.StringStringMapCmd
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		values = pipe.HGetAll(ctx, oidcStateKey(state))
+		pipe.Del(ctx, oidcStateKey(state))
+		return nil
+	})
+	if err != nil {
+		return OIDCLogin{}, err
+	}
+	login := values.Val()
+	if login["provider"] == "" {
+		return OIDCLogin{}, ErrTokenNotFound
+	}
+	return OIDCLogin{Provider: login["provider"], Nonce: login["nonce"], Verifier: login["verifier"]}, nil
+}
+
+// LinkedUser returns the user an external identity is linked to, or "".
+func (r *RedisService) LinkedUser(ctx context.Context, provider, subject string) (string, error) {
+	userId, err := r.redisDb.Get(ctx, oidcLinkKey(provider, subject)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return userId, err
+}
+
+// LinkIdentity links an external identity to userId. Links have no
+// expiry; the provider's subject never changes for the same account.
+func (r *RedisService) LinkIdentity(ctx context.Context, provider, subject, userId string) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, oidcLinkKey(provider, subject), userId, 0)
+		pipe.SAdd(ctx, userOIDCLinksKey(userId), oidcLinkKey(provider, subject))
+		return nil
+	})
+	return err
+}
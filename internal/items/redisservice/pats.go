@@ -0,0 +1,130 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// PersonalToken is a personal access token without its secret; only the
+// SHA-256 hash of the full token is stored.
+type PersonalToken struct {
+	ID         string
+	UserID     string
+	Name       string
+	Scopes     []string
+	Hash       string
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastUsedAt time.Time
+}
+
+func patKey(id string) string {
+	return fmt.Sprintf("pat:%s", id)
+}
+
+func userPatsKey(userId string) string {
+	return fmt.Sprintf("pat:user:%s", userId)
+}
+
+// touchPat sets last_used_at only on a token that still exists, so a late
+// update cannot recreate an expired one without a TTL.
+var touchPat = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	redis.call("HSET", KEYS[1], "last_used_at", ARGV[1])
+end
+return 0
+`)
+
+// CreatePersonalToken stores token until it expires.
+func (r *RedisService) CreatePersonalToken(ctx context.Context, token PersonalToken) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, patKey(token.ID),
+			"user_id", token.UserID,
+			"name", token.Name,
+			"scopes", strings.Join(token.Scopes, " "),
+			"hash", token.Hash,
+			"created_at", token.CreatedAt.Unix(),
+			"expires_at", token.ExpiresAt.Unix(),
+		)
+		pipe.ExpireAt(ctx, patKey(token.ID), token.ExpiresAt)
+		pipe.SAdd(ctx, userPatsKey(token.UserID), token.ID)
+		return nil
+	})
+	return err
+}
+
+// GetPersonalToken returns ErrTokenNotFound once the token has expired or
+// been revoked.
+func (r *RedisService) GetPersonalToken(ctx context.Context, id string) (PersonalToken, error) {
+	values, err := r.redisDb.HGetAll(ctx, patKey(id)).Result()
+	if err != nil {
+		return PersonalToken{}, err
+	}
+	if values["user_id"] == "" {
+		return PersonalToken{}, ErrTokenNotFound
+	}
+
+	token := PersonalToken{
+		ID:     id,
+		UserID: values["user_id"],
+		Name:   values["name"],
+		Scopes: strings.Fields(values["scopes"]),
+		Hash:   values["hash"],
+	}
+	for field, t := range map[string]*time.Time{"created_at": &token.CreatedAt, "expires_at": &token.ExpiresAt, "last_used_at": &token.LastUsedAt} {
+		if values[field] == "" {
+			continue
+		}
+		unix, err := strconv.ParseInt(values[field], 10, 64)
+		if err != nil {
+			return PersonalToken{}, err
+		}
+		*t = time.Unix(unix, 0)
+	}
+	return token, nil
+}
+
+// ListPersonalTokens returns userId's live tokens and forgets the IDs of
+// expired ones.
+func (r *RedisService) ListPersonalTokens(ctx context.Context, userId string) ([]PersonalToken, error) {
+	ids, err := r.redisDb.SMembers(ctx, userPatsKey(userId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := []PersonalToken{}
+	for _, id := range ids {
+		token, err := r.GetPersonalToken(ctx, id)
+		if err == ErrTokenNotFound {
+			r.redisDb.SRem(ctx, userPatsKey(userId), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, nil
+}
+
+// RevokePersonalToken deletes userId's token id and reports whether it
+// existed. Tokens of other users are left alone.
+func (r *RedisService) RevokePersonalToken(ctx context.Context, userId, id string) (bool, error) {
+	removed, err := r.redisDb.SRem(ctx, userPatsKey(userId), id).Result()
+	if err != nil || removed == 0 {
+		return false, err
+	}
+	deleted, err := r.redisDb.Del(ctx, patKey(id)).Result()
+	return deleted == 1, err
+}
+
+// TouchPersonalToken records that token id was used at t. It does nothing
+// if the token has expired in the meantime.
+func (r *RedisService) TouchPersonalToken(ctx context.Context, id string, t time.Time) error {
+	return touchPat.Run(ctx, r.redisDb, []string{patKey(id)}, t.Unix()).Err()
+}
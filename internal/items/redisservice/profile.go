@@ -0,0 +1,38 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+
+	"gateway-service/internal/pkg/profile"
+)
+
+func profileKey(userId string) string {
+	return fmt.Sprintf("profile:%s", userId)
+}
+
+// GetPreferences returns nil if userId never saved preferences.
+func (r *RedisService) GetPreferences(ctx context.Context, userId string) (*profile.Preferences, error) {
+	values, err := r.redisDb.HGetAll(ctx, profileKey(userId)).Result()
+	if err != nil || len(values) == 0 {
+		return nil, err
+	}
+	return &profile.Preferences{
+		Currency:         values["currency"],
+		Locale:           values["locale"],
+		Timezone:         values["timezone"],
+		FirstDayOfWeek:   values["first_day_of_week"],
+		DefaultAccountID: values["default_account_id"],
+	}, nil
+}
+
+// SavePreferences replaces userId's preferences. They do not expire.
+func (r *RedisService) SavePreferences(ctx context.Context, userId string, prefs profile.Preferences) error {
+	return r.redisDb.HSet(ctx, profileKey(userId),
+		"currency", prefs.Currency,
+		"locale", prefs.Locale,
+		"timezone", prefs.Timezone,
+		"first_day_of_week", prefs.FirstDayOfWeek,
+		"default_account_id", prefs.DefaultAccountID,
+	).Err()
+}
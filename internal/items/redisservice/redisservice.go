@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	pb "gateway-service/genproto/account"
+	"gateway-service/internal/items/metrics"
 
 	"github.com/go-redis/redis/v8"
 	"google.golang.org/protobuf/encoding/protojson"
@@ -14,28 +16,37 @@ import (
 
 type (
 	RedisService struct {
-		redisDb *redis.Client
-		logger  *slog.Logger
+		redisDb    *redis.Client
+		logger     *slog.Logger
+		accountTTL atomic.Int64
 	}
 )
 
-func New(redisDb *redis.Client, logger *slog.Logger) *RedisService {
-	return &RedisService{
+func New(redisDb *redis.Client, logger *slog.Logger, accountTTL time.Duration) *RedisService {
+	r := &RedisService{
 		logger:  logger,
 		redisDb: redisDb,
 	}
+	r.SetAccountTTL(accountTTL)
+	return r
+}
+
+// SetAccountTTL changes how long accounts stay cached. It is safe to call
+// while requests are being served.
+func (r *RedisService) SetAccountTTL(ttl time.Duration) {
+	r.accountTTL.Store(int64(ttl))
 }
 
 func (r *RedisService) StoreAccountInRedis(ctx context.Context, account *pb.AccountResponse) (*pb.AccountResponse, error) {
 	key := fmt.Sprintf("account:%s", account.Id)
 	athleteJSON, err := protojson.Marshal(account)
 	if err != nil {
-		r.logger.Error("Error marshalling account:", slog.String("err: ", err.Error()))
+		r.logger.ErrorContext(ctx, "Error marshalling account:", slog.String("err: ", err.Error()))
 		return nil, err
 	}
 
-	if err := r.redisDb.Set(ctx, key, athleteJSON, 10*time.Minute).Err(); err != nil {
-		r.logger.Error("Error setting account in Redis:", slog.String("err: ", err.Error()))
+	if err := r.redisDb.Set(ctx, key, athleteJSON, time.Duration(r.accountTTL.Load())).Err(); err != nil {
+		r.logger.ErrorContext(ctx, "Error setting account in Redis:", slog.String("err: ", err.Error()))
 		return nil, err
 	}
 
@@ -46,17 +57,28 @@ func (r *RedisService) GetAccountFromRedis(ctx context.Context, id string) (*pb.
 	key := fmt.Sprintf("account:%s", id)
 	val, err := r.redisDb.Get(ctx, key).Result()
 	if err == redis.Nil {
+		metrics.CacheMiss("account")
 		return nil, nil
 	} else if err != nil {
-		r.logger.Error("Error getting account from Redis:", slog.String("err: ", err.Error()))
+		metrics.CacheError("account")
+		r.logger.ErrorContext(ctx, "Error getting account from Redis:", slog.String("err: ", err.Error()))
 		return nil, err
 	}
+	metrics.CacheHit("account")
 
 	var athlete pb.AccountResponse
 	if err := protojson.Unmarshal([]byte(val), &athlete); err != nil {
-		r.logger.Error("Error unmarshalling account:", slog.String("err: ", err.Error()))
+		r.logger.ErrorContext(ctx, "Error unmarshalling account:", slog.String("err: ", err.Error()))
 		return nil, err
 	}
 
 	return &athlete, nil
 }
+
+func (r *RedisService) DeleteAccountFromRedis(ctx context.Context, id string) error {
+	return r.redisDb.Del(ctx, fmt.Sprintf("account:%s", id)).Err()
+}
+
+func (r *RedisService) Ping(ctx context.Context) error {
+	return r.redisDb.Ping(ctx).Err()
+}
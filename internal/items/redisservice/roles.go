@@ -0,0 +1,118 @@
+package redisservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gateway-service/internal/items/rbac"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// rolesKey is a hash of user IDs to their role. Each role also has a set
+// of its members, and rolesVersionKey counts changes to either.
+const (
+	rolesKey        = "roles"
+	rolesVersionKey = "roles:version"
+)
+
+func roleMembersKey(role string) string {
+	return fmt.Sprintf("role:%s", role)
+}
+
+// setRole assigns role ARGV[2] to ARGV[1] and returns {0, the role
+// assigned before}. With ARGV[3] set it only assigns a role to users
+// without one; otherwise it returns {2} for them. It returns {1} instead of
+// demoting the last superadmin.
+var setRole = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], ARGV[1])
+if current == ARGV[2] or (current and ARGV[3] == "1") then
+	return {0, current}
+end
+if not current and ARGV[3] ~= "1" then
+	return {2}
+end
+if current == "superadmin" and redis.call("SCARD", "role:superadmin") <= 1 then
+	return {1}
+end
+if current then
+	redis.call("SREM", "role:" .. current, ARGV[1])
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+redis.call("SADD", "role:" .. ARGV[2], ARGV[1])
+redis.call("INCR", KEYS[2])
+return {0, current or ""}
+`)
+
+// removeRole drops the role of ARGV[1], if any.
+var removeRole = redis.NewScript(`
+local current = redis.call("HGET", KEYS[1], ARGV[1])
+if not current then
+	return 0
+end
+redis.call("HDEL", KEYS[1], ARGV[1])
+redis.call("SREM", "role:" .. current, ARGV[1])
+return redis.call("INCR", KEYS[2])
+`)
+
+func (r *RedisService) Roles(ctx context.Context) (map[string]string, int64, error) {
+	var roles *redis.StringStringMapCmd
+	var version *redis.StringCmd
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		roles = pipe.HGetAll(ctx, rolesKey)
+		version = pipe.Get(ctx, rolesVersionKey)
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+	n, err := version.Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, 0, err
+	}
+	return roles.Val(), n, nil
+}
+
+func (r *RedisService) RolesVersion(ctx context.Context) (int64, error) {
+	n, err := r.redisDb.Get(ctx, rolesVersionKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
+
+// Role returns "" if userId has no role assigned.
+func (r *RedisService) Role(ctx context.Context, userId string) (string, error) {
+	role, err := r.redisDb.HGet(ctx, rolesKey, userId).Result()
+	if errors.Is(err, redis.Nil) {
+		return "", nil
+	}
+	return role, err
+}
+
+func (r *RedisService) RoleMembers(ctx context.Context, role string) ([]string, error) {
+	return r.redisDb.SMembers(ctx, roleMembersKey(role)).Result()
+}
+
+// SetRole fails with rbac.ErrLastSuperadmin instead of demoting the last
+// superadmin, and unless onlyIfUnset, with rbac.ErrNoRole for users
+// without a role.
+func (r *RedisService) SetRole(ctx context.Context, userId, role string, onlyIfUnset bool) (string, error) {
+	flag := "0"
+	if onlyIfUnset {
+		flag = "1"
+	}
+	result, err := setRole.Run(ctx, r.redisDb, []string{rolesKey, rolesVersionKey}, userId, role, flag).Slice()
+	if err != nil {
+		return "", err
+	}
+	switch result[0] {
+	case int64(1):
+		return "", rbac.ErrLastSuperadmin
+	case int64(2):
+		return "", rbac.ErrNoRole
+	}
+	previous, _ := result[1].(string)
+	return previous, nil
+}
@@ -0,0 +1,182 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Session is one login. TokenID identifies its access token, by the jti
+// claim or else a hash of the token; RefreshHash is the SHA-256 of the
+// refresh token issued with it.
+type Session struct {
+	ID          string
+	UserID      string
+	TokenID     string
+	RefreshHash string
+	UserAgent   string
+	IP          string
+	CreatedAt   time.Time
+	LastSeenAt  time.Time
+}
+
+func sessionKey(id string) string {
+	return fmt.Sprintf("session:%s", id)
+}
+
+func sessionTokenKey(tokenId string) string {
+	return fmt.Sprintf("session:token:%s", tokenId)
+}
+
+func revokedSessionKey(tokenId string) string {
+	return fmt.Sprintf("session:revoked:%s", tokenId)
+}
+
+func userSessionsKey(userId string) string {
+	return fmt.Sprintf("sessions:user:%s", userId)
+}
+
+// checkSession returns 0 if the token's session was revoked and 1
+// otherwise, and records ARGV[1] as the session's last-seen time. Tokens
+// without a session, issued before sessions were tracked, pass.
+var checkSession = redis.NewScript(`
+if redis.call("EXISTS", KEYS[2]) == 1 then
+	return 0
+end
+local id = redis.call("GET", KEYS[1])
+if id then
+	local key = "session:" .. id
+	if redis.call("EXISTS", key) == 1 then
+		redis.call("HSET", key, "last_seen_at", ARGV[1])
+	end
+end
+return 1
+`)
+
+// StartSession records a login. It lives as long as its access token.
+func (r *RedisService) StartSession(ctx context.Context, session Session, ttl time.Duration) error {
+	_, err := r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, sessionKey(session.ID),
+			"user_id", session.UserID,
+			"token_id", session.TokenID,
+			"refresh_hash", session.RefreshHash,
+			"user_agent", session.UserAgent,
+			"ip", session.IP,
+			"created_at", session.CreatedAt.Unix(),
+			"last_seen_at", session.CreatedAt.Unix(),
+		)
+		pipe.Expire(ctx, sessionKey(session.ID), ttl)
+		pipe.Set(ctx, sessionTokenKey(session.TokenID), session.ID, ttl)
+		pipe.SAdd(ctx, userSessionsKey(session.UserID), session.ID)
+		return nil
+	})
+	return err
+}
+
+// TouchSession reports whether the session of tokenId is still valid and
+// updates its last-seen time.
+func (r *RedisService) TouchSession(ctx context.Context, tokenId string, t time.Time) (bool, error) {
+	valid, err := checkSession.Run(ctx, r.redisDb, []string{sessionTokenKey(tokenId), revokedSessionKey(tokenId)}, t.Unix()).Int()
+	return valid == 1, err
+}
+
+// SessionOfToken returns the ID of the session tokenId belongs to, or "".
+func (r *RedisService) SessionOfToken(ctx context.Context, tokenId string) (string, error) {
+	id, err := r.redisDb.Get(ctx, sessionTokenKey(tokenId)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return id, err
+}
+
+// GetSession returns ErrTokenNotFound once the session has expired or
+// been revoked.
+func (r *RedisService) GetSession(ctx context.Context, id string) (Session, error) {
+	values, err := r.redisDb.HGetAll(ctx, sessionKey(id)).Result()
+	if err != nil {
+		return Session{}, err
+	}
+	if values["user_id"] == "" {
+		return Session{}, ErrTokenNotFound
+	}
+
+	session := Session{
+		ID:          id,
+		UserID:      values["user_id"],
+		TokenID:     values["token_id"],
+		RefreshHash: values["refresh_hash"],
+		UserAgent:   values["user_agent"],
+		IP:          values["ip"],
+	}
+	for field, t := range map[string]*time.Time{"created_at": &session.CreatedAt, "last_seen_at": &session.LastSeenAt} {
+		unix, err := strconv.ParseInt(values[field], 10, 64)
+		if err != nil {
+			return Session{}, err
+		}
+		*t = time.Unix(unix, 0)
+	}
+	return session, nil
+}
+
+// ListSessions returns userId's live sessions and forgets expired ones.
+func (r *RedisService) ListSessions(ctx context.Context, userId string) ([]Session, error) {
+	ids, err := r.redisDb.SMembers(ctx, userSessionsKey(userId)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := []Session{}
+	for _, id := range ids {
+		session, err := r.GetSession(ctx, id)
+		if err == ErrTokenNotFound {
+			r.redisDb.SRem(ctx, userSessionsKey(userId), id)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// RevokeSession ends userId's session id and rejects its access token for
+// tokenTTL, the longest it could still be valid. It reports whether the
+// session existed; sessions of other users are left alone.
+func (r *RedisService) RevokeSession(ctx context.Context, userId, id string, tokenTTL time.Duration) (bool, error) {
+	session, err := r.GetSession(ctx, id)
+	if err == ErrTokenNotFound || (err == nil && session.UserID != userId) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	_, err = r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, revokedSessionKey(session.TokenID), "1", tokenTTL)
+		pipe.Del(ctx, sessionKey(id), sessionTokenKey(session.TokenID))
+		pipe.SRem(ctx, userSessionsKey(userId), id)
+		return nil
+	})
+	return err == nil, err
+}
+
+// ForgetSessions drops every session record of userId. It is used after
+// all their tokens were revoked by RevokeTokens.
+func (r *RedisService) ForgetSessions(ctx context.Context, userId string) error {
+	sessions, err := r.ListSessions(ctx, userId)
+	if err != nil {
+		return err
+	}
+	_, err = r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, session := range sessions {
+			pipe.Del(ctx, sessionKey(session.ID), sessionTokenKey(session.TokenID))
+		}
+		pipe.Del(ctx, userSessionsKey(userId))
+		return nil
+	})
+	return err
+}
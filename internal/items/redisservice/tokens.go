@@ -0,0 +1,79 @@
+package redisservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrTokenNotFound means a one-time token was never issued, has expired,
+// was replaced by a newer one or was already used.
+var ErrTokenNotFound = errors.New("token not found")
+
+func tokenKey(purpose, id string) string {
+	return fmt.Sprintf("token:%s:%s", purpose, id)
+}
+
+func userTokenKey(purpose, userId string) string {
+	return fmt.Sprintf("token:%s:user:%s", purpose, userId)
+}
+
+// StoreToken records a one-time token for userId. Only the newest token
+// per user and purpose is kept, so issuing one invalidates the previous.
+func (r *RedisService) StoreToken(ctx context.Context, purpose, id, userId string, ttl time.Duration) error {
+	previous, err := r.redisDb.GetSet(ctx, userTokenKey(purpose, userId), id).Result()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+
+	_, err = r.redisDb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if previous != "" {
+			pipe.Del(ctx, tokenKey(purpose, previous))
+		}
+		pipe.Set(ctx, tokenKey(purpose, id), userId, ttl)
+		pipe.Expire(ctx, userTokenKey(purpose, userId), ttl)
+		return nil
+	})
+	return err
+}
+
+// ConsumeToken returns the user a one-time token was issued to and deletes
+// it. Of concurrent calls with the same token only one succeeds.
+func (r *RedisService) ConsumeToken(ctx context.Context, purpose, id string) (string, error) {
+	userId, err := r.redisDb.Get(ctx, tokenKey(purpose, id)).Result()
+	if err == redis.Nil {
+		return "", ErrTokenNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	deleted, err := r.redisDb.Del(ctx, tokenKey(purpose, id)).Result()
+	if err != nil {
+		return "", err
+	}
+	if deleted == 0 {
+		return "", ErrTokenNotFound
+	}
+	r.redisDb.Del(ctx, userTokenKey(purpose, userId))
+	return userId, nil
+}
+
+// LookupToken returns the user a token was issued to without using it up,
+// for tokens that stay valid until they expire.
+func (r *RedisService) LookupToken(ctx context.Context, purpose, id string) (string, error) {
+	userId, err := r.redisDb.Get(ctx, tokenKey(purpose, id)).Result()
+	if err == redis.Nil {
+		return "", ErrTokenNotFound
+	}
+	return userId, err
+}
+
+// AcquireCooldown reports whether key is free and, if so, holds it for
+// ttl. It limits how often an action may be repeated.
+func (r *RedisService) AcquireCooldown(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return r.redisDb.SetNX(ctx, "cooldown:"+key, "1", ttl).Result()
+}
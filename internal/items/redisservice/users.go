@@ -0,0 +1,114 @@
+package redisservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// UserStatus is the gateway-side state of a user that tokens are checked
+// against on every authenticated request.
+type UserStatus struct {
+	Inactive bool
+	// Unverified is set from registration until the email is confirmed.
+	Unverified bool
+	// RevokedBefore rejects tokens issued before it, and those issued in
+	// the same second that have no session; zero means none.
+	RevokedBefore time.Time
+}
+
+func inactiveKey(userId string) string {
+	return fmt.Sprintf("user:inactive:%s", userId)
+}
+
+func revokedKey(userId string) string {
+	return fmt.Sprintf("user:revoked_before:%s", userId)
+}
+
+func unverifiedKey(userId string) string {
+	return fmt.Sprintf("user:unverified:%s", userId)
+}
+
+// SetUserActive records whether userId may use the API. Deactivation has
+// no expiry.
+func (r *RedisService) SetUserActive(ctx context.Context, userId string, active bool) error {
+	if active {
+		return r.redisDb.Del(ctx, inactiveKey(userId)).Err()
+	}
+	return r.redisDb.Set(ctx, inactiveKey(userId), "1", 0).Err()
+}
+
+// SetUserVerified records whether userId has confirmed their email. Users
+// registered before verification existed have no marker and count as
+// verified.
+func (r *RedisService) SetUserVerified(ctx context.Context, userId string, verified bool) error {
+	if verified {
+		return r.redisDb.Del(ctx, unverifiedKey(userId)).Err()
+	}
+	return r.redisDb.Set(ctx, unverifiedKey(userId), "1", 0).Err()
+}
+
+// RevokeTokens rejects every token issued to userId up to now. It is
+// stored in whole seconds, like iat, so logins in the same second are told
+// apart by their session; callers drop the user's sessions afterwards. The
+// marker only needs to outlive the longest-lived token, tokenTTL.
+func (r *RedisService) RevokeTokens(ctx context.Context, userId string, tokenTTL time.Duration) error {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	return r.redisDb.Set(ctx, revokedKey(userId), now, tokenTTL).Err()
+}
+
+func (r *RedisService) GetUserStatus(ctx context.Context, userId string) (UserStatus, error) {
+	values, err := r.redisDb.MGet(ctx, inactiveKey(userId), revokedKey(userId), unverifiedKey(userId)).Result()
+	if err != nil && err != redis.Nil {
+		return UserStatus{}, err
+	}
+
+	var status UserStatus
+	status.Inactive = values[0] != nil
+	status.Unverified = values[2] != nil
+	if revoked, ok := values[1].(string); ok {
+		unix, err := strconv.ParseInt(revoked, 10, 64)
+		if err != nil {
+			return UserStatus{}, err
+		}
+		status.RevokedBefore = time.Unix(unix, 0)
+	}
+	return status, nil
+}
+
+// staffActivityKey is a hash of admin user ID to the Unix time of their
+// last request.
+const staffActivityKey = "staff:last_active"
+
+func (r *RedisService) TouchStaffActivity(ctx context.Context, userId string) error {
+	return r.redisDb.HSet(ctx, staffActivityKey, userId, time.Now().Unix()).Err()
+}
+
+// StaffLastActive returns the last request time of each of userIds that
+// has one.
+func (r *RedisService) StaffLastActive(ctx context.Context, userIds []string) (map[string]time.Time, error) {
+	lastActive := map[string]time.Time{}
+	if len(userIds) == 0 {
+		return lastActive, nil
+	}
+
+	values, err := r.redisDb.HMGet(ctx, staffActivityKey, userIds...).Result()
+	if err != nil {
+		return nil, err
+	}
+	for i, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			continue
+		}
+		unix, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		lastActive[userIds[i]] = time.Unix(unix, 0).UTC()
+	}
+	return lastActive, nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type AccountDeletionResponse struct {
+	// ScheduledFor is when the account is deleted unless the user logs in
+	// before then.
+	ScheduledFor time.Time `json:"scheduled_for"`
+}
+
+// UserDeletedEvent is published to user_deleted once a user and their
+// budgeting data have been deleted, for services holding more of their
+// data, such as notifications, to remove it.
+type UserDeletedEvent struct {
+	UserID      string    `json:"user_id"`
+	RequestedAt time.Time `json:"requested_at"`
+	DeletedAt   time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+type ExportJob struct {
+	ID string `json:"id"`
+	// Status is pending, done or failed.
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Size of the archive in bytes, once done.
+	Size int64 `json:"size,omitempty"`
+	// DownloadURL is a signed link to the archive, set once done. It works
+	// without the Authorization header until DownloadExpiresAt.
+	DownloadURL       string     `json:"download_url,omitempty"`
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type ImpersonateRequest struct {
+	// Reason is recorded in the audit log, e.g. a support ticket number.
+	Reason string `json:"reason"`
+	// DurationMinutes defaults to impersonation.default_ttl.
+	DurationMinutes int `json:"duration_minutes"`
+	// AllowWrite lets the token change data; only superadmins may set it.
+	AllowWrite bool `json:"allow_write"`
+}
+
+type ImpersonationResponse struct {
+	ID          string    `json:"id"`
+	AccessToken string    `json:"access_token"`
+	UserID      string    `json:"user_id"`
+	ReadOnly    bool      `json:"read_only"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type MFACodeRequest struct {
+	// Code is a 6 digit TOTP code or, where accepted, a recovery code.
+	Code string `json:"code"`
+}
+
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// MFAChallengeResponse replaces the tokens in a login response when the
+// user has two-factor authentication enabled.
+type MFAChallengeResponse struct {
+	MFARequired    bool      `json:"mfa_required"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+type MFAEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFAStatusResponse struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+// ElevateRequest re-authenticates with either Code, a TOTP code, or Email
+// and Password.
+type ElevateRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type ElevateResponse struct {
+	ElevationToken string    `json:"elevation_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
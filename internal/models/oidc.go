@@ -0,0 +1,5 @@
+package models
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type ChangePasswordRequest struct {
+	Email           string `json:"email"`
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PasswordResetRequestedEvent is published to password_reset_requested
+// for a mailer to send ResetURL to Email.
+type PasswordResetRequestedEvent struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	ResetURL  string    `json:"reset_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
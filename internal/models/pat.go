@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+type CreatePersonalTokenRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt defaults to personal_tokens.default_ttl from now.
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type PersonalToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// CreatePersonalTokenResponse is the only time the token itself is shown.
+type CreatePersonalTokenResponse struct {
+	PersonalToken
+	Token string `json:"token"`
+}
+
+type PersonalTokenScopesResponse struct {
+	Scopes []string `json:"scopes"`
+}
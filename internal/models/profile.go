@@ -0,0 +1,14 @@
+package models
+
+type Profile struct {
+	// Currency is an ISO 4217 code, e.g. USD.
+	Currency string `json:"currency"`
+	// Locale is one of en-US, en-GB, de-DE, fr-FR, ru-RU and uz-UZ.
+	Locale string `json:"locale"`
+	// Timezone is an IANA name, e.g. Asia/Tashkent.
+	Timezone string `json:"timezone"`
+	// FirstDayOfWeek is a lower-case weekday name, e.g. monday.
+	FirstDayOfWeek string `json:"first_day_of_week"`
+	// DefaultAccountID is used for transactions created without an account.
+	DefaultAccountID string `json:"default_account_id"`
+}
@@ -3,11 +3,17 @@ package models
 type GetSpendingReportRequest struct {
     StartDate string `json:"start_date"`
     EndDate   string `json:"end_date"`
+    // Period is week, month or year and fills missing dates with the
+    // current one. It defaults to month.
+    Period string `json:"period"`
 }
 
 type GetIncomeReportRequest struct {
     StartDate string `json:"start_date"`
     EndDate   string `json:"end_date"`
+    // Period is week, month or year and fills missing dates with the
+    // current one. It defaults to month.
+    Period string `json:"period"`
 }
 
 type GetBudgetPerformanceReportRequest struct {
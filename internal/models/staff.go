@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+type StaffMember struct {
+	UserID       string     `json:"user_id"`
+	Role         string     `json:"role"`
+	LastActiveAt *time.Time `json:"last_active_at"`
+}
+
+// UpdateUserRequest is an admin's change to a user's details. The role,
+// active state and password each have an endpoint of their own.
+type UpdateUserRequest struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email"`
+}
+
+// VerificationRequestedEvent is published to user_verification_requested
+// for a mailer to send VerifyURL to Email.
+type VerificationRequestedEvent struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email"`
+	VerifyURL string    `json:"verify_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,351 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gateway-service/internal/items/config"
+)
+
+// testCA issues certificates like scripts/gen-certs.sh does, into a
+// temporary directory.
+type testCA struct {
+	t      *testing.T
+	dir    string
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	serial int64
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	ca := &testCA{t: t, dir: t.TempDir()}
+	ca.cert, ca.key = ca.create(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "gateway-local-ca"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil, nil)
+	ca.write("ca.crt", ca.cert.Raw, "CERTIFICATE")
+	return ca
+}
+
+func (ca *testCA) create(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	ca.t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	ca.serial++
+	template.SerialNumber = big.NewInt(ca.serial)
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	return cert, key
+}
+
+// issue writes name.crt and name.key for a leaf certificate and returns
+// their paths.
+func (ca *testCA) issue(name string, usage x509.ExtKeyUsage) (string, string) {
+	ca.t.Helper()
+	cert, key := ca.create(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		DNSNames:    []string{name, "localhost"},
+		IPAddresses: []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{usage},
+	}, ca.cert, ca.key)
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		ca.t.Fatal(err)
+	}
+	return ca.write(name+".crt", cert.Raw, "CERTIFICATE"), ca.write(name+".key", der, "EC PRIVATE KEY")
+}
+
+func (ca *testCA) write(name string, der []byte, blockType string) string {
+	ca.t.Helper()
+	path := filepath.Join(ca.dir, name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		ca.t.Fatal(err)
+	}
+	return path
+}
+
+func (ca *testCA) path(name string) string {
+	return filepath.Join(ca.dir, name)
+}
+
+// serve accepts one connection on a TLS listener with serverConfig, and
+// writes "ok" to it once the handshake succeeds.
+func serve(t *testing.T, serverConfig *tls.Config) string {
+	t.Helper()
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if conn.(*tls.Conn).Handshake() == nil {
+			conn.Write([]byte("ok"))
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// roundTrip connects with clientConfig and reads the server's reply. With
+// TLS 1.3 a rejected client certificate only shows up on the first read.
+func roundTrip(addr string, clientConfig *tls.Config) (*tls.Conn, error) {
+	conn, err := tls.Dial("tcp", addr, clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func TestServerTLS(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		serverConfig, err := ServerTLS(config.ServerTLS{Enabled: false, CertFile: "missing.crt"})
+		if err != nil || serverConfig != nil {
+			t.Fatalf("ServerTLS = %v, %v; want nil, nil", serverConfig, err)
+		}
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		_, err := ServerTLS(config.ServerTLS{Enabled: true, CertFile: "missing.crt", KeyFile: "missing.key"})
+		if err == nil {
+			t.Fatal("ServerTLS succeeded without a certificate")
+		}
+	})
+
+	t.Run("serves the certificate", func(t *testing.T) {
+		ca := newTestCA(t)
+		certFile, keyFile := ca.issue("gateway", x509.ExtKeyUsageServerAuth)
+		serverConfig, err := ServerTLS(config.ServerTLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if serverConfig.MinVersion != tls.VersionTLS12 {
+			t.Errorf("MinVersion = %x, want TLS 1.2", serverConfig.MinVersion)
+		}
+
+		clientConfig, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: ca.path("ca.crt"), ServerName: "gateway"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := roundTrip(serve(t, serverConfig), clientConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		if cn := conn.ConnectionState().PeerCertificates[0].Subject.CommonName; cn != "gateway" {
+			t.Errorf("server certificate CN = %q, want gateway", cn)
+		}
+	})
+}
+
+func TestReloader(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue("gateway", x509.ExtKeyUsageServerAuth)
+
+	reloader, err := NewReloader(certFile, keyFile, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	serial := func() int64 {
+		cert, err := x509.ParseCertificate(reloader.Certificate().Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert.SerialNumber.Int64()
+	}
+	// touch moves the files' modification time forward, since a rewrite
+	// within the file system's timestamp resolution may not change it.
+	modTime := time.Now()
+	touch := func() {
+		modTime = modTime.Add(time.Second)
+		for _, path := range []string{certFile, keyFile} {
+			if err := os.Chtimes(path, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	first := serial()
+	if got := serial(); got != first {
+		t.Fatalf("serial changed to %d without a file change", got)
+	}
+
+	ca.issue("gateway", x509.ExtKeyUsageServerAuth)
+	touch()
+	second := serial()
+	if second == first {
+		t.Fatal("certificate was not reloaded after the files changed")
+	}
+
+	if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	touch()
+	if got := serial(); got != second {
+		t.Fatalf("serial = %d after a failed reload, want the previous %d", got, second)
+	}
+
+	t.Run("checks at most once per interval", func(t *testing.T) {
+		ca := newTestCA(t)
+		certFile, keyFile := ca.issue("gateway", x509.ExtKeyUsageServerAuth)
+		reloader, err := NewReloader(certFile, keyFile, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := reloader.Certificate()
+
+		ca.issue("gateway", x509.ExtKeyUsageServerAuth)
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(certFile, later, later)
+		if reloader.Certificate() != before {
+			t.Fatal("certificate reloaded before the interval passed")
+		}
+	})
+}
+
+func TestClientTLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue("auth", x509.ExtKeyUsageServerAuth)
+	serverConfig, err := ServerTLS(config.ServerTLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("missing CA file", func(t *testing.T) {
+		if _, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: ca.path("missing.crt")}); err == nil {
+			t.Fatal("ClientTLS succeeded with a missing CA file")
+		}
+	})
+
+	t.Run("CA file without certificates", func(t *testing.T) {
+		path := filepath.Join(ca.dir, "empty.crt")
+		os.WriteFile(path, []byte("nothing here"), 0o600)
+		if _, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: path}); err == nil {
+			t.Fatal("ClientTLS succeeded with an empty CA file")
+		}
+	})
+
+	t.Run("verifies the server against the CA", func(t *testing.T) {
+		clientConfig, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: ca.path("ca.crt"), ServerName: "auth"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := roundTrip(serve(t, serverConfig), clientConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	})
+
+	t.Run("rejects a server name the certificate does not cover", func(t *testing.T) {
+		clientConfig, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: ca.path("ca.crt"), ServerName: "budgeting"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := roundTrip(serve(t, serverConfig), clientConfig); err == nil {
+			t.Fatal("handshake succeeded for the wrong server name")
+		}
+	})
+
+	t.Run("rejects a server from another CA", func(t *testing.T) {
+		other := newTestCA(t)
+		clientConfig, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: other.path("ca.crt"), ServerName: "auth"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := roundTrip(serve(t, serverConfig), clientConfig); err == nil {
+			t.Fatal("handshake succeeded with an untrusted server certificate")
+		}
+	})
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := newTestCA(t)
+	certFile, keyFile := ca.issue("auth", x509.ExtKeyUsageServerAuth)
+	clientCertFile, clientKeyFile := ca.issue("gateway-client", x509.ExtKeyUsageClientAuth)
+
+	// The upstream side of mTLS, as the auth and budgeting services
+	// configure it.
+	serverConfig, err := ServerTLS(config.ServerTLS{Enabled: true, CertFile: certFile, KeyFile: keyFile, ReloadInterval: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	serverConfig.ClientCAs = pool
+	serverConfig.ClientAuth = tls.RequireAndVerifyClientCert
+
+	t.Run("accepted with a client certificate", func(t *testing.T) {
+		clientConfig, err := ClientTLS(config.ClientTLS{
+			Enabled:    true,
+			CAFile:     ca.path("ca.crt"),
+			CertFile:   clientCertFile,
+			KeyFile:    clientKeyFile,
+			ServerName: "auth",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn, err := roundTrip(serve(t, serverConfig), clientConfig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.Close()
+	})
+
+	t.Run("rejected without a client certificate", func(t *testing.T) {
+		clientConfig, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: ca.path("ca.crt"), ServerName: "auth"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := roundTrip(serve(t, serverConfig), clientConfig); err == nil {
+			t.Fatal("handshake succeeded without a client certificate")
+		}
+	})
+
+	t.Run("client certificate file missing", func(t *testing.T) {
+		_, err := ClientTLS(config.ClientTLS{Enabled: true, CAFile: ca.path("ca.crt"), CertFile: ca.path("missing.crt"), KeyFile: clientKeyFile})
+		if err == nil {
+			t.Fatal("ClientTLS succeeded with a missing client certificate")
+		}
+	})
+}
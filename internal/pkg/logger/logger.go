@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"gateway-service/internal/items/config"
+	"gateway-service/internal/pkg/reqctx"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ComponentKey is the attribute that selects a per-component level, set
+// with logger.With(ComponentKey, "msgbroker").
+const ComponentKey = "component"
+
+// New builds the process logger from config. The returned Levels can be
+// updated at runtime and the closer releases the log file, if any.
+func New(cfg config.LoggingConfig) (*slog.Logger, *Levels, io.Closer, error) {
+	levels := &Levels{}
+	if err := levels.Set(cfg.Level, cfg.Levels); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		writers []io.Writer
+		closer  io.Closer = nopCloser{}
+	)
+
+	switch cfg.Output {
+	case "stdout":
+		writers = append(writers, os.Stdout)
+	case "file", "both":
+		file, err := OpenRotatingFile(cfg.File, cfg.MaxSizeMB, cfg.RotateInterval, cfg.MaxBackups, cfg.MaxAge)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		writers = append(writers, file)
+		closer = file
+		if cfg.Output == "both" {
+			writers = append(writers, os.Stdout)
+		}
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown log output %q", cfg.Output)
+	}
+
+	inner := slog.NewJSONHandler(io.MultiWriter(writers...), &slog.HandlerOptions{
+		Level:       slog.LevelDebug,
+		ReplaceAttr: Redact,
+	})
+
+	return slog.New(&handler{inner: inner, levels: levels}), levels, closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Levels holds the default level and per-component overrides.
+type Levels struct {
+	mu         sync.RWMutex
+	base       slog.Level
+	components map[string]slog.Level
+}
+
+// Set replaces all levels at once. Nothing changes if any level is invalid.
+func (l *Levels) Set(base string, components map[string]string) error {
+	baseLevel, err := parseLevel(base)
+	if err != nil {
+		return err
+	}
+
+	parsed := make(map[string]slog.Level, len(components))
+	for name, level := range components {
+		lvl, err := parseLevel(level)
+		if err != nil {
+			return fmt.Errorf("component %s: %w", name, err)
+		}
+		parsed[name] = lvl
+	}
+
+	l.mu.Lock()
+	l.base = baseLevel
+	l.components = parsed
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *Levels) For(component string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if lvl, ok := l.components[component]; ok {
+		return lvl
+	}
+	return l.base
+}
+
+func parseLevel(s string) (slog.Level, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, errors.New("invalid log level " + s)
+	}
+	return lvl, nil
+}
+
+// handler filters records by component level and adds the request and
+// trace IDs found in the context.
+type handler struct {
+	inner     slog.Handler
+	levels    *Levels
+	component string
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.For(h.component)
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if id := reqctx.RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	component := h.component
+	for _, a := range attrs {
+		if a.Key == ComponentKey {
+			component = a.Value.String()
+		}
+	}
+	return &handler{inner: h.inner.WithAttrs(attrs), levels: h.levels, component: component}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{inner: h.inner.WithGroup(name), levels: h.levels, component: h.component}
+}
@@ -0,0 +1,110 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	emailPattern = regexp.MustCompile(`([A-Za-z0-9._%+\-])[A-Za-z0-9._%+\-]*@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+	sensitiveKeyParts = []string{"password", "token", "secret", "authorization", "otp", "totp", "recovery_code"}
+)
+
+// Redact is a slog ReplaceAttr hook. It hides the values of sensitive keys,
+// masks email addresses in strings and walks structs, maps and slices
+// (such as logged request bodies) doing the same.
+func Redact(_ []string, a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, maskEmails(a.Value.String()))
+	case slog.KindAny:
+		return slog.Any(a.Key, redactValue(a.Value.Any()))
+	}
+	return a
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func maskEmails(s string) string {
+	if !strings.Contains(s, "@") {
+		return s
+	}
+	return emailPattern.ReplaceAllString(s, "$1***@$2")
+}
+
+// RedactValue applies the same redaction as Redact to a value, for callers
+// that store or publish data outside the logger.
+func RedactValue(v any) any {
+	return redactValue(v)
+}
+
+func redactValue(v any) any {
+	if err, ok := v.(error); ok {
+		return maskEmails(err.Error())
+	}
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return v
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+	default:
+		return v
+	}
+
+	// Round-trip through JSON so every field is visited by its logged name.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return v
+	}
+	return redactJSON(generic)
+}
+
+func redactJSON(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if isSensitiveKey(k) {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redactJSON(item)
+		}
+		return val
+	case []any:
+		for i, item := range val {
+			val[i] = redactJSON(item)
+		}
+		return val
+	case string:
+		return maskEmails(val)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupLayout is the timestamp suffix of rotated files.
+const backupLayout = "20060102T150405.000"
+
+// RotatingFile is an append-only log file that is rotated when it grows
+// past maxSize or when interval has passed since it was started. Rotated
+// files are renamed with a timestamp suffix and pruned by count and age.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	maxAge     time.Duration
+
+	file      *os.File
+	size      int64
+	startedAt time.Time
+}
+
+// OpenRotatingFile opens path for appending. A zero maxSizeMB, interval,
+// maxBackups or maxAge disables that limit.
+func OpenRotatingFile(path string, maxSizeMB int, interval time.Duration, maxBackups int, maxAge time.Duration) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		interval:   interval,
+		maxBackups: maxBackups,
+		maxAge:     maxAge,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.shouldRotate(len(p)) {
+		// A failed rotation leaves the current file open, so keep logging
+		// to it and try again on the next write.
+		if err := f.rotate(); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: rotating %s: %v\n", f.path, err)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
+
+func (f *RotatingFile) shouldRotate(n int) bool {
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(n) > f.maxSize {
+		return true
+	}
+	return f.interval > 0 && time.Since(f.startedAt) >= f.interval
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.startedAt = time.Now()
+	if f.size > 0 {
+		f.startedAt = f.existingStart(info.ModTime())
+	}
+	return nil
+}
+
+// existingStart returns when the existing file at path was started, so
+// time-based rotation carries on across restarts. That is when the newest
+// backup was rotated out or, without one, the file's mtime.
+func (f *RotatingFile) existingStart(modTime time.Time) time.Time {
+	backups, _ := filepath.Glob(f.path + ".*")
+	var newest time.Time
+	for _, backup := range backups {
+		t, err := time.ParseInLocation(backupLayout, strings.TrimPrefix(backup, f.path+"."), time.Local)
+		if err == nil && t.After(newest) && !t.After(modTime) {
+			newest = t
+		}
+	}
+	if newest.IsZero() {
+		return modTime
+	}
+	return newest
+}
+
+// rotate renames the file to a timestamped backup and opens a new one at
+// path. The old handle is only closed once the new file is open, and the
+// backup is moved back if it cannot be, so logging never stops.
+func (f *RotatingFile) rotate() error {
+	backup := fmt.Sprintf("%s.%s", f.path, time.Now().Format(backupLayout))
+	if err := os.Rename(f.path, backup); err != nil {
+		return err
+	}
+
+	old := f.file
+	if err := f.open(); err != nil {
+		return errors.Join(err, os.Rename(backup, f.path))
+	}
+	old.Close()
+
+	f.prune()
+	return nil
+}
+
+// prune removes backups beyond maxBackups and backups older than maxAge.
+// Errors are ignored so logging never stops because of a stale backup.
+func (f *RotatingFile) prune() {
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+	// Timestamp suffixes sort chronologically; newest first.
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	for i, backup := range backups {
+		if !strings.HasPrefix(backup, f.path+".") {
+			continue
+		}
+		if f.maxBackups > 0 && i >= f.maxBackups {
+			os.Remove(backup)
+			continue
+		}
+		if f.maxAge > 0 {
+			if info, err := os.Stat(backup); err == nil && time.Since(info.ModTime()) > f.maxAge {
+				os.Remove(backup)
+			}
+		}
+	}
+}
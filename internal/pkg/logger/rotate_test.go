@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRotatingFileIntervalSurvivesRestart(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name    string
+		modTime time.Time
+		// backup is the age of an earlier backup, or zero for none.
+		backup     time.Duration
+		wantRotate bool
+	}{
+		{"recent file", now, 0, false},
+		{"file last written an interval ago", now.Add(-2 * time.Hour), 0, true},
+		{"file rotated in an interval ago", now, 2 * time.Hour, true},
+		{"file rotated in recently", now, 10 * time.Minute, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "gateway.log")
+			if err := os.WriteFile(path, []byte("earlier line\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(path, tt.modTime, tt.modTime); err != nil {
+				t.Fatal(err)
+			}
+			if tt.backup != 0 {
+				backup := path + "." + now.Add(-tt.backup).Format(backupLayout)
+				if err := os.WriteFile(backup, []byte("older line\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			f, err := OpenRotatingFile(path, 0, time.Hour, 0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+			if _, err := f.Write([]byte("new line\n")); err != nil {
+				t.Fatal(err)
+			}
+
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rotated := string(content) == "new line\n"; rotated != tt.wantRotate {
+				t.Fatalf("rotated = %v, want %v; file holds %q", rotated, tt.wantRotate, content)
+			}
+		})
+	}
+}
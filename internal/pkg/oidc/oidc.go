@@ -0,0 +1,289 @@
+// Package oidc is an OpenID Connect relying party for the authorization
+// code flow with PKCE. It discovers the provider's endpoints, caches its
+// signing keys and verifies ID tokens. Providers that only speak OAuth2,
+// such as GitHub, are supported by reading the identity from a user info
+// endpoint instead of an ID token.
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrExchange means the provider rejected the authorization code.
+	ErrExchange = errors.New("authorization code exchange failed")
+	// ErrInvalidIDToken means the ID token failed verification.
+	ErrInvalidIDToken = errors.New("invalid ID token")
+)
+
+// Options describes one provider. Issuer enables discovery; otherwise
+// AuthURL, TokenURL and UserInfoURL must be given.
+type Options struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// EmailsURL lists the user's addresses when the user info has no
+	// verified email, as GitHub's /user/emails does.
+	EmailsURL string
+
+	Client *http.Client
+}
+
+// Identity is the user as asserted by the provider.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type Provider struct {
+	opts Options
+	keys *keySet
+
+	mu   sync.Mutex
+	meta *metadata
+}
+
+func New(opts Options) *Provider {
+	if opts.Client == nil {
+		opts.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	p := &Provider{opts: opts}
+	if opts.Issuer == "" {
+		p.meta = &metadata{
+			AuthorizationEndpoint: opts.AuthURL,
+			TokenEndpoint:         opts.TokenURL,
+			UserInfoEndpoint:      opts.UserInfoURL,
+		}
+	}
+	return p
+}
+
+func (p *Provider) Name() string {
+	return p.opts.Name
+}
+
+// discover fetches the provider metadata on first use. A failure is not
+// cached, so a provider that was down is retried on the next login.
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.meta != nil {
+		return p.meta, nil
+	}
+
+	var meta metadata
+	wellKnown := strings.TrimSuffix(p.opts.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := getJSON(ctx, p.opts.Client, wellKnown, "", &meta); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if meta.Issuer != p.opts.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer %q does not match %q", meta.Issuer, p.opts.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" || meta.JWKSURI == "" {
+		return nil, errors.New("oidc discovery: incomplete provider metadata")
+	}
+	p.meta = &meta
+	p.keys = newKeySet(meta.JWKSURI, p.opts.Client)
+	return p.meta, nil
+}
+
+// AuthCodeURL returns the provider URL the browser is sent to. nonce is
+// ignored by providers without ID tokens.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.opts.ClientID},
+		"redirect_uri":          {p.opts.RedirectURL},
+		"scope":                 {strings.Join(p.opts.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {challenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if p.opts.Issuer != "" {
+		query.Set("nonce", nonce)
+	}
+	sep := "?"
+	if strings.Contains(meta.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return meta.AuthorizationEndpoint + sep + query.Encode(), nil
+}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems code with its PKCE verifier and returns the identity
+// it belongs to. For OpenID providers that is the verified ID token, whose
+// nonce must match.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.opts.RedirectURL},
+		"client_id":     {p.opts.ClientID},
+		"client_secret": {p.opts.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokens tokenResponse
+	if err := do(p.opts.Client, req, &tokens); err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrExchange, err)
+	}
+	if tokens.Error != "" {
+		return Identity{}, fmt.Errorf("%w: %s %s", ErrExchange, tokens.Error, tokens.ErrorDescription)
+	}
+
+	if p.opts.Issuer != "" {
+		if tokens.IDToken == "" {
+			return Identity{}, fmt.Errorf("%w: no id_token in response", ErrInvalidIDToken)
+		}
+		return p.verify(ctx, tokens.IDToken, nonce)
+	}
+	return p.userInfo(ctx, tokens.AccessToken)
+}
+
+// userInfo reads the identity of an OAuth2-only provider.
+func (p *Provider) userInfo(ctx context.Context, accessToken string) (Identity, error) {
+	if accessToken == "" {
+		return Identity{}, fmt.Errorf("%w: no access_token in response", ErrExchange)
+	}
+
+	var info struct {
+		ID    json.Number `json:"id"`
+		Sub   string      `json:"sub"`
+		Email string      `json:"email"`
+		Name  string      `json:"name"`
+		Login string      `json:"login"`
+	}
+	if err := getJSON(ctx, p.opts.Client, p.meta.UserInfoEndpoint, accessToken, &info); err != nil {
+		return Identity{}, fmt.Errorf("user info: %w", err)
+	}
+	identity := Identity{Subject: info.Sub, Name: info.Name}
+	if identity.Subject == "" {
+		identity.Subject = info.ID.String()
+	}
+	if identity.Name == "" {
+		identity.Name = info.Login
+	}
+	if identity.Subject == "" {
+		return Identity{}, errors.New("user info: no user ID")
+	}
+
+	// The profile email of an OAuth2 provider is not known to be verified,
+	// so only a verified address from the emails list is trusted.
+	if p.opts.EmailsURL != "" {
+		var emails []struct {
+			Email    string `json:"email"`
+			Primary  bool   `json:"primary"`
+			Verified bool   `json:"verified"`
+		}
+		if err := getJSON(ctx, p.opts.Client, p.opts.EmailsURL, accessToken, &emails); err != nil {
+			return Identity{}, fmt.Errorf("user emails: %w", err)
+		}
+		for _, e := range emails {
+			if e.Primary && e.Verified {
+				identity.Email, identity.EmailVerified = e.Email, true
+			}
+		}
+	}
+	return identity, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, endpoint, accessToken string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return do(client, req, out)
+}
+
+func do(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	// Token endpoints report errors as JSON with status 400, which the
+	// caller inspects, so only decode failures and other statuses fail.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("%s %s: status %d", req.Method, req.URL.Redacted(), resp.StatusCode)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("%s %s: %w", req.Method, req.URL.Redacted(), err)
+	}
+	return nil
+}
+
+// RandomString returns n random bytes, base64url encoded, for state,
+// nonce and PKCE verifier values.
+func RandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// challenge is the S256 PKCE code challenge of verifier.
+func challenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
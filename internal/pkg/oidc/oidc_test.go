@@ -0,0 +1,331 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const testClientID = "gateway"
+
+// testProvider is an OpenID provider on an httptest server. Its token
+// endpoint answers with idToken, and its JWKS publishes keys.
+type testProvider struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu        sync.Mutex
+	keys      map[string]*rsa.PrivateKey
+	jwksHits  int
+	idToken   string
+	tokenForm url.Values
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	t.Helper()
+	tp := &testProvider{t: t, keys: map[string]*rsa.PrivateKey{}}
+	tp.addKey("key-1")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(metadata{
+			Issuer:                tp.server.URL,
+			AuthorizationEndpoint: tp.server.URL + "/authorize",
+			TokenEndpoint:         tp.server.URL + "/token",
+			JWKSURI:               tp.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+		tp.jwksHits++
+		set := struct {
+			Keys []jsonWebKey `json:"keys"`
+		}{}
+		for kid, key := range tp.keys {
+			set.Keys = append(set.Keys, jsonWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		}
+		json.NewEncoder(w).Encode(set)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		tp.mu.Lock()
+		defer tp.mu.Unlock()
+		tp.tokenForm = r.PostForm
+		json.NewEncoder(w).Encode(tokenResponse{AccessToken: "access", IDToken: tp.idToken})
+	})
+	tp.server = httptest.NewServer(mux)
+	t.Cleanup(tp.server.Close)
+	return tp
+}
+
+func (tp *testProvider) addKey(kid string) {
+	tp.t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tp.t.Fatal(err)
+	}
+	tp.mu.Lock()
+	tp.keys[kid] = key
+	tp.mu.Unlock()
+}
+
+func (tp *testProvider) hits() int {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	return tp.jwksHits
+}
+
+// claims returns valid ID token claims for nonce, to be modified by tests.
+func (tp *testProvider) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            tp.server.URL,
+		"aud":            testClientID,
+		"sub":            "subject-1",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "Test User",
+		"nonce":          nonce,
+		"iat":            time.Now().Unix(),
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+// sign signs claims with the provider key kid.
+func (tp *testProvider) sign(kid string, claims jwt.MapClaims) string {
+	tp.t.Helper()
+	tp.mu.Lock()
+	key := tp.keys[kid]
+	tp.mu.Unlock()
+	if key == nil {
+		var err error
+		if key, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+			tp.t.Fatal(err)
+		}
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	raw, err := token.SignedString(key)
+	if err != nil {
+		tp.t.Fatal(err)
+	}
+	return raw
+}
+
+func (tp *testProvider) provider() *Provider {
+	return New(Options{
+		Name:         "generic",
+		Issuer:       tp.server.URL,
+		ClientID:     testClientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://gateway/auth/oidc/generic/callback",
+		Scopes:       []string{"openid", "email"},
+	})
+}
+
+// discovered returns a provider that has fetched the metadata.
+func (tp *testProvider) discovered() *Provider {
+	tp.t.Helper()
+	p := tp.provider()
+	if _, err := p.discover(context.Background()); err != nil {
+		tp.t.Fatal(err)
+	}
+	return p
+}
+
+func TestVerify(t *testing.T) {
+	tp := newTestProvider(t)
+	const nonce = "nonce-1"
+
+	tests := []struct {
+		name   string
+		token  func() string
+		nonce  string
+		wantOK bool
+	}{
+		{"valid", func() string { return tp.sign("key-1", tp.claims(nonce)) }, nonce, true},
+		{"wrong issuer", func() string {
+			claims := tp.claims(nonce)
+			claims["iss"] = "https://evil.example.com"
+			return tp.sign("key-1", claims)
+		}, nonce, false},
+		{"wrong audience", func() string {
+			claims := tp.claims(nonce)
+			claims["aud"] = "someone-else"
+			return tp.sign("key-1", claims)
+		}, nonce, false},
+		{"several audiences without azp", func() string {
+			claims := tp.claims(nonce)
+			claims["aud"] = []string{testClientID, "someone-else"}
+			return tp.sign("key-1", claims)
+		}, nonce, false},
+		{"several audiences with another azp", func() string {
+			claims := tp.claims(nonce)
+			claims["aud"] = []string{testClientID, "someone-else"}
+			claims["azp"] = "someone-else"
+			return tp.sign("key-1", claims)
+		}, nonce, false},
+		{"several audiences with our azp", func() string {
+			claims := tp.claims(nonce)
+			claims["aud"] = []string{testClientID, "someone-else"}
+			claims["azp"] = testClientID
+			return tp.sign("key-1", claims)
+		}, nonce, true},
+		{"wrong nonce", func() string { return tp.sign("key-1", tp.claims("other")) }, nonce, false},
+		{"no nonce expected", func() string { return tp.sign("key-1", tp.claims("")) }, "", false},
+		{"expired", func() string {
+			claims := tp.claims(nonce)
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+			return tp.sign("key-1", claims)
+		}, nonce, false},
+		{"HMAC signed with the client secret", func() string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, tp.claims(nonce))
+			token.Header["kid"] = "key-1"
+			raw, _ := token.SignedString([]byte("secret"))
+			return raw
+		}, nonce, false},
+		{"unsigned", func() string {
+			raw, _ := jwt.NewWithClaims(jwt.SigningMethodNone, tp.claims(nonce)).SignedString(jwt.UnsafeAllowNoneSignatureType)
+			return raw
+		}, nonce, false},
+		{"signed by another key with a known kid", func() string {
+			claims := tp.claims(nonce)
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+			token.Header["kid"] = "key-1"
+			other, _ := rsa.GenerateKey(rand.Reader, 2048)
+			raw, _ := token.SignedString(other)
+			return raw
+		}, nonce, false},
+		{"no subject", func() string {
+			claims := tp.claims(nonce)
+			delete(claims, "sub")
+			return tp.sign("key-1", claims)
+		}, nonce, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			identity, err := tp.discovered().verify(context.Background(), tt.token(), tt.nonce)
+			if tt.wantOK {
+				if err != nil {
+					t.Fatalf("verify: %v", err)
+				}
+				want := Identity{Subject: "subject-1", Email: "user@example.com", EmailVerified: true, Name: "Test User"}
+				if identity != want {
+					t.Fatalf("identity = %+v, want %+v", identity, want)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidIDToken) {
+				t.Fatalf("verify error = %v, want ErrInvalidIDToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyEmailVerifiedAsString(t *testing.T) {
+	tp := newTestProvider(t)
+	claims := tp.claims("n")
+	claims["email_verified"] = "true"
+	identity, err := tp.discovered().verify(context.Background(), tp.sign("key-1", claims), "n")
+	if err != nil || !identity.EmailVerified {
+		t.Fatalf("identity = %+v, %v; want a verified email", identity, err)
+	}
+}
+
+func TestVerifyUnknownKeyRefreshesKeySet(t *testing.T) {
+	tp := newTestProvider(t)
+	p := tp.discovered()
+	ctx := context.Background()
+
+	if _, err := p.verify(ctx, tp.sign("key-1", tp.claims("n")), "n"); err != nil {
+		t.Fatal(err)
+	}
+	if hits := tp.hits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", hits)
+	}
+
+	// The provider rolls its keys. Within minRefresh of the last fetch
+	// an unknown key ID is rejected without fetching again.
+	tp.addKey("key-2")
+	rolled := tp.sign("key-2", tp.claims("n"))
+	if _, err := p.verify(ctx, rolled, "n"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("verify error = %v, want ErrInvalidIDToken", err)
+	}
+	if hits := tp.hits(); hits != 1 {
+		t.Fatalf("JWKS fetched %d times within minRefresh, want 1", hits)
+	}
+
+	p.keys.mu.Lock()
+	p.keys.fetched = time.Now().Add(-minRefresh)
+	p.keys.mu.Unlock()
+	if _, err := p.verify(ctx, rolled, "n"); err != nil {
+		t.Fatalf("verify after the key set was refreshed: %v", err)
+	}
+	if hits := tp.hits(); hits != 2 {
+		t.Fatalf("JWKS fetched %d times, want 2", hits)
+	}
+
+	// A key ID the provider does not publish still fails after a refresh.
+	p.keys.mu.Lock()
+	p.keys.fetched = time.Now().Add(-minRefresh)
+	p.keys.mu.Unlock()
+	if _, err := p.verify(ctx, tp.sign("key-3", tp.claims("n")), "n"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("verify error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestExchange(t *testing.T) {
+	tp := newTestProvider(t)
+	p := tp.provider()
+	ctx := context.Background()
+
+	authURL, err := p.AuthCodeURL(ctx, "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := u.Query()
+	if query.Get("code_challenge") != challenge("verifier-1") || query.Get("code_challenge_method") != "S256" {
+		t.Errorf("auth URL has no S256 challenge for the verifier: %s", authURL)
+	}
+	if query.Get("nonce") != "nonce-1" || query.Get("state") != "state-1" {
+		t.Errorf("auth URL does not carry state and nonce: %s", authURL)
+	}
+
+	tp.idToken = tp.sign("key-1", tp.claims("nonce-1"))
+	identity, err := p.Exchange(ctx, "code-1", "verifier-1", "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.Subject != "subject-1" {
+		t.Errorf("subject = %q, want subject-1", identity.Subject)
+	}
+	if tp.tokenForm.Get("code_verifier") != "verifier-1" || tp.tokenForm.Get("code") != "code-1" {
+		t.Errorf("token request form = %v", tp.tokenForm)
+	}
+
+	if _, err := p.Exchange(ctx, "code-1", "verifier-1", "another-nonce"); !errors.Is(err, ErrInvalidIDToken) {
+		t.Fatalf("Exchange with another nonce: %v, want ErrInvalidIDToken", err)
+	}
+}
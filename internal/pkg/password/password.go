@@ -0,0 +1,81 @@
+// Package password checks new passwords against the configured strength
+// policy before they are sent to the auth service.
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gateway-service/internal/items/config"
+)
+
+// common lists passwords that meet a typical policy but are among the
+// first ones guessed.
+var common = map[string]bool{
+	"password1!":   true,
+	"password123":  true,
+	"password123!": true,
+	"passw0rd!":    true,
+	"p@ssw0rd":     true,
+	"p@ssword1":    true,
+	"qwerty123!":   true,
+	"qwertyuiop1":  true,
+	"1q2w3e4r5t":   true,
+	"1qaz2wsx3edc": true,
+	"welcome123!":  true,
+	"letmein123!":  true,
+	"iloveyou123":  true,
+	"admin12345":   true,
+	"changeme123":  true,
+}
+
+// Check returns an error describing why pw is too weak, or nil. related
+// holds values the password must not contain, such as the email address.
+func Check(pw string, policy config.PasswordConfig, related ...string) error {
+	length := utf8.RuneCountInString(pw)
+	if length < policy.MinLength {
+		return fmt.Errorf("password must be at least %d characters", policy.MinLength)
+	}
+	if length > policy.MaxLength {
+		return fmt.Errorf("password must be at most %d characters", policy.MaxLength)
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, present := range []bool{lower, upper, digit, symbol} {
+		if present {
+			classes++
+		}
+	}
+	if classes < policy.MinCharClasses {
+		return fmt.Errorf("password must contain at least %d of: lower case letters, upper case letters, digits, symbols", policy.MinCharClasses)
+	}
+
+	folded := strings.ToLower(pw)
+	if common[folded] {
+		return errors.New("password is too common")
+	}
+	for _, value := range related {
+		// Only the local part of an email address is compared.
+		value, _, _ = strings.Cut(strings.ToLower(value), "@")
+		if len(value) >= 3 && strings.Contains(folded, value) {
+			return errors.New("password must not contain your email address")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package profile holds a user's display and regional preferences and
+// applies them: the timezone their dates are in, the default date ranges
+// of reports and how numbers and dates are formatted for them.
+package profile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	// Loads timezones without the system database, which the runtime
+	// image does not ship.
+	_ "time/tzdata"
+)
+
+const dateLayout = "2006-01-02"
+
+// Periods a report range can default to.
+const (
+	PeriodWeek  = "week"
+	PeriodMonth = "month"
+	PeriodYear  = "year"
+)
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type format struct {
+	decimal string
+	group   string
+	date    string
+}
+
+// locales are the supported locales and how they write numbers and dates.
+var locales = map[string]format{
+	"en-US": {decimal: ".", group: ",", date: "01/02/2006"},
+	"en-GB": {decimal: ".", group: ",", date: "02/01/2006"},
+	"de-DE": {decimal: ",", group: ".", date: "02.01.2006"},
+	"fr-FR": {decimal: ",", group: " ", date: "02/01/2006"},
+	"ru-RU": {decimal: ",", group: " ", date: "02.01.2006"},
+	"uz-UZ": {decimal: ",", group: " ", date: "02.01.2006"},
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// Preferences are a user's settings. Empty fields take the gateway's
+// defaults, see WithDefaults.
+type Preferences struct {
+	// Currency is an ISO 4217 code, e.g. USD.
+	Currency string `json:"currency"`
+	// Locale is a BCP 47 tag from Locales, e.g. en-US.
+	Locale string `json:"locale"`
+	// Timezone is an IANA name, e.g. Asia/Tashkent.
+	Timezone string `json:"timezone"`
+	// FirstDayOfWeek is a lower-case weekday name, e.g. monday.
+	FirstDayOfWeek string `json:"first_day_of_week"`
+	// DefaultAccountID is used for transactions created without an account.
+	DefaultAccountID string `json:"default_account_id"`
+}
+
+// Store persists preferences per user. Get returns nil for a user who
+// never saved any.
+type Store interface {
+	GetPreferences(ctx context.Context, userId string) (*Preferences, error)
+	SavePreferences(ctx context.Context, userId string, prefs Preferences) error
+}
+
+// Load returns userId's preferences with defaults filled in.
+func Load(ctx context.Context, store Store, userId string, defaults Preferences) (Preferences, error) {
+	prefs, err := store.GetPreferences(ctx, userId)
+	if err != nil || prefs == nil {
+		return defaults, err
+	}
+	return prefs.WithDefaults(defaults), nil
+}
+
+// Locales returns the supported locales.
+func Locales() []string {
+	result := make([]string, 0, len(locales))
+	for locale := range locales {
+		result = append(result, locale)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// WithDefaults fills the empty fields of p from defaults.
+func (p Preferences) WithDefaults(defaults Preferences) Preferences {
+	for field, value := range map[*string]string{
+		&p.Currency:         defaults.Currency,
+		&p.Locale:           defaults.Locale,
+		&p.Timezone:         defaults.Timezone,
+		&p.FirstDayOfWeek:   defaults.FirstDayOfWeek,
+		&p.DefaultAccountID: defaults.DefaultAccountID,
+	} {
+		if *field == "" {
+			*field = value
+		}
+	}
+	return p
+}
+
+// Validate reports every invalid field at once. Empty fields are valid.
+func (p Preferences) Validate() error {
+	var errs []error
+	if p.Currency != "" && !currencyCode.MatchString(p.Currency) {
+		errs = append(errs, fmt.Errorf("currency: %q is not an ISO 4217 code", p.Currency))
+	}
+	if _, ok := locales[p.Locale]; p.Locale != "" && !ok {
+		errs = append(errs, fmt.Errorf("locale: %q is not one of %s", p.Locale, strings.Join(Locales(), ", ")))
+	}
+	if _, err := time.LoadLocation(p.Timezone); p.Timezone != "" && (err != nil || p.Timezone == "Local") {
+		errs = append(errs, fmt.Errorf("timezone: %q is not an IANA timezone", p.Timezone))
+	}
+	if _, ok := weekdays[p.FirstDayOfWeek]; p.FirstDayOfWeek != "" && !ok {
+		errs = append(errs, fmt.Errorf("first_day_of_week: %q is not a weekday", p.FirstDayOfWeek))
+	}
+	return errors.Join(errs...)
+}
+
+// Location returns the user's timezone, or UTC.
+func (p Preferences) Location() *time.Location {
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil || p.Timezone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
+// Date returns t as a YYYY-MM-DD date in the user's timezone, the format
+// the budgeting service stores.
+func (p Preferences) Date(t time.Time) string {
+	return t.In(p.Location()).Format(dateLayout)
+}
+
+// Range returns the YYYY-MM-DD dates from the start of the current week,
+// month or year in the user's timezone up to and including now.
+func (p Preferences) Range(period string, now time.Time) (start, end string, err error) {
+	now = now.In(p.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var from time.Time
+	switch period {
+	case PeriodWeek:
+		first, ok := weekdays[p.FirstDayOfWeek]
+		if !ok {
+			first = time.Monday
+		}
+		from = today.AddDate(0, 0, -int((today.Weekday()-first+7)%7))
+	case PeriodMonth:
+		from = today.AddDate(0, 0, 1-today.Day())
+	case PeriodYear:
+		from = today.AddDate(0, 0, 1-today.YearDay())
+	default:
+		return "", "", fmt.Errorf("period: %q is not one of %s, %s, %s", period, PeriodWeek, PeriodMonth, PeriodYear)
+	}
+	return from.Format(dateLayout), today.Format(dateLayout), nil
+}
+
+func (p Preferences) format() format {
+	if f, ok := locales[p.Locale]; ok {
+		return f
+	}
+	return locales["en-US"]
+}
+
+// FormatDate writes t as a date the way the user's locale does, in their
+// timezone.
+func (p Preferences) FormatDate(t time.Time) string {
+	return t.In(p.Location()).Format(p.format().date)
+}
+
+// FormatAmount writes amount with two decimals and the user's locale's
+// separators, e.g. 1,234.50 or 1.234,50.
+func (p Preferences) FormatAmount(amount float64) string {
+	f := p.format()
+	s := fmt.Sprintf("%.2f", amount)
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.group)
+		}
+		b.WriteRune(digit)
+	}
+	b.WriteString(f.decimal)
+	b.WriteString(frac)
+	return b.String()
+}
@@ -0,0 +1,151 @@
+package profile
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var defaults = Preferences{Currency: "USD", Locale: "en-US", Timezone: "UTC", FirstDayOfWeek: "monday"}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		prefs Preferences
+		want  []string
+	}{
+		{"empty", Preferences{}, nil},
+		{"valid", Preferences{Currency: "UZS", Locale: "uz-UZ", Timezone: "Asia/Tashkent", FirstDayOfWeek: "sunday"}, nil},
+		{"lower-case currency", Preferences{Currency: "usd"}, []string{"currency"}},
+		{"unsupported locale", Preferences{Locale: "xx-XX"}, []string{"locale"}},
+		{"unknown timezone", Preferences{Timezone: "Mars/Olympus"}, []string{"timezone"}},
+		{"local timezone", Preferences{Timezone: "Local"}, []string{"timezone"}},
+		{"capitalised weekday", Preferences{FirstDayOfWeek: "Monday"}, []string{"first_day_of_week"}},
+		{"every field at once", Preferences{Currency: "dollars", Locale: "en", Timezone: "nowhere", FirstDayOfWeek: "someday"},
+			[]string{"currency", "locale", "timezone", "first_day_of_week"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.prefs.Validate()
+			if (err != nil) != (len(tt.want) > 0) {
+				t.Fatalf("err = %v, want errors for %v", err, tt.want)
+			}
+			for _, field := range tt.want {
+				if !strings.Contains(err.Error(), field+":") {
+					t.Errorf("err = %v, want it to mention %s", err, field)
+				}
+			}
+		})
+	}
+}
+
+func TestRange(t *testing.T) {
+	// Wednesday 2025-01-01 20:30 UTC is already Thursday in Tashkent.
+	now := time.Date(2025, 1, 1, 20, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		prefs      Preferences
+		period     string
+		start, end string
+	}{
+		{"week from monday", Preferences{FirstDayOfWeek: "monday"}, PeriodWeek, "2024-12-30", "2025-01-01"},
+		{"week from sunday", Preferences{FirstDayOfWeek: "sunday"}, PeriodWeek, "2024-12-29", "2025-01-01"},
+		{"week from today", Preferences{FirstDayOfWeek: "wednesday"}, PeriodWeek, "2025-01-01", "2025-01-01"},
+		{"week without a first day", Preferences{}, PeriodWeek, "2024-12-30", "2025-01-01"},
+		{"week in the user's timezone", Preferences{Timezone: "Asia/Tashkent", FirstDayOfWeek: "thursday"}, PeriodWeek, "2025-01-02", "2025-01-02"},
+		{"month", Preferences{}, PeriodMonth, "2025-01-01", "2025-01-01"},
+		{"month in a timezone behind UTC", Preferences{Timezone: "America/Los_Angeles"}, PeriodMonth, "2025-01-01", "2025-01-01"},
+		{"year", Preferences{Timezone: "Pacific/Honolulu"}, PeriodYear, "2025-01-01", "2025-01-01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := tt.prefs.Range(tt.period, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if start != tt.start || end != tt.end {
+				t.Fatalf("Range = %s..%s, want %s..%s", start, end, tt.start, tt.end)
+			}
+		})
+	}
+
+	if _, _, err := (Preferences{}).Range("decade", now); err == nil {
+		t.Fatal("Range accepted an unknown period")
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	at := time.Date(2025, 3, 9, 22, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		prefs  Preferences
+		amount float64
+		want   string
+		date   string
+		day    string
+	}{
+		{"default locale", Preferences{}, 1234.5, "1,234.50", "03/09/2025", "2025-03-09"},
+		{"en-GB", Preferences{Locale: "en-GB"}, 1234567.891, "1,234,567.89", "09/03/2025", "2025-03-09"},
+		{"de-DE", Preferences{Locale: "de-DE"}, -1234.5, "-1.234,50", "09.03.2025", "2025-03-09"},
+		{"fr-FR", Preferences{Locale: "fr-FR"}, 999, "999,00", "09/03/2025", "2025-03-09"},
+		{"timezone moves the date", Preferences{Locale: "uz-UZ", Timezone: "Asia/Tashkent"}, 0.5, "0,50", "10.03.2025", "2025-03-10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.prefs.FormatAmount(tt.amount); got != tt.want {
+				t.Errorf("FormatAmount = %q, want %q", got, tt.want)
+			}
+			if got := tt.prefs.FormatDate(at); got != tt.date {
+				t.Errorf("FormatDate = %q, want %q", got, tt.date)
+			}
+			if got := tt.prefs.Date(at); got != tt.day {
+				t.Errorf("Date = %q, want %q", got, tt.day)
+			}
+		})
+	}
+}
+
+type fakeStore struct {
+	prefs *Preferences
+	err   error
+}
+
+func (s fakeStore) GetPreferences(ctx context.Context, userId string) (*Preferences, error) {
+	return s.prefs, s.err
+}
+
+func (s fakeStore) SavePreferences(ctx context.Context, userId string, prefs Preferences) error {
+	return errors.New("not implemented")
+}
+
+func TestLoad(t *testing.T) {
+	stored := Preferences{Currency: "EUR", Timezone: "Europe/Berlin"}
+	lookupErr := errors.New("redis down")
+
+	tests := []struct {
+		name  string
+		store fakeStore
+		want  Preferences
+		err   error
+	}{
+		{"never saved", fakeStore{}, defaults, nil},
+		{"saved fields override defaults", fakeStore{prefs: &stored},
+			Preferences{Currency: "EUR", Locale: "en-US", Timezone: "Europe/Berlin", FirstDayOfWeek: "monday"}, nil},
+		{"lookup fails", fakeStore{err: lookupErr}, defaults, lookupErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(context.Background(), tt.store, "user-1", defaults)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Fatalf("Load = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package redisCl
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"testing"
+
+	"gateway-service/internal/items/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracingHook(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	host, port, err := net.SplitHostPort(miniredis.RunT(t).Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Default()
+	cfg.Redis.Host = host
+	cfg.Redis.Port, _ = strconv.Atoi(port)
+	client, err := NewRedisDB(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	tests := []struct {
+		name      string
+		run       func(ctx context.Context) error
+		span      string
+		operation string
+		failed    bool
+	}{
+		{"command", func(ctx context.Context) error { return client.Set(ctx, "k", "v", 0).Err() }, "redis set", "set", false},
+		{"missing key is not an error", func(ctx context.Context) error {
+			if err := client.Get(ctx, "missing").Err(); err != redis.Nil {
+				return err
+			}
+			return nil
+		}, "redis get", "get", false},
+		{"failed command", func(ctx context.Context) error {
+			client.Incr(ctx, "k")
+			return nil
+		}, "redis incr", "incr", true},
+		{"pipeline", func(ctx context.Context) error {
+			_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, "a", "1", 0)
+				pipe.Del(ctx, "a")
+				return nil
+			})
+			return err
+		}, "redis pipeline", "set del", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+			if err := tt.run(ctx); err != nil {
+				t.Fatal(err)
+			}
+			parent.End()
+
+			var span sdktrace.ReadOnlySpan
+			for _, s := range recorder.Ended() {
+				if s.Parent().SpanID() == parent.SpanContext().SpanID() && s.Name() == tt.span {
+					span = s
+				}
+			}
+			if span == nil {
+				t.Fatalf("no %q span under the request span", tt.span)
+			}
+			if span.SpanKind() != trace.SpanKindClient {
+				t.Fatalf("span kind = %s, want client", span.SpanKind())
+			}
+			wantOperation := attribute.String("db.operation", tt.operation)
+			found := false
+			for _, attr := range span.Attributes() {
+				found = found || attr == wantOperation
+			}
+			if !found {
+				t.Fatalf("attributes = %v, want %v", span.Attributes(), wantOperation)
+			}
+			if failed := span.Status().Code == codes.Error; failed != tt.failed {
+				t.Fatalf("span status = %v, want failed %v", span.Status(), tt.failed)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Package reqctx carries per-request correlation data through a
+// context.Context: the request ID and the time spent in upstream calls.
+package reqctx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	MetadataRequestID = "x-request-id"
+)
+
+type requestIDKey struct{}
+
+type timingsKey struct{}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID returns a random 128-bit hex identifier.
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID reports whether an incoming ID is safe to reuse: short and
+// limited to characters that cannot break log lines or headers.
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Timings accumulates the duration of upstream calls made for one request.
+type Timings struct {
+	mu    sync.Mutex
+	calls map[string]time.Duration
+}
+
+func WithTimings(ctx context.Context) (context.Context, *Timings) {
+	t := &Timings{calls: make(map[string]time.Duration)}
+	return context.WithValue(ctx, timingsKey{}, t), t
+}
+
+// RecordUpstream adds d to the total for name. It is a no-op when ctx has
+// no Timings attached.
+func RecordUpstream(ctx context.Context, name string, d time.Duration) {
+	t, ok := ctx.Value(timingsKey{}).(*Timings)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	t.calls[name] += d
+	t.mu.Unlock()
+}
+
+// Milliseconds returns a copy of the recorded totals in milliseconds.
+func (t *Timings) Milliseconds() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make(map[string]int64, len(t.calls))
+	for name, d := range t.calls {
+		out[name] = d.Milliseconds()
+	}
+	return out
+}
+
+// UnaryClientInterceptor forwards the request ID in gRPC metadata and
+// records the call duration against the request.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if id := RequestID(ctx); id != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, MetadataRequestID, id)
+		}
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		RecordUpstream(ctx, method, time.Since(start))
+		return err
+	}
+}
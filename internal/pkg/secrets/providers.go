@@ -0,0 +1,91 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Env reads KEY from the environment.
+type Env struct{}
+
+func (Env) Name() string { return "env" }
+
+func (Env) Get(_ context.Context, key string) (string, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+// Files reads Docker and Kubernetes style secret files. KEY_FILE, when set,
+// names the file directly; otherwise the lower-cased key is looked up in
+// Dir (e.g. /run/secrets/jwt_secret_key). Files are read on every Get so a
+// rotated or remounted file is seen on the next refresh.
+type Files struct {
+	Dir string
+}
+
+func (Files) Name() string { return "file" }
+
+func (f Files) Get(_ context.Context, key string) (string, error) {
+	path := os.Getenv(key + "_FILE")
+	if path == "" {
+		if f.Dir == "" {
+			return "", ErrNotFound
+		}
+		path = filepath.Join(f.Dir, strings.ToLower(key))
+	}
+	return readSecretFile(path)
+}
+
+// LocalStore is a stand-in for an external secret manager: a JSON object
+// of key to value kept in one file, re-read on every Get.
+type LocalStore struct {
+	Path string
+}
+
+func (LocalStore) Name() string { return "local" }
+
+func (s LocalStore) Get(_ context.Context, key string) (string, error) {
+	if s.Path == "" {
+		return "", ErrNotFound
+	}
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var values map[string]string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return "", err
+	}
+	value, ok := values[key]
+	if !ok || value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
@@ -0,0 +1,132 @@
+// Package secrets resolves credentials from pluggable providers and keeps
+// them current, so rotated secrets are picked up without a restart.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var ErrNotFound = errors.New("secret not found")
+
+// Provider looks up a secret by key. Keys are the environment-variable
+// style names used in config, e.g. JWT_SECRET_KEY. A provider returns
+// ErrNotFound when it does not hold the key.
+type Provider interface {
+	Name() string
+	Get(ctx context.Context, key string) (string, error)
+}
+
+// Manager resolves a fixed set of keys through a chain of providers, first
+// match wins, and caches the values. Refresh re-resolves them.
+type Manager struct {
+	providers []Provider
+
+	mu     sync.RWMutex
+	keys   []string
+	values map[string]string
+	source map[string]string
+}
+
+func NewManager(providers ...Provider) *Manager {
+	return &Manager{
+		providers: providers,
+		values:    make(map[string]string),
+		source:    make(map[string]string),
+	}
+}
+
+// Register adds keys that Refresh should resolve.
+func (m *Manager) Register(keys ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys = append(m.keys, keys...)
+}
+
+// Get returns the cached value of key and whether any provider had it.
+func (m *Manager) Get(key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Source returns the name of the provider key was resolved from.
+func (m *Manager) Source(key string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.source[key]
+}
+
+// Refresh resolves every registered key and returns the keys whose value
+// changed. A key that no provider holds keeps its previous value, so a
+// secret file being swapped out does not blank a credential in use.
+func (m *Manager) Refresh(ctx context.Context) ([]string, error) {
+	m.mu.RLock()
+	keys := append([]string(nil), m.keys...)
+	m.mu.RUnlock()
+
+	var (
+		rotated []string
+		errs    []error
+	)
+	for _, key := range keys {
+		value, source, err := m.resolve(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		m.mu.Lock()
+		old, existed := m.values[key]
+		m.values[key] = value
+		m.source[key] = source
+		m.mu.Unlock()
+
+		if existed && old != value {
+			rotated = append(rotated, key)
+		}
+	}
+	return rotated, errors.Join(errs...)
+}
+
+func (m *Manager) resolve(ctx context.Context, key string) (string, string, error) {
+	for _, p := range m.providers {
+		value, err := p.Get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", "", err
+		}
+		return value, p.Name(), nil
+	}
+	return "", "", ErrNotFound
+}
+
+// Watch calls Refresh every interval until ctx is done.
+func (m *Manager) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rotated, err := m.Refresh(ctx)
+			if err != nil {
+				logger.Error("Failed to refresh secrets", "error", err.Error())
+			}
+			for _, key := range rotated {
+				logger.Info("Secret rotated", "key", key, "provider", m.Source(key))
+			}
+		}
+	}
+}
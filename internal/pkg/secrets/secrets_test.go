@@ -0,0 +1,120 @@
+package secrets
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const testKey = "GATEWAY_TEST_SECRET"
+
+func writeSecret(t *testing.T, path, value string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(value), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestManagerResolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    string
+		file   string
+		keyEnv bool // point GATEWAY_TEST_SECRET_FILE at a file outside Dir
+		local  string
+		value  string
+		source string
+	}{
+		{"env wins", "from-env", "from-file\n", false, `{"GATEWAY_TEST_SECRET":"from-local"}`, "from-env", "env"},
+		{"file in dir", "", "from-file\n", false, `{"GATEWAY_TEST_SECRET":"from-local"}`, "from-file", "file"},
+		{"named file", "", "from-named-file", true, "", "from-named-file", "file"},
+		{"empty file falls through", "", "\n", false, `{"GATEWAY_TEST_SECRET":"from-local"}`, "from-local", "local"},
+		{"local provider", "", "", false, `{"GATEWAY_TEST_SECRET":"from-local"}`, "from-local", "local"},
+		{"not found", "", "", false, `{"OTHER":"value"}`, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv(testKey, tt.env)
+			t.Setenv(testKey+"_FILE", "")
+			if tt.file != "" {
+				path := filepath.Join(dir, "gateway_test_secret")
+				if tt.keyEnv {
+					path = filepath.Join(t.TempDir(), "secret")
+					t.Setenv(testKey+"_FILE", path)
+				}
+				writeSecret(t, path, tt.file)
+			}
+			local := filepath.Join(t.TempDir(), "secrets.json")
+			if tt.local != "" {
+				writeSecret(t, local, tt.local)
+			}
+
+			m := NewManager(Env{}, Files{Dir: dir}, LocalStore{Path: local})
+			m.Register(testKey)
+			if _, err := m.Refresh(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			value, ok := m.Get(testKey)
+			if ok != (tt.value != "") || value != tt.value {
+				t.Fatalf("Get = %q, %v, want %q", value, ok, tt.value)
+			}
+			if source := m.Source(testKey); source != tt.source {
+				t.Fatalf("Source = %q, want %q", source, tt.source)
+			}
+		})
+	}
+}
+
+func TestManagerRefreshRotation(t *testing.T) {
+	t.Setenv(testKey, "")
+	t.Setenv(testKey+"_FILE", "")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "gateway_test_secret")
+	m := NewManager(Env{}, Files{Dir: dir})
+	m.Register(testKey)
+
+	steps := []struct {
+		name    string
+		update  func()
+		value   string
+		rotated []string
+	}{
+		{"initial", func() { writeSecret(t, path, "v1") }, "v1", nil},
+		{"unchanged", func() {}, "v1", nil},
+		{"rotated", func() { writeSecret(t, path, "v2") }, "v2", []string{testKey}},
+		{"removed keeps the last value", func() { os.Remove(path) }, "v2", nil},
+	}
+	for _, step := range steps {
+		step.update()
+		rotated, err := m.Refresh(context.Background())
+		if err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if !slices.Equal(rotated, step.rotated) {
+			t.Errorf("%s: rotated = %v, want %v", step.name, rotated, step.rotated)
+		}
+		if value, _ := m.Get(testKey); value != step.value {
+			t.Errorf("%s: value = %q, want %q", step.name, value, step.value)
+		}
+	}
+}
+
+func TestManagerRefreshProviderError(t *testing.T) {
+	t.Setenv(testKey, "")
+	t.Setenv(testKey+"_FILE", "")
+	local := filepath.Join(t.TempDir(), "secrets.json")
+	writeSecret(t, local, "not json")
+
+	m := NewManager(Env{}, LocalStore{Path: local})
+	m.Register(testKey)
+	if _, err := m.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh succeeded with a malformed provider file")
+	}
+	if _, ok := m.Get(testKey); ok {
+		t.Fatal("key resolved despite the provider error")
+	}
+}
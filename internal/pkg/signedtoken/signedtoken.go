@@ -0,0 +1,75 @@
+// Package signedtoken issues opaque tokens for links sent by email, such
+// as email verification. A token carries a random ID and its expiry,
+// signed with HMAC-SHA256 for one purpose, so a token cannot be forged,
+// extended or reused for another purpose. Single use is enforced by the
+// caller, which stores the ID server side.
+package signedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalid = errors.New("invalid token")
+	ErrExpired = errors.New("token has expired")
+)
+
+// New returns a token for purpose that expires at expiresAt, and its ID.
+func New(secret, purpose string, expiresAt time.Time) (token, id string, err error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	id = hex.EncodeToString(b)
+
+	payload := id + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + sign(secret, purpose, payload), id, nil
+}
+
+// Parse checks token against each of secrets, so tokens survive a key
+// rotation, and returns its ID.
+func Parse(token, purpose string, secrets []string, now time.Time) (string, error) {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return "", ErrInvalid
+	}
+	payload, signature := token[:i], token[i+1:]
+
+	valid := false
+	for _, secret := range secrets {
+		if hmac.Equal([]byte(signature), []byte(sign(secret, purpose, payload))) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", ErrInvalid
+	}
+
+	id, expiry, ok := strings.Cut(payload, ".")
+	if !ok {
+		return "", ErrInvalid
+	}
+	unix, err := strconv.ParseInt(expiry, 10, 64)
+	if err != nil {
+		return "", ErrInvalid
+	}
+	if !now.Before(time.Unix(unix, 0)) {
+		return "", ErrExpired
+	}
+	return id, nil
+}
+
+func sign(secret, purpose, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gateway-service/internal/items/config"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	tests := []struct {
+		name     string
+		exporter string
+		written  bool
+		fails    bool
+	}{
+		{"disabled", "none", false, false},
+		{"file exporter", "file", true, false},
+		{"unknown exporter", "zipkin", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+			t.Cleanup(func() {
+				otel.SetTracerProvider(prevProvider)
+				otel.SetTextMapPropagator(prevPropagator)
+			})
+
+			cfg := config.Default()
+			cfg.Tracing.Exporter = tt.exporter
+			cfg.Tracing.FilePath = filepath.Join(t.TempDir(), "traces.json")
+			shutdown, err := Setup(context.Background(), cfg)
+			if (err != nil) != tt.fails {
+				t.Fatalf("Setup error = %v, want failure %v", err, tt.fails)
+			}
+			if tt.fails {
+				return
+			}
+
+			_, span := Tracer().Start(context.Background(), "GET /user/profile")
+			span.End()
+			if err := shutdown(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(cfg.Tracing.FilePath)
+			if err != nil && !os.IsNotExist(err) {
+				t.Fatal(err)
+			}
+			if written := strings.Contains(string(data), "GET /user/profile"); written != tt.written {
+				t.Fatalf("span written = %v, want %v", written, tt.written)
+			}
+			if tt.written && !strings.Contains(string(data), cfg.Tracing.ServiceName) {
+				t.Fatalf("exported span lacks service name %q", cfg.Tracing.ServiceName)
+			}
+		})
+	}
+}
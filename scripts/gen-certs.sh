@@ -0,0 +1,29 @@
+#!/bin/bash
+# Generates a local CA plus server and client certificates for trying TLS
+# and mutual TLS between the gateway, auth and budgeting services.
+# Usage: ./scripts/gen-certs.sh [out_dir]
+set -euo pipefail
+
+OUT=${1:-certs}
+DAYS=365
+mkdir -p "$OUT"
+
+openssl req -x509 -newkey rsa:2048 -nodes -days "$DAYS" \
+  -keyout "$OUT/ca.key" -out "$OUT/ca.crt" -subj "/CN=gateway-local-ca"
+
+# name, extended key usage, subjectAltName
+issue() {
+  openssl req -newkey rsa:2048 -nodes \
+    -keyout "$OUT/$1.key" -out "$OUT/$1.csr" -subj "/CN=$1"
+  openssl x509 -req -in "$OUT/$1.csr" -days "$DAYS" \
+    -CA "$OUT/ca.crt" -CAkey "$OUT/ca.key" -CAcreateserial -out "$OUT/$1.crt" \
+    -extfile <(printf "extendedKeyUsage=%s\nsubjectAltName=%s\n" "$2" "$3")
+  rm "$OUT/$1.csr"
+}
+
+issue gateway serverAuth "DNS:gateway,DNS:localhost,IP:127.0.0.1"
+issue auth serverAuth "DNS:auth,DNS:localhost,IP:127.0.0.1"
+issue budgeting serverAuth "DNS:budgeting,DNS:localhost,IP:127.0.0.1"
+issue gateway-client clientAuth "DNS:gateway"
+
+echo "Certificates written to $OUT"
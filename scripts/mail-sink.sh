@@ -0,0 +1,14 @@
+#!/bin/bash
+# Development stand-in for the mailer: prints the emails the gateway asks
+# to send, such as verification and password reset links, as they are published to Kafka.
+# Usage: ./scripts/mail-sink.sh [kafka_container] [topic...]
+set -euo pipefail
+
+CONTAINER=${1:-kafka}
+shift || true
+TOPICS=${*:-user_verification_requested password_reset_requested}
+
+docker exec "$CONTAINER" kafka-console-consumer \
+  --bootstrap-server localhost:9092 \
+  --include "$(echo "$TOPICS" | tr ' ' '|')" \
+  --property print.topic=true